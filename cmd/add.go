@@ -28,10 +28,10 @@ var addCmd = &cobra.Command{
 			return
 		}
 
-		dir, err := os.Getwd()
+		dir, err := getProjectDir()
 		if err != nil {
-			ui.PrintError("Failed to get current directory: %v", err)
-			os.Exit(1)
+			ui.PrintError("Failed to determine project directory: %v", err)
+			exit(1)
 		}
 
 		// Verify this is a Wordsmith project
@@ -40,7 +40,7 @@ var addCmd = &cobra.Command{
 			fmt.Println()
 			ui.PrintInfo("Run 'wordsmith init' to create a new project")
 			fmt.Println()
-			os.Exit(1)
+			exit(1)
 		}
 
 		switch args[0] {
@@ -116,6 +116,7 @@ jobs:
 *.log
 build/
 wordsmith
+.wordsmith/
 `
 		if err := os.WriteFile(gitignorePath, []byte(gitignoreContent), 0644); err != nil {
 			ui.PrintWarning("Failed to create .gitignore: %v", err)
@@ -3,11 +3,15 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"wordsmith/internal/builder"
 	"wordsmith/internal/config"
 	"wordsmith/internal/ui"
+	"wordsmith/internal/version"
 )
 
 var buildCmd = &cobra.Command{
@@ -20,30 +24,123 @@ var buildCmd = &cobra.Command{
 			ui.PrintHeader(Version)
 		}
 
-		dir, err := os.Getwd()
+		// buildExit removes the --git-ref worktree before exiting
+		buildExit := exit
+
+		dir, err := getProjectDir()
 		if err != nil {
-			ui.PrintError("Failed to get current directory: %v", err)
-			os.Exit(1)
+			ui.PrintError("Failed to determine project directory: %v", err)
+			buildExit(1)
+		}
+
+		// Build from a temporary worktree of the ref; the output still goes to dir/build
+		srcDir := dir
+		if gitRef, _ := cmd.Flags().GetString("git-ref"); gitRef != "" {
+			var cleanup func()
+			srcDir, cleanup, err = checkoutGitRef(dir, gitRef)
+			if err != nil {
+				ui.PrintError("%v", err)
+				buildExit(1)
+			}
+			defer cleanup()
+			buildExit = func(code int) {
+				cleanup()
+				exit(code)
+			}
+			if !quiet {
+				ui.PrintInfo("Building %s from a temporary worktree", ui.Highlight(gitRef))
+			}
 		}
 
 		// Check for theme.properties first, then plugin.properties, then library.properties
-		isTheme := config.ThemeExists(dir)
-		isPlugin := config.PluginExists(dir)
-		isLibrary := config.LibraryExists(dir)
+		isTheme := config.ThemeExists(srcDir)
+		isPlugin := config.PluginExists(srcDir)
+		isLibrary := config.LibraryExists(srcDir)
 
 		if !isTheme && !isPlugin && !isLibrary {
 			ui.PrintError("No plugin.properties, theme.properties, or library.properties found in current directory")
 			ui.PrintInfo("Run 'wordsmith init plugin', 'wordsmith init theme', or 'wordsmith init library' to create one")
-			os.Exit(1)
+			buildExit(1)
+		}
+
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			if err := listBuildFiles(srcDir, isTheme, isPlugin); err != nil {
+				ui.PrintError("Failed to list files: %v", err)
+				buildExit(1)
+			}
+			return
+		}
+
+		reportPath, _ := cmd.Flags().GetString("report")
+		fileHashes, _ := cmd.Flags().GetBool("filelist-hashes")
+		stageOnly, _ := cmd.Flags().GetBool("stage-only")
+		updateManifest, _ := cmd.Flags().GetString("update-manifest")
+		if updateManifest != "" && (!isTheme && !isPlugin || stageOnly) {
+			ui.PrintError("--update-manifest requires a plugin or theme zip (not a library or --stage-only)")
+			buildExit(1)
+		}
+		if updateManifest != "" && !strings.HasPrefix(updateManifest, "http://") && !strings.HasPrefix(updateManifest, "https://") {
+			ui.PrintError("--update-manifest must be an http(s) base URL, e.g. https://example.com/releases")
+			buildExit(1)
+		}
+		provenance, _ := cmd.Flags().GetBool("provenance")
+		outputPath, _ := cmd.Flags().GetString("output")
+		if outputPath != "" {
+			if stageOnly {
+				ui.PrintError("--output can't be combined with --stage-only")
+				buildExit(1)
+			}
+			if outputPath, err = filepath.Abs(outputPath); err != nil {
+				ui.PrintError("Invalid --output path: %v", err)
+				buildExit(1)
+			}
+		}
+		checksums, err := parseChecksums(cmd)
+		if err != nil {
+			ui.PrintError("%v", err)
+			buildExit(1)
+		}
+		if len(checksums) > 0 && stageOnly {
+			ui.PrintError("--emit-checksum-file can't be combined with --stage-only")
+			buildExit(1)
+		}
+		if fileHashes && reportPath == "" {
+			reportPath = filepath.Join(dir, "build", "report.json")
 		}
 
 		if isTheme {
 			// Build theme
-			b := builder.NewThemeBuilder(dir)
+			b := builder.NewThemeBuilder(srcDir)
+			setBuildDir(&b.BaseBuilder, dir)
 			b.Quiet = quiet
-			if err := b.Build(); err != nil {
+			b.StageOnly = stageOnly
+			b.OutputPath = outputPath
+			b.Provenance = provenance
+			b.Checksums = checksums
+			b.ToolVersion = Version
+			b.GenerateScreenshot, _ = cmd.Flags().GetBool("generate-screenshot")
+			err := b.Build()
+			if reportPath != "" {
+				name := ""
+				if b.Config != nil {
+					name = b.Config.Name
+				}
+				report := b.NewBuildReport("theme", name, err)
+				if fileHashes && err == nil {
+					addFileHashes(report, &b.BaseBuilder)
+				}
+				writeBuildReport(reportPath, report)
+			}
+			if err != nil {
 				ui.PrintError("Build failed: %v", err)
-				os.Exit(1)
+				buildExit(1)
+			}
+			if stageOnly {
+				printStaged(quiet, filepath.Join(b.WorkDir, "stage"))
+				return
+			}
+			if updateManifest != "" {
+				writeUpdateManifest(b, updateManifest, quiet)
 			}
 
 			if quiet {
@@ -60,11 +157,36 @@ var buildCmd = &cobra.Command{
 			}
 		} else if isPlugin {
 			// Build plugin
-			b := builder.New(dir)
+			b := builder.New(srcDir)
+			setBuildDir(&b.BaseBuilder, dir)
 			b.Quiet = quiet
-			if err := b.Build(); err != nil {
+			b.StageOnly = stageOnly
+			b.OutputPath = outputPath
+			b.Provenance = provenance
+			b.Checksums = checksums
+			b.ToolVersion = Version
+			err := b.Build()
+			if reportPath != "" {
+				name := ""
+				if b.Config != nil {
+					name = b.Config.Name
+				}
+				report := b.NewBuildReport("plugin", name, err)
+				if fileHashes && err == nil {
+					addFileHashes(report, &b.BaseBuilder)
+				}
+				writeBuildReport(reportPath, report)
+			}
+			if err != nil {
 				ui.PrintError("Build failed: %v", err)
-				os.Exit(1)
+				buildExit(1)
+			}
+			if stageOnly {
+				printStaged(quiet, filepath.Join(b.WorkDir, "stage"))
+				return
+			}
+			if updateManifest != "" {
+				writeUpdateManifest(b, updateManifest, quiet)
 			}
 
 			if quiet {
@@ -81,11 +203,33 @@ var buildCmd = &cobra.Command{
 			}
 		} else {
 			// Build library
-			b := builder.NewLibraryBuilder(dir)
+			b := builder.NewLibraryBuilder(srcDir)
+			setBuildDir(&b.BaseBuilder, dir)
 			b.Quiet = quiet
-			if err := b.Build(); err != nil {
+			b.StageOnly = stageOnly
+			b.OutputPath = outputPath
+			b.Provenance = provenance
+			b.Checksums = checksums
+			b.ToolVersion = Version
+			err := b.Build()
+			if reportPath != "" {
+				name := ""
+				if b.Config != nil {
+					name = b.Config.Name
+				}
+				report := b.NewBuildReport("library", name, err)
+				if fileHashes && err == nil {
+					addFileHashes(report, &b.BaseBuilder)
+				}
+				writeBuildReport(reportPath, report)
+			}
+			if err != nil {
 				ui.PrintError("Build failed: %v", err)
-				os.Exit(1)
+				buildExit(1)
+			}
+			if stageOnly {
+				printStaged(quiet, filepath.Join(b.WorkDir, "stage"))
+				return
 			}
 
 			if quiet {
@@ -101,7 +245,259 @@ var buildCmd = &cobra.Command{
 	},
 }
 
+// checkoutGitRef adds a detached git worktree of ref for the repository containing
+// dir, in a temporary directory. It returns the project directory inside the
+// worktree and a function that removes the worktree.
+func checkoutGitRef(dir, ref string) (string, func(), error) {
+	if !version.IsGitRepo(dir) {
+		return "", nil, fmt.Errorf("--git-ref requires a git repository, and %s is not in one", dir)
+	}
+	if err := exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Run(); err != nil {
+		return "", nil, fmt.Errorf("unknown git ref '%s'", ref)
+	}
+
+	// The project may be in a subdirectory of the repository
+	prefix, err := exec.Command("git", "-C", dir, "rev-parse", "--show-prefix").Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read git repository: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", config.TempPrefix+"ref-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	worktree := filepath.Join(tmpDir, "src")
+	if output, err := exec.Command("git", "-C", dir, "worktree", "add", "--detach", worktree, ref).CombinedOutput(); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", nil, fmt.Errorf("failed to check out '%s': %w", ref, commandError(err, output))
+	}
+
+	cleanup := func() {
+		exec.Command("git", "-C", dir, "worktree", "remove", "--force", worktree).Run()
+		os.RemoveAll(tmpDir)
+	}
+	return filepath.Join(worktree, strings.TrimSpace(string(prefix))), cleanup, nil
+}
+
+// setBuildDir points a builder's output at dir/build, for builds whose sources are elsewhere
+func setBuildDir(b *builder.BaseBuilder, dir string) {
+	b.BuildDir = filepath.Join(dir, "build")
+	b.WorkDir = filepath.Join(b.BuildDir, "work")
+}
+
+// printStaged reports a --stage-only build
+func printStaged(quiet bool, stageDir string) {
+	if quiet {
+		ui.PrintSuccess("Staged: %s", stageDir)
+		return
+	}
+	fmt.Println()
+	fmt.Println(ui.Divider())
+	fmt.Println()
+	ui.PrintSuccess("Build complete!")
+	ui.PrintInfo("Stage: %s", ui.Highlight(stageDir))
+	fmt.Println()
+}
+
+// writeBuildReport writes a JSON build report, warning if it can't be written
+func writeBuildReport(path string, report *builder.BuildReport) {
+	if err := report.Write(path); err != nil {
+		ui.PrintWarning("%v", err)
+	}
+}
+
+// updateManifestWriter is implemented by the plugin and theme builders
+type updateManifestWriter interface {
+	WriteUpdateManifest(baseURL string) (string, error)
+}
+
+// writeUpdateManifest writes build/update.json for a plugin or theme build
+func writeUpdateManifest(b updateManifestWriter, baseURL string, quiet bool) {
+	path, err := b.WriteUpdateManifest(baseURL)
+	if err != nil {
+		ui.PrintError("%v", err)
+		exit(1)
+	}
+	if !quiet {
+		ui.PrintSuccess("Created: %s", filepath.Base(path))
+	}
+}
+
+// parseChecksums returns the checksum algorithms requested with --emit-checksum-file
+func parseChecksums(cmd *cobra.Command) ([]string, error) {
+	value, _ := cmd.Flags().GetString("emit-checksum-file")
+	if value == "" {
+		return nil, nil
+	}
+
+	var checksums []string
+	for _, algorithm := range strings.Split(value, ",") {
+		algorithm = strings.ToLower(strings.TrimSpace(algorithm))
+		supported := false
+		for _, a := range builder.ChecksumAlgorithms {
+			supported = supported || a == algorithm
+		}
+		if !supported {
+			return nil, fmt.Errorf("invalid --emit-checksum-file %q (use sha256, md5, or sha256,md5)", algorithm)
+		}
+		checksums = append(checksums, algorithm)
+	}
+	return checksums, nil
+}
+
+// addFileHashes adds the size and checksum of every packaged file to a report
+func addFileHashes(report *builder.BuildReport, b *builder.BaseBuilder) {
+	entries, err := b.FileEntries()
+	if err != nil {
+		ui.PrintWarning("%v", err)
+		return
+	}
+	report.FileHashes = entries
+}
+
+// listBuildFiles prints the files, libraries, and dependencies that would be
+// packaged for the project in dir, without producing any build artifacts
+func listBuildFiles(dir string, isTheme, isPlugin bool) error {
+	var main string
+	var include, exclude []string
+	var libraries, plugins []config.LibrarySpec
+
+	if isTheme {
+		cfg, err := config.LoadThemeProperties(dir)
+		if err != nil {
+			return err
+		}
+		main, include, exclude, libraries = cfg.Main, cfg.Include, cfg.Exclude, cfg.Libraries
+	} else if isPlugin {
+		cfg, err := config.LoadPluginProperties(dir)
+		if err != nil {
+			return err
+		}
+		main, include, exclude, libraries, plugins = cfg.Main, cfg.Include, cfg.Exclude, cfg.Libraries, cfg.Plugins
+	} else {
+		cfg, err := config.LoadLibraryProperties(dir)
+		if err != nil {
+			return err
+		}
+		include, exclude, libraries = cfg.Include, cfg.Exclude, cfg.Libraries
+	}
+
+	exclude, err := builder.EffectiveExcludes(dir, exclude)
+	if err != nil {
+		return err
+	}
+
+	files, err := builder.ListFiles(dir, main, include, exclude)
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	ui.PrintInfo("Files:")
+	for _, file := range files {
+		info, err := os.Stat(filepath.Join(dir, file))
+		if err != nil {
+			ui.PrintWarning("  %s: %v", file, err)
+			continue
+		}
+		total += info.Size()
+		fmt.Printf("  %s %s\n", file, ui.MutedStyle.Render(formatSize(info.Size())))
+	}
+
+	if len(libraries) > 0 {
+		fmt.Println()
+		ui.PrintInfo("Libraries:")
+		for _, lib := range libraries {
+			fmt.Printf("  %s %s\n", lib.Name, ui.MutedStyle.Render(lib.URL))
+		}
+	}
+
+	if len(plugins) > 0 {
+		fmt.Println()
+		ui.PrintInfo("Plugin dependencies:")
+		for _, plugin := range plugins {
+			fmt.Printf("  %s %s\n", plugin.Name, ui.MutedStyle.Render(plugin.URL))
+		}
+	}
+
+	fmt.Println()
+	ui.PrintKeyValue("Files", fmt.Sprintf("%d", len(files)))
+	ui.PrintKeyValue("Total size", formatSize(total)+" (before libraries and minification)")
+
+	return nil
+}
+
+// formatSize formats a byte count for display
+func formatSize(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+var buildDockerCmd = &cobra.Command{
+	Use:   "docker",
+	Short: "Build a Docker image for the plugin or theme",
+	Long:  "Build a Docker image containing WordPress with the plugin or theme pre-installed",
+	Run: func(cmd *cobra.Command, args []string) {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		noLatest, _ := cmd.Flags().GetBool("no-latest")
+		if !quiet {
+			ui.PrintHeader(Version)
+		}
+
+		labelFlags, _ := cmd.Flags().GetStringArray("label")
+		labels, err := config.ParseLabelList(labelFlags)
+		if err != nil {
+			ui.PrintError("%v", err)
+			exit(1)
+		}
+
+		dir, err := getProjectDir()
+		if err != nil {
+			ui.PrintError("Failed to determine project directory: %v", err)
+			exit(1)
+		}
+
+		d := builder.NewDockerBuilder(dir)
+		d.Quiet = quiet
+		d.NoLatest = noLatest
+		d.Labels = labels
+		d.PullPolicy, _ = cmd.Flags().GetString("base-image-pull-policy")
+		d.Push, _ = cmd.Flags().GetBool("push")
+		d.Registry, _ = cmd.Flags().GetString("registry")
+		d.CacheFrom, _ = cmd.Flags().GetStringArray("cache-from")
+		if err := d.Build(); err != nil {
+			ui.PrintError("Docker build failed: %v", err)
+			exit(1)
+		}
+	},
+}
+
 func init() {
 	buildCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	buildCmd.Flags().BoolP("list", "l", false, "List the files that would be packaged without building")
+	buildCmd.Flags().String("report", "", "Write a JSON build report to the given file (written on success and failure)")
+	buildCmd.Flags().Bool("stage-only", false, "Stop after staging files in build/work/stage, without creating the zip")
+	buildCmd.Flags().Bool("generate-screenshot", false, "Create a placeholder screenshot.png for themes that don't have one")
+	buildCmd.Flags().String("update-manifest", "", "Write build/update.json for self-hosted updates, with the zip downloaded from this base URL")
+	buildCmd.Flags().StringP("output", "o", "", "Write the zip to this path instead of build/<slug>-<version>.zip (parent directories are created)")
+	buildCmd.Flags().Bool("provenance", false, "Add a BUILD_INFO file with the git commit, branch, build time (SOURCE_DATE_EPOCH if set), and wordsmith version")
+	buildCmd.Flags().String("git-ref", "", "Build a git tag, branch, or commit from a temporary worktree instead of the working tree")
+	buildCmd.Flags().Bool("filelist-hashes", false, "Include each packaged file's size and SHA-256 in the build report (defaults the report to build/report.json)")
+	buildCmd.Flags().String("emit-checksum-file", "", "Write <zip>.sha256 and/or <zip>.md5 next to the zip: sha256 (the default with no value), md5, or sha256,md5")
+	buildCmd.Flags().Lookup("emit-checksum-file").NoOptDefVal = "sha256"
+	buildDockerCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	buildDockerCmd.Flags().Bool("no-latest", false, "Do not tag the image as latest")
+	buildDockerCmd.Flags().StringArray("label", nil, "Add a label to the image (key=value, repeatable)")
+	buildDockerCmd.Flags().String("base-image-pull-policy", "missing", "Base image pull policy: missing (use the cache), always (docker build --pull), or never")
+	buildDockerCmd.Flags().Bool("push", false, "Push the versioned image to --registry after building")
+	buildDockerCmd.Flags().String("registry", "", "Registry to push to, e.g. ghcr.io/owner (the image is pushed as <registry>/<slug>:v<version>)")
+	buildDockerCmd.Flags().StringArray("cache-from", nil, "Reuse layers from an image, e.g. the last pushed one (repeatable)")
+	buildCmd.AddCommand(buildDockerCmd)
 	rootCmd.AddCommand(buildCmd)
 }
@@ -93,8 +93,8 @@ description: "Use this skill when working on a WordPress plugin, theme, or libra
 
 ## CLI Commands
 
-### wordsmith init [plugin|theme|library]
-Initialize a new WordPress plugin, theme, or library project.
+### wordsmith init [plugin|block-plugin|theme|library|site]
+Initialize a new WordPress plugin, theme, library, or site project. `+"`block-plugin`"+` creates a plugin with a block.json, src/index.js, and a register_block_type call.
 
 Flags:
 - `+"`--name`"+` — Plugin/theme/library name (default: directory name)
@@ -102,8 +102,12 @@ Flags:
 - `+"`--author`"+` — Author name
 - `+"`--author-uri`"+` — Author website URL
 - `+"`--type`"+` — Theme type: block, classic, hybrid, or child
+- `+"`--block`"+` — Create a block plugin (same as init block-plugin)
 - `+"`--template`"+` — Parent theme name (for child themes)
 - `+"`--template-uri`"+` — Parent theme URL or path (for child themes)
+- `+"`--from <dir|zip>`"+` — Import an existing plugin or theme: writes plugin.properties/theme.properties from its main file or style.css header, inferring main, include, and text-domain
+- `+"`--fail-on-existing`"+` — Exit with code 2 if the properties file already exists (default)
+- `+"`--overwrite`"+` — Regenerate an existing project in place instead
 - `+"`--git, -g`"+` — Generate GitHub Actions build workflow and .gitignore
 - `+"`--claude, -c`"+` — Generate Claude Code support files
 
@@ -118,15 +122,41 @@ Build the plugin, theme, or library into a distributable ZIP file.
 
 Flags:
 - `+"`--quiet`"+` — Suppress output
+- `+"`--list`"+` — Preview the files, libraries, and dependencies that would be packaged
+- `+"`--report <file>`"+` — Write a JSON build report (written on success and failure)
+- `+"`--stage-only`"+` — Stop after staging build/work/stage (prints the path), without creating the zip
+- `+"`--generate-screenshot`"+` — Themes: package a placeholder screenshot.png (name and version) if the theme has none; otherwise the build warns
+- `+"`--filelist-hashes`"+` — Include each packaged file's path, size, and SHA-256 in the report (build/report.json by default)
+- `+"`--output <path>`"+` / `+"`-o`"+` — Write the zip to this path instead of build/<slug>-<version>.zip (parent directories are created)
+- `+"`--provenance`"+` — Add a BUILD_INFO file with the git commit, branch, build time (SOURCE_DATE_EPOCH if set), and wordsmith version
+- `+"`--emit-checksum-file[=sha256|md5|sha256,md5]`"+` — Write <zip>.sha256 (default) and/or <zip>.md5 next to the zip, in sha256sum/md5sum format
+- `+"`--update-manifest <base-url>`"+` — Write build/update.json (plugin-update-checker format) with the zip's download URL under the base URL
+- `+"`--git-ref <ref>`"+` — Build a tag, branch, or commit from a temporary git worktree; the version comes from that ref and the zip goes to build/
 
 Detects project type from properties file (plugin.properties, theme.properties, or library.properties).
+Can be run from a subdirectory: the nearest parent with a properties file is used as the project root (override with the global `+"`--root <dir>`"+` flag).
 Version is read from git tags using `+"`git describe --tags --match \"v*.*.*\"`"+`.
 
+### wordsmith build docker
+Build a Docker image with WordPress and the plugin or theme pre-installed.
+Tags the image as `+"`{slug}:v{version}`"+` and `+"`{slug}:latest`"+`.
+
+Flags:
+- `+"`--no-latest`"+` — Only tag the versioned image
+- `+"`--push --registry <registry>`"+` — Tag the image as <registry>/<slug>:v<version> and push it (also on `+"`site build docker`"+`)
+- `+"`--cache-from <image>`"+` — Reuse layers from an earlier (pushed) image, e.g. in CI; repeatable, also on `+"`site build docker`"+`
+- `+"`--base-image-pull-policy missing|always|never`"+` — Pull policy for the base image (`+"`always`"+` adds `+"`--pull`"+`; also on `+"`site build docker`"+`)
+
 ### wordsmith deploy [file]
 Build and deploy the plugin or theme to a local WordPress Docker environment.
 
 Flags:
 - `+"`--quiet`"+` — Suppress output
+- `+"`--target-path <wp-content>`"+` — Copy into a local (non-Docker) wp-content directory and activate with `+"`wp`"+` if installed
+- `+"`--stage-only`"+` — Deploy from build/work/stage without creating the zip
+- `+"`--only-deps`"+` — Install and activate the plugin's dependencies only, without deploying the plugin itself
+- `+"`--all`"+` — In a site project, build and deploy every plugin and theme in plugins/ and themes/, activating them as `+"`wordpress start`"+` would
+- `+"`--profile <name>`"+` — Merge the settings under `+"`profiles.<name>`"+` in plugin.properties over the base settings (defaults to `+"`$WORDSMITH_PROFILE`"+`)
 
 Automatically starts WordPress if not running. Handles plugin dependencies and theme parent chains.
 
@@ -137,17 +167,40 @@ Modes:
 - `+"`build`"+` — Rebuild on changes
 - `+"`deploy`"+` — Rebuild and redeploy on changes
 
-Uses 500ms debounce to avoid rapid rebuilds.
+Uses file system events, ignoring build/, hidden directories, and `+"`exclude`"+` patterns, with a 300ms debounce to avoid rapid rebuilds. Prints how long each run took.
+
+Flags:
+- `+"`--assets-only`"+` — In deploy mode, copy changed CSS/JS (minified if enabled) straight into the container instead of redeploying; other changes still run a full deploy
 
 ### wordsmith wordpress [command]
 Manage WordPress Docker development environments.
 
 Subcommands:
-- `+"`start [file]`"+` — Start WordPress in Docker (auto-assigns ports 8080-8099)
+- `+"`start [file]`"+` — Start WordPress in Docker (auto-assigns ports 8080-8099, reusing the project's last ports from .wordsmith/state.json when free, or exactly the port/db-port set in the properties file)
+  - `+"`--open-site`"+` / `+"`--open-admin`"+` — Choose which URLs open in the browser (remembered in .wordsmith/state.json)
 - `+"`stop [name]`"+` — Stop WordPress containers
+- `+"`logs [name]`"+` — Follow docker logs of the WordPress container (`+"`--mysql`"+` for MySQL, `+"`--tail N`"+`)
+- `+"`shell [name]`"+` — Interactive bash (or sh) in the WordPress container (`+"`--wp`"+` for a WP-CLI container shell instead)
+- `+"`pause [name]`"+` / `+"`unpause [name]`"+` — docker pause/unpause the environment's containers (state and ports kept; shown as paused in ps)
 - `+"`ps`"+` — List all WordPress environments with status
 - `+"`delete [name]`"+` — Delete WordPress environment and data
 - `+"`browse [name]`"+` — Open WordPress in browser
+- `+"`list-plugins [name]`"+` — List installed plugins (`+"`--active`"+`, `+"`--format table|json`"+`)
+- `+"`list-themes [name]`"+` — List installed themes (`+"`--active`"+`, `+"`--format table|json`"+`)
+- `+"`port [name]`"+` — Print only the published WordPress port (`+"`--mysql`"+` for MySQL); non-zero exit if not running
+- `+"`switch-theme [slug] [name]`"+` — Activate a theme (installed from WordPress.org if missing); defaults to the project's theme
+- `+"`sites create <site-slug> [name]`"+` — Create a network site in a multisite environment (`+"`--title`"+`)
+- `+"`sites list [name]`"+` — List network sites (`+"`--format table|json`"+`)
+- `+"`clean-uploads [name]`"+` — Remove files under wp-content/uploads (`+"`--older-than <days>`"+`, `+"`--regenerate`"+`, `+"`--force`"+` to skip the prompt)
+- `+"`whoami [name]`"+` — Print the admin username, email, and wp-admin URL (`+"`--show-password`"+` to include the password)
+- `+"`copy-from <source> [name]`"+` — Replace the database with a copy of another running environment's and search-replace its URL (`+"`--force`"+` skips the prompt)
+- `+"`search-replace <old> [new] [name]`"+` — Run wp search-replace (skips GUIDs); `+"`new`"+` defaults to the environment's URL (hostname or localhost, on its port), `+"`--dry-run`"+` previews
+- `+"`theme-json-dump [name]`"+` — Print the active theme's resolved theme.json as JSON (`+"`--origin default|blocks|theme|custom`"+`)
+- `+"`diff-options [name]`"+` — Print the options whose database value differs from plugin.properties settings, exiting non-zero (`+"`--apply`"+` writes the configured values, `+"`--profile <name>`"+`)
+- `+"`debug-log [name]`"+` — Copy WP_DEBUG_LOG's file (wp-content/debug.log) to ./debug.log (`+"`--output`"+`), then truncate it with `+"`--clear`"+`
+- `+"`run-upgrade [name]`"+` — Install an old version of the plugin (`+"`--from`"+` version, zip, or URL), import `+"`--snapshot`"+`, deploy the working copy over it, reactivate it, and run `+"`--eval`"+` PHP
+- `+"`mailto [name]`"+` — Send a test email with wp_mail (`+"`--to`"+` defaults to the admin email, `+"`--subject`"+`)
+- `+"`proxy <hostname> [name]`"+` — Serve WordPress on a custom hostname (prints the /etc/hosts line; `+"`--manage-hosts`"+` adds it with sudo, also on `+"`start`"+`, and `+"`delete --manage-hosts`"+` removes it)
 
 ### wordsmith site [command]
 Manage WordPress site projects with multiple plugins and themes.
@@ -158,7 +211,7 @@ Subcommands:
 - `+"`stop`"+` — Stop WordPress for the site
 - `+"`delete`"+` — Delete WordPress environment
 - `+"`build`"+` — Build all local plugins/themes in the site
-- `+"`build docker`"+` — Create Docker image with site pre-installed
+- `+"`build docker`"+` — Create Docker image with site pre-installed (`+"`--strict`"+` fails if any plugin or theme can't be bundled)
 
 ### wordsmith add [feature]
 Add features to an existing project.
@@ -167,8 +220,31 @@ Available features:
 - `+"`git`"+` — GitHub Actions build workflow and .gitignore
 - `+"`claude`"+` — Claude Code support files
 
+### wordsmith config print
+Print the effective configuration (all properties files, derived slugs, applied defaults, and the WordPress environment name/image/port) as JSON or YAML (`+"`--format yaml`"+`).
+
+### wordsmith validate
+Lint the project's properties files without building: the main file exists, include patterns match something, child themes set `+"`template`"+` and `+"`template-uri`"+`, block patterns have Title and Slug headers, style variations in styles/ are valid JSON, versions parse, and `+"`text-domain`"+` is a valid slug. Prints each problem as `+"`file: key: message`"+` and exits non-zero if there are any.
+
+### wordsmith clean --temp
+Remove stray `+"`wordsmith-*`"+` files and directories from the system temp directory left by interrupted builds. `+"`--older-than`"+` (default 1h, 0 for all) spares runs in progress.
+
+### --json-events
+Global flag: emit newline-delimited JSON events (`+"`{phase, message, level, ts}`"+`) on stdout instead of styled output, covering build phases, downloads, and deploy steps. Other output goes to stderr.
+
+### --no-cache
+Global flag (or `+"`WORDSMITH_NO_CACHE=1`"+`): bypass the library cache (~/.wordsmith/libraries) and the download cache (/tmp/wordsmith/cache) for this run without populating them. Existing build/ zips of local dependencies are still reused.
+
+### GITHUB_TOKEN
+Set `+"`GITHUB_TOKEN`"+` (or `+"`WORDSMITH_GITHUB_TOKEN`"+`) to authenticate GitHub API and release asset requests, for private repositories and higher rate limits. The token is only sent to github.com and api.github.com, never to redirect targets like objects.githubusercontent.com.
+
+### --max-download-size
+Global flag (or `+"`WORDSMITH_MAX_DOWNLOAD_SIZE`"+`): largest library, theme, or plugin download to accept, e.g. `+"`200MB`"+`; `+"`0`"+` disables the limit. Defaults to 1GB.
+
 ### wordsmith completion [shell]
 Generate shell completion scripts (bash, zsh, fish, powershell).
+`+"`wordsmith completion install`"+` installs completion for the current shell.
+Environment names (stop/delete/proxy) and .properties files (start/deploy) complete dynamically.
 
 ## Configuration Files
 
@@ -185,6 +261,9 @@ license-uri=https://www.gnu.org/licenses/gpl-2.0.html
 # Main plugin file
 main=my-plugin.php
 
+# Top-level folder inside the zip, if it must differ from the slug (optional)
+zip-folder=my-plugin-pro
+
 # WordPress requirements
 requires=5.0
 requires-php=7.4
@@ -203,11 +282,25 @@ domain-path=/languages
 libraries=my-library
 plugins=dependency-plugin
 
+# Opt out of WordPress.org updates: a URL or false
+update-uri=https://example.com/my-plugin
+
+# readme.txt header when the build generates one (no readme.txt in the project)
+tags=forms,email
+contributors=janedoe
+tested-up-to=6.6
+
+# Network-only plugin on multisite (adds "Network: true" to the header)
+network=false
+
 # Minification and obfuscation
 minify=true
+minify-exclude=assets/vendor
 obfuscate=false
 `+"```"+`
 
+Exclude patterns can also go in a gitignore-style `+"`.wordsmithignore`"+` file in the project directory. Its patterns apply on top of `+"`exclude`"+` and the last match wins, so `+"`!readme.md`"+` re-includes a file excluded by `+"`*.md`"+`. Excluding a directory excludes everything nested in it, and a leading `+"`/`"+` anchors a pattern to the project root.
+
 ### theme.properties
 `+"```properties"+`
 # Theme Configuration
@@ -238,6 +331,10 @@ domain-path=/languages
 # Theme tags
 tags=custom-logo,custom-menu,editor-style
 
+# Features added as add_theme_support() calls in the built functions.php
+# (after_setup_theme function; features already declared there are kept as written)
+supports=post-thumbnails,custom-logo,align-wide
+
 # Parent theme (for child themes)
 # template=parent-theme
 # template-uri=https://github.com/user/parent-theme
@@ -267,6 +364,57 @@ description=Development site
 # Plugins and themes to install
 plugins=plugin-slug,https://example.com/plugin.zip
 themes=theme-slug
+# A YAML entry with skip: true is ignored (also in site.properties and plugin dependencies)
+# A YAML entry's sha256 (also on libraries) is checked against the downloaded zip when building and on wordpress start
+# A YAML plugin entry's order (or priority) sets activation order: lower numbers activate first (default 0)
+# Shared JSON list of plugins and themes (cached 10 minutes); local entries with the same slug win
+plugins-from=https://example.com/plugins.json
+
+# Database image (defaults to mysql:8.0; MariaDB images such as mariadb:11 work too)
+db-image=mysql:8.0
+
+# Database and admin logins (defaults: wordpress/wordpress/wordpress, admin/admin, admin@localhost.com)
+db-name=wordpress
+db-user=wordpress
+db-password=wordpress
+admin-user=admin
+admin-password=admin
+admin-email=admin@localhost.com
+
+# docker --env-file for the WordPress container (wordsmith's WORDPRESS_DB_* -e values win)
+env-file=.env.wordpress
+
+# Custom hostname for the site URLs (add it to /etc/hosts)
+hostname=myplugin.local
+
+# Fixed WordPress and MySQL ports (start fails if one is in use)
+port=8123
+db-port=3345
+
+# Restart containers after a reboot (no, unless-stopped, always)
+restart-policy=unless-stopped
+
+# PHP extensions to install (docker-php-ext-install or pecl)
+php-extensions=redis,intl
+
+# Raise PHP upload_max_filesize/post_max_size and memory_limit (also max_execution_time=300)
+upload-limit=256M
+memory-limit=512M
+
+# Cap each container's memory and CPUs (docker run --memory/--cpus)
+memory=1g
+cpus=1.5
+
+# Run a Redis container and enable the object cache
+redis=true
+
+# Install as a multisite network (true/subdirectory or subdomain); plugins are network-activated
+multisite=true
+
+# Commands run once by built Docker images after activation (YAML list; wp runs with WP_CLI_ALLOW_ROOT=1)
+# after-install:
+#   - wp option update blogdescription "Demo site"
+# after-install-strict: true
 `+"```"+`
 
 ### site.properties
@@ -281,6 +429,9 @@ image=wordpress:latest
 # Plugins and themes (local paths or remote)
 plugins=../my-plugin,other-plugin
 themes=../my-theme
+
+# Activate every bundled plugin in site build docker images (for demos, not production)
+# activate-all=true
 `+"```"+`
 
 ## Project Structure
@@ -329,6 +480,10 @@ my-theme/
 ├── parts/
 │   ├── header.html        # Header block part
 │   └── footer.html        # Footer block part
+├── patterns/
+│   └── hero.php           # Block pattern (Title and Slug headers required)
+├── styles/
+│   └── dark.json          # Style variation (must be valid JSON)
 ├── assets/
 ├── languages/
 └── .gitignore
@@ -339,9 +494,13 @@ my-theme/
 Wordsmith reads version from git tags using `+"`git describe --tags --match \"v*.*.*\"`"+`. The version format is:
 - Clean tag: `+"`v1.2.3`"+` → `+"`1.2.3`"+`
 - Commits ahead: `+"`v1.2.3-5-gabcdef`"+` → `+"`1.2.3-5`"+`
+- Pre-release tag: `+"`v1.2.3-beta.1`"+` → `+"`1.2.3-beta.1`"+`
 - Dirty working tree: appends timestamp
+- No version tag: the `+"`Version:`"+` header of the main file (plugins) or style.css (themes), else `+"`0.1.0`"+`
+
+A `+"`version`"+` property overrides all of these.
 
-A `+"`version.properties`"+` file is generated during build with `+"`major`"+`, `+"`minor`"+`, and `+"`maintenance`"+` fields, which PHP code reads at runtime.
+A `+"`version.properties`"+` file is generated during build with `+"`major`"+`, `+"`minor`"+`, `+"`patch`"+`, `+"`prerelease`"+`, and `+"`build`"+` fields, which PHP code reads at runtime (plus the legacy `+"`maintenance`"+`, the patch with its suffixes).
 
 ## Build Process
 
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"wordsmith/internal/config"
+	"wordsmith/internal/ui"
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove files left behind by earlier runs",
+	Long:  "Remove files left behind by earlier runs. --temp sweeps wordsmith-* files and directories from the system temp directory, such as extracted libraries and --no-cache downloads from builds that were interrupted",
+	Run: func(cmd *cobra.Command, args []string) {
+		temp, _ := cmd.Flags().GetBool("temp")
+		olderThan, _ := cmd.Flags().GetDuration("older-than")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+
+		if !quiet {
+			ui.PrintHeader(Version)
+		}
+		if !temp {
+			cmd.Help()
+			return
+		}
+
+		removed, err := config.SweepTempDirs(olderThan)
+		if err != nil {
+			ui.PrintError("Failed to clean temp directory: %v", err)
+			exit(1)
+		}
+
+		if len(removed) == 0 {
+			ui.PrintInfo("No stray temp files in %s", os.TempDir())
+			return
+		}
+		if !quiet {
+			for _, path := range removed {
+				ui.PrintInfo("  Removed %s", path)
+			}
+		}
+		ui.PrintSuccess("Removed %d temp file(s)", len(removed))
+	},
+}
+
+func init() {
+	cleanCmd.Flags().Bool("temp", false, "Remove stray wordsmith-* files and directories from the system temp directory")
+	cleanCmd.Flags().Duration("older-than", time.Hour, "Only remove temp files not modified within this duration, so running builds are left alone (0 removes all)")
+	cleanCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	rootCmd.AddCommand(cleanCmd)
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -69,13 +70,13 @@ var completionInstallCmd = &cobra.Command{
 		shell := detectShell()
 		if shell == "" {
 			ui.PrintError("Could not detect shell. Please use 'wordsmith completion [bash|zsh|fish|powershell]' manually")
-			os.Exit(1)
+			exit(1)
 		}
 
 		home, err := os.UserHomeDir()
 		if err != nil {
 			ui.PrintError("Could not find home directory: %v", err)
-			os.Exit(1)
+			exit(1)
 		}
 
 		var completionDir, completionFile, rcFile, sourceLine string
@@ -101,20 +102,20 @@ var completionInstallCmd = &cobra.Command{
 
 		default:
 			ui.PrintError("Auto-install not supported for %s. Please use 'wordsmith completion %s' manually", shell, shell)
-			os.Exit(1)
+			exit(1)
 		}
 
 		// Create completion directory
 		if err := os.MkdirAll(completionDir, 0755); err != nil {
 			ui.PrintError("Failed to create completion directory: %v", err)
-			os.Exit(1)
+			exit(1)
 		}
 
 		// Generate and write completion script
 		f, err := os.Create(completionFile)
 		if err != nil {
 			ui.PrintError("Failed to create completion file: %v", err)
-			os.Exit(1)
+			exit(1)
 		}
 
 		switch shell {
@@ -150,6 +151,38 @@ var completionInstallCmd = &cobra.Command{
 	},
 }
 
+// completeEnvironments completes the name of a wordsmith WordPress environment
+// from the labels on existing containers
+func completeEnvironments(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	output, err := exec.Command("docker", "ps", "-a",
+		"--filter", "label=wordsmith.type=wordpress",
+		"--format", "{{.Label \"wordsmith.project\"}}",
+	).Output()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, name := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if name != "" && strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePropertiesFiles completes a single .properties file argument
+func completePropertiesFiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return []string{"properties"}, cobra.ShellCompDirectiveFilterFileExt
+}
+
 func detectShell() string {
 	shell := os.Getenv("SHELL")
 	if strings.Contains(shell, "zsh") {
@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"wordsmith/internal/builder"
+	"wordsmith/internal/config"
+	"wordsmith/internal/ui"
+)
+
+// propertyDefaults lists the defaults applied when a key is missing from a properties file
+var propertyDefaults = map[string]map[string]string{
+	"plugin.properties":    {},
+	"theme.properties":     {"main": "style.css"},
+	"library.properties":   {},
+	"site.properties":      environmentDefaults(),
+	"wordpress.properties": environmentDefaults(),
+}
+
+// environmentDefaults lists the defaults shared by site.properties and wordpress.properties
+func environmentDefaults() map[string]string {
+	return map[string]string{
+		"image":          "wordpress:latest",
+		"db-image":       config.DefaultDBImage,
+		"restart-policy": "no",
+		"db-name":        config.DefaultDBName,
+		"db-user":        config.DefaultDBUser,
+		"db-password":    config.DefaultDBPassword,
+		"admin-user":     config.DefaultAdminUser,
+		"admin-password": config.DefaultAdminPassword,
+		"admin-email":    config.DefaultAdminEmail,
+	}
+}
+
+// resolvedFile is the effective configuration loaded from one properties file
+type resolvedFile struct {
+	File     string            `json:"file"`
+	Type     string            `json:"type"`
+	Slug     string            `json:"slug,omitempty"`
+	Defaults map[string]string `json:"defaults,omitempty"`
+	Config   interface{}       `json:"config"`
+}
+
+// resolvedEnvironment is the WordPress environment `wordpress start` would use
+type resolvedEnvironment struct {
+	Source string `json:"source"`
+	Name   string `json:"name"`
+	Slug   string `json:"slug"`
+	Image  string `json:"image"`
+	Port   string `json:"port,omitempty"`
+}
+
+// resolvedConfig is the output of `config print`
+type resolvedConfig struct {
+	ProjectDir  string               `json:"projectDir"`
+	Files       []resolvedFile       `json:"files"`
+	Environment *resolvedEnvironment `json:"environment,omitempty"`
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect project configuration",
+	Run: func(cmd *cobra.Command, args []string) {
+		ui.PrintHeader(Version)
+		cmd.Help()
+	},
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print the fully-resolved configuration",
+	Long:  "Load every properties file in the project and print the effective configuration, including defaults and the WordPress environment it resolves to",
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		if format != "json" && format != "yaml" {
+			ui.PrintError("Invalid format '%s'. Use 'json' or 'yaml'", format)
+			exit(1)
+		}
+
+		dir, err := getProjectDir()
+		if err != nil {
+			ui.PrintError("Failed to determine project directory: %v", err)
+			exit(1)
+		}
+
+		resolved, err := resolveConfig(dir)
+		if err != nil {
+			ui.PrintError("%v", err)
+			exit(1)
+		}
+		if len(resolved.Files) == 0 {
+			ui.PrintError("No properties files found in %s", dir)
+			exit(1)
+		}
+
+		// Round-trip through JSON so both formats use the same keys
+		data, err := json.MarshalIndent(resolved, "", "  ")
+		if err != nil {
+			ui.PrintError("Failed to encode configuration: %v", err)
+			exit(1)
+		}
+
+		if format == "yaml" {
+			var generic interface{}
+			if err := json.Unmarshal(data, &generic); err != nil {
+				ui.PrintError("Failed to encode configuration: %v", err)
+				exit(1)
+			}
+			data, err = yaml.Marshal(generic)
+			if err != nil {
+				ui.PrintError("Failed to encode configuration: %v", err)
+				exit(1)
+			}
+			fmt.Print(string(data))
+			return
+		}
+
+		fmt.Println(string(data))
+	},
+}
+
+// resolveConfig loads every properties file in dir
+func resolveConfig(dir string) (*resolvedConfig, error) {
+	resolved := &resolvedConfig{ProjectDir: dir, Files: []resolvedFile{}}
+
+	for _, filename := range config.ProjectFiles {
+		if !config.PropertiesFileExists(dir, filename) {
+			continue
+		}
+
+		file := resolvedFile{File: filename}
+		var err error
+
+		switch filename {
+		case "plugin.properties":
+			var cfg *config.PluginConfig
+			cfg, err = config.LoadPluginProperties(dir)
+			if err == nil {
+				b := &builder.Builder{Config: cfg}
+				file.Type, file.Slug, file.Config = "plugin", b.GetPluginSlug(), cfg
+			}
+		case "theme.properties":
+			var cfg *config.ThemeConfig
+			cfg, err = config.LoadThemeProperties(dir)
+			if err == nil {
+				b := &builder.ThemeBuilder{Config: cfg}
+				file.Type, file.Slug, file.Config = "theme", b.GetThemeSlug(), cfg
+			}
+		case "library.properties":
+			var cfg *config.LibraryConfig
+			cfg, err = config.LoadLibraryProperties(dir)
+			if err == nil {
+				b := &builder.LibraryBuilder{Config: cfg}
+				file.Type, file.Slug, file.Config = "library", b.GetLibrarySlug(), cfg
+			}
+		case "site.properties":
+			var cfg *config.SiteConfig
+			cfg, err = config.LoadSiteProperties(dir)
+			if err == nil {
+				file.Type, file.Config = "site", cfg
+			}
+		case "wordpress.properties":
+			var cfg *config.WordPressConfig
+			cfg, err = config.LoadWordPressProperties(dir)
+			if err == nil {
+				file.Type, file.Config = "wordpress", cfg
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", filename, err)
+		}
+
+		file.Defaults, err = missingDefaults(filepath.Join(dir, filename))
+		if err != nil {
+			return nil, err
+		}
+		resolved.Files = append(resolved.Files, file)
+	}
+
+	resolved.Environment = resolveEnvironment(dir, resolved.Files)
+	return resolved, nil
+}
+
+// missingDefaults returns the defaults applied for keys not set in a properties file
+func missingDefaults(path string) (map[string]string, error) {
+	props, err := config.ParseProperties(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := make(map[string]string)
+	for key, value := range propertyDefaults[filepath.Base(path)] {
+		if _, ok := props[key]; !ok {
+			defaults[key] = value
+		}
+	}
+	return defaults, nil
+}
+
+// resolveEnvironment mirrors how `wordpress start` picks the environment name and image:
+// site.properties, then wordpress.properties, then plugin/theme properties
+func resolveEnvironment(dir string, files []resolvedFile) *resolvedEnvironment {
+	env := &resolvedEnvironment{Image: "wordpress:latest"}
+
+	for _, source := range []string{"site", "wordpress", "plugin", "theme"} {
+		for _, file := range files {
+			if file.Type != source {
+				continue
+			}
+			env.Source = file.File
+			switch cfg := file.Config.(type) {
+			case *config.SiteConfig:
+				env.Name, env.Image = cfg.Name, cfg.Image
+			case *config.WordPressConfig:
+				env.Name, env.Image = cfg.Name, cfg.Image
+			case *config.PluginConfig:
+				env.Name = cfg.Name
+			case *config.ThemeConfig:
+				env.Name = cfg.Name
+			}
+			break
+		}
+		if env.Source != "" {
+			break
+		}
+	}
+
+	if env.Source == "" {
+		return nil
+	}
+
+	if env.Name == "" {
+		name, err := getInstanceName(dir)
+		if err == nil {
+			env.Name = name
+		}
+	}
+	if env.Name == "" {
+		env.Name = filepath.Base(dir)
+	}
+
+	env.Slug = sanitizePluginName(env.Name)
+	if isCommandAvailable("docker") && isContainerRunning(env.Slug+"-wordpress") {
+		env.Port = getContainerPort(env.Slug + "-wordpress")
+	}
+
+	return env
+}
+
+func init() {
+	configPrintCmd.Flags().String("format", "json", "Output format: json or yaml")
+	configCmd.AddCommand(configPrintCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -15,19 +15,53 @@ import (
 )
 
 var deployCmd = &cobra.Command{
-	Use:   "deploy [file]",
-	Short: "Build and deploy plugin or theme to WordPress",
-	Args:  cobra.MaximumNArgs(1),
+	Use:               "deploy [file]",
+	Short:             "Build and deploy plugin or theme to WordPress",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completePropertiesFiles,
 	Run: func(cmd *cobra.Command, args []string) {
 		quiet, _ := cmd.Flags().GetBool("quiet")
 		if !quiet {
 			ui.PrintHeader(Version)
 		}
 
-		dir, err := os.Getwd()
+		dir, err := getProjectDir()
 		if err != nil {
-			ui.PrintError("Failed to get current directory: %v", err)
-			os.Exit(1)
+			ui.PrintError("Failed to determine project directory: %v", err)
+			exit(1)
+		}
+
+		// Deploy every plugin and theme in a site project
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			if !config.SiteExists(dir) {
+				ui.PrintError("--all requires a site project (no site.properties found in current directory)")
+				exit(1)
+			}
+			if targetPath, _ := cmd.Flags().GetString("target-path"); targetPath != "" {
+				ui.PrintError("--all cannot be used with --target-path")
+				exit(1)
+			}
+			if onlyDeps, _ := cmd.Flags().GetBool("only-deps"); onlyDeps {
+				ui.PrintError("--all cannot be used with --only-deps")
+				exit(1)
+			}
+			stageOnly, _ := cmd.Flags().GetBool("stage-only")
+			deployed, err := deploySite(dir, stageOnly, quiet)
+			if err != nil {
+				ui.PrintError("%v", err)
+				exit(1)
+			}
+			ui.SetPhase(builder.PhaseComplete)
+			if !quiet {
+				fmt.Println()
+				fmt.Println(ui.Divider())
+				fmt.Println()
+			}
+			ui.PrintSuccess("Deployed %d plugins and themes to WordPress!", deployed)
+			if !quiet {
+				fmt.Println()
+			}
+			return
 		}
 
 		isTheme := config.ThemeExists(dir)
@@ -37,10 +71,43 @@ var deployCmd = &cobra.Command{
 		if !isTheme && !isPlugin {
 			if isLibrary {
 				ui.PrintError("Libraries cannot be deployed directly to WordPress.")
-				os.Exit(1)
+				exit(1)
 			}
 			ui.PrintError("No plugin.properties or theme.properties found in current directory")
-			os.Exit(1)
+			exit(1)
+		}
+
+		onlyDeps, _ := cmd.Flags().GetBool("only-deps")
+		stageOnly, _ := cmd.Flags().GetBool("stage-only")
+		profile, _ := cmd.Flags().GetString("profile")
+		if profile == "" {
+			profile = os.Getenv("WORDSMITH_PROFILE")
+		}
+		if onlyDeps && isTheme {
+			ui.PrintError("--only-deps is only supported for plugins")
+			exit(1)
+		}
+
+		// Deploy to a local WordPress install instead of Docker
+		if targetPath, _ := cmd.Flags().GetString("target-path"); targetPath != "" {
+			if onlyDeps {
+				ui.PrintError("--only-deps cannot be used with --target-path")
+				exit(1)
+			}
+			if err := deployToPath(dir, targetPath, isTheme, stageOnly, quiet); err != nil {
+				ui.PrintError("%v", err)
+				exit(1)
+			}
+			if quiet {
+				ui.PrintSuccess("Deployed to %s", targetPath)
+			} else {
+				fmt.Println()
+				fmt.Println(ui.Divider())
+				fmt.Println()
+				ui.PrintSuccess("Deployed to %s", targetPath)
+				fmt.Println()
+			}
+			return
 		}
 
 		// Determine which properties file to use for WordPress instance
@@ -53,7 +120,7 @@ var deployCmd = &cobra.Command{
 			}
 			if !config.FileExists(propsFile) {
 				ui.PrintError("Properties file not found: %s", propsFile)
-				os.Exit(1)
+				exit(1)
 			}
 		} else {
 			// Check for wordpress.properties first, then use plugin/theme name
@@ -71,7 +138,7 @@ var deployCmd = &cobra.Command{
 				wpConfig, err := config.LoadWordPressProperties(filepath.Dir(propsFile))
 				if err != nil {
 					ui.PrintError("Failed to load %s: %v", filename, err)
-					os.Exit(1)
+					exit(1)
 				}
 				instanceName = wpConfig.Name
 			}
@@ -102,42 +169,27 @@ var deployCmd = &cobra.Command{
 			cfg, err := config.LoadThemeProperties(dir)
 			if err != nil {
 				ui.PrintError("Failed to load theme.properties: %v", err)
-				os.Exit(1)
+				exit(1)
 			}
 
 			slug = sanitizeForDocker(cfg.Name)
 
 			// Check if WordPress container is running
 			containerName := instanceSlug + "-wordpress"
-			if !isContainerRunning(containerName) {
-				if !quiet {
-					ui.PrintInfo("WordPress is not running, starting it...")
-					fmt.Println()
-				}
-				var startArgs []string
-				startArgs = append(startArgs, "wordpress", "start")
-				if propsFile != "" {
-					startArgs = append(startArgs, propsFile)
-				}
-				startArgs = append(startArgs, "--quiet")
-				startCmd := exec.Command(os.Args[0], startArgs...)
-				startCmd.Stdout = os.Stdout
-				startCmd.Stderr = os.Stderr
-				startCmd.Dir = dir
-				if err := startCmd.Run(); err != nil {
-					ui.PrintError("Failed to start WordPress: %v", err)
-					os.Exit(1)
-				}
-				fmt.Println()
+			if err := ensureWordPressRunning(dir, propsFile, containerName, quiet); err != nil {
+				ui.PrintError("Failed to start WordPress: %v", err)
+				exit(1)
 			}
 
 			b := builder.NewThemeBuilder(dir)
 			b.Quiet = quiet
+			b.StageOnly = stageOnly
 			if err := b.Build(); err != nil {
 				ui.PrintError("Build failed: %v", err)
-				os.Exit(1)
+				exit(1)
 			}
 
+			ui.SetPhase("deploy")
 			if !quiet {
 				fmt.Println()
 				ui.PrintInfo("Deploying theme to WordPress...")
@@ -154,13 +206,9 @@ var deployCmd = &cobra.Command{
 
 				parentContainerPath := fmt.Sprintf("/var/www/html/wp-content/themes/%s", parentSlug)
 
-				dockerCmd := exec.Command("docker", "exec", containerName, "rm", "-rf", parentContainerPath)
-				dockerCmd.Run()
-
-				dockerCmd = exec.Command("docker", "cp", parent.Path+"/.", containerName+":"+parentContainerPath)
-				if err := dockerCmd.Run(); err != nil {
+				if err := replaceInContainer(containerName, parent.Path, parentContainerPath); err != nil {
 					ui.PrintError("Failed to deploy parent theme '%s': %v", parent.Name, err)
-					os.Exit(1)
+					exit(1)
 				}
 			}
 
@@ -168,69 +216,59 @@ var deployCmd = &cobra.Command{
 			stageDir = fmt.Sprintf("%s/build/work/stage", dir)
 			containerPath = fmt.Sprintf("/var/www/html/wp-content/themes/%s", slug)
 
-			dockerCmd := exec.Command("docker", "exec", containerName, "rm", "-rf", containerPath)
-			dockerCmd.Run()
-
-			dockerCmd = exec.Command("docker", "cp", stageDir+"/.", containerName+":"+containerPath)
-			if err := dockerCmd.Run(); err != nil {
+			if err := replaceInContainer(containerName, stageDir, containerPath); err != nil {
 				ui.PrintError("Failed to deploy: %v", err)
-				os.Exit(1)
+				exit(1)
 			}
 
 			// Activate theme
 			networkName := instanceSlug + "-network"
+			credentials := instanceCredentials(instanceSlug)
 			activateCmd := exec.Command("docker", "run", "--rm",
 				"--network", networkName,
 				"--user", "33:33",
 				"-v", instanceSlug+"-wp:/var/www/html",
 				"-e", "WORDPRESS_DB_HOST="+instanceSlug+"-mysql",
-				"-e", "WORDPRESS_DB_USER=wordpress",
-				"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-				"-e", "WORDPRESS_DB_NAME=wordpress",
+				"-e", "WORDPRESS_DB_USER="+credentials.DBUser,
+				"-e", "WORDPRESS_DB_PASSWORD="+credentials.DBPassword,
+				"-e", "WORDPRESS_DB_NAME="+credentials.DBName,
 				"wordpress:cli",
 				"wp", "theme", "activate", slug,
 			)
-			activateCmd.Run()
+			if output, err := activateCmd.CombinedOutput(); err != nil {
+				ui.PrintWarning("Failed to activate theme '%s': %v", slug, commandError(err, output))
+			}
 		} else {
 			cfg, err := config.LoadPluginProperties(dir)
 			if err != nil {
 				ui.PrintError("Failed to load plugin.properties: %v", err)
-				os.Exit(1)
+				exit(1)
 			}
 
 			slug = sanitizeForDocker(cfg.Name)
 
+			settings, err := cfg.SettingsForProfile(profile)
+			if err != nil {
+				ui.PrintError("%v", err)
+				exit(1)
+			}
+
 			// Check if WordPress container is running
 			containerName := instanceSlug + "-wordpress"
-			if !isContainerRunning(containerName) {
-				if !quiet {
-					ui.PrintInfo("WordPress is not running, starting it...")
-					fmt.Println()
-				}
-				var startArgs []string
-				startArgs = append(startArgs, "wordpress", "start")
-				if propsFile != "" {
-					startArgs = append(startArgs, propsFile)
-				}
-				startArgs = append(startArgs, "--quiet")
-				startCmd := exec.Command(os.Args[0], startArgs...)
-				startCmd.Stdout = os.Stdout
-				startCmd.Stderr = os.Stderr
-				startCmd.Dir = dir
-				if err := startCmd.Run(); err != nil {
-					ui.PrintError("Failed to start WordPress: %v", err)
-					os.Exit(1)
-				}
-				fmt.Println()
+			if err := ensureWordPressRunning(dir, propsFile, containerName, quiet); err != nil {
+				ui.PrintError("Failed to start WordPress: %v", err)
+				exit(1)
 			}
 
 			b := builder.New(dir)
 			b.Quiet = quiet
+			b.StageOnly = stageOnly
 			if err := b.Build(); err != nil {
 				ui.PrintError("Build failed: %v", err)
-				os.Exit(1)
+				exit(1)
 			}
 
+			ui.SetPhase("deploy")
 			if !quiet {
 				fmt.Println()
 				ui.PrintInfo("Deploying to WordPress...")
@@ -238,52 +276,80 @@ var deployCmd = &cobra.Command{
 
 			// Deploy plugin dependencies first
 			networkName := instanceSlug + "-network"
+			credentials := instanceCredentials(instanceSlug)
 			dependencies := b.GetPluginDependencies()
 			if len(dependencies) > 0 {
 				if err := deployPluginDependencies(dependencies, containerName, networkName, instanceSlug, quiet); err != nil {
 					ui.PrintError("Failed to deploy plugin dependencies: %v", err)
-					os.Exit(1)
+					exit(1)
 				}
 			}
 
+			// Leave the main plugin out, e.g. when developing it in a mounted directory
+			if onlyDeps {
+				if len(dependencies) == 0 {
+					ui.PrintWarning("No plugin dependencies to deploy")
+				}
+				ui.SetPhase(builder.PhaseComplete)
+				if !quiet {
+					fmt.Println()
+				}
+				ui.PrintSuccess("Deployed %d plugin dependencies", len(dependencies))
+				if !quiet {
+					fmt.Println()
+				}
+				return
+			}
+
 			stageDir = fmt.Sprintf("%s/build/work/stage", dir)
 			containerPath = fmt.Sprintf("/var/www/html/wp-content/plugins/%s", slug)
 
-			dockerCmd := exec.Command("docker", "exec", containerName, "rm", "-rf", containerPath)
-			dockerCmd.Run()
-
-			dockerCmd = exec.Command("docker", "cp", stageDir+"/.", containerName+":"+containerPath)
-			if err := dockerCmd.Run(); err != nil {
+			if err := replaceInContainer(containerName, stageDir, containerPath); err != nil {
 				ui.PrintError("Failed to deploy: %v", err)
-				os.Exit(1)
+				exit(1)
 			}
 
-			// Activate plugin
+			// Activate plugin, network-wide for network-only plugins on multisite
+			activateArgs := []string{"wp", "plugin", "activate", slug}
+			if b.Config.Network {
+				if isMultisite(instanceSlug) {
+					activateArgs = append(activateArgs, "--network")
+				} else {
+					ui.PrintWarning("'network: true' only applies to multisite; [%s] is a single site, so '%s' is activated normally", instanceSlug, slug)
+				}
+			}
 			activateCmd := exec.Command("docker", "run", "--rm",
 				"--network", networkName,
 				"--user", "33:33",
 				"-v", instanceSlug+"-wp:/var/www/html",
 				"-e", "WORDPRESS_DB_HOST="+instanceSlug+"-mysql",
-				"-e", "WORDPRESS_DB_USER=wordpress",
-				"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-				"-e", "WORDPRESS_DB_NAME=wordpress",
+				"-e", "WORDPRESS_DB_USER="+credentials.DBUser,
+				"-e", "WORDPRESS_DB_PASSWORD="+credentials.DBPassword,
+				"-e", "WORDPRESS_DB_NAME="+credentials.DBName,
 				"wordpress:cli",
-				"wp", "plugin", "activate", slug,
 			)
-			activateCmd.Run()
+			activateCmd.Args = append(activateCmd.Args, activateArgs...)
+			if output, err := activateCmd.CombinedOutput(); err != nil {
+				ui.PrintWarning("Failed to activate plugin '%s': %v", slug, commandError(err, output))
+			}
 
 			// Deploy plugin settings
-			if len(cfg.Settings) > 0 {
+			if len(settings) > 0 {
 				if !quiet {
-					ui.PrintInfo("Deploying settings...")
+					if profile != "" {
+						ui.PrintInfo("Deploying settings (profile: %s)...", profile)
+					} else {
+						ui.PrintInfo("Deploying settings...")
+					}
 				}
-				if err := deployPluginSettings(cfg.Settings, networkName, instanceSlug, quiet); err != nil {
+				if err := deployPluginSettings(settings, networkName, instanceSlug, quiet); err != nil {
 					ui.PrintError("Failed to deploy settings: %v", err)
-					os.Exit(1)
+					exit(1)
 				}
 			}
 		}
 
+		ui.SetPhase(builder.PhaseComplete)
 		if quiet {
 			ui.PrintSuccess("Deployed to WordPress!")
 		} else {
@@ -296,8 +362,312 @@ var deployCmd = &cobra.Command{
 	},
 }
 
+// deploySite builds every plugin and theme in the site project in dir and deploys
+// them into the site's environment, starting it if needed. Plugins and themes are
+// activated according to their Active flags. Returns how many were deployed.
+func deploySite(dir string, stageOnly, quiet bool) (int, error) {
+	siteConfig, err := config.LoadSiteProperties(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load site.properties: %w", err)
+	}
+	if len(siteConfig.LocalPlugins) == 0 && len(siteConfig.LocalThemes) == 0 {
+		return 0, fmt.Errorf("no plugins/ or themes/ to deploy in %s", dir)
+	}
+
+	instanceSlug := sanitizeForDocker(siteConfig.Name)
+	containerName := instanceSlug + "-wordpress"
+	if err := ensureWordPressRunning(dir, "", containerName, quiet); err != nil {
+		return 0, fmt.Errorf("failed to start WordPress: %w", err)
+	}
+
+	ui.SetPhase("deploy")
+	var failed []string
+	for _, plugin := range siteConfig.LocalPlugins {
+		if !quiet {
+			ui.PrintInfo("Deploying plugin '%s'...", plugin.Slug)
+		}
+		if err := deploySitePlugin(plugin, containerName, instanceSlug, stageOnly, quiet); err != nil {
+			ui.PrintWarning("  Failed to deploy plugin '%s': %v", plugin.Slug, err)
+			failed = append(failed, plugin.Slug)
+		}
+	}
+	for _, theme := range siteConfig.LocalThemes {
+		if !quiet {
+			ui.PrintInfo("Deploying theme '%s'...", theme.Slug)
+		}
+		if err := deploySiteTheme(theme, containerName, instanceSlug, stageOnly, quiet); err != nil {
+			ui.PrintWarning("  Failed to deploy theme '%s': %v", theme.Slug, err)
+			failed = append(failed, theme.Slug)
+		}
+	}
+
+	if len(failed) > 0 {
+		return 0, fmt.Errorf("failed to deploy %s", strings.Join(failed, ", "))
+	}
+	return len(siteConfig.LocalPlugins) + len(siteConfig.LocalThemes), nil
+}
+
+// deploySitePlugin builds (or installs from its zip) one of a site's plugins and
+// deploys it into the environment, activating it if it's active
+func deploySitePlugin(plugin config.LocalPlugin, containerName, instanceSlug string, stageOnly, quiet bool) error {
+	slug := plugin.Slug
+	network := false
+
+	if plugin.IsZip {
+		if err := installZipInContainer(containerName, instanceSlug, "plugin", plugin.Path); err != nil {
+			return err
+		}
+		if root, err := config.ZipRootDir(plugin.Path); err == nil && root != "" {
+			slug = root
+		}
+	} else {
+		b := builder.New(plugin.Path)
+		b.Quiet = true
+		b.StageOnly = stageOnly
+		if err := b.Build(); err != nil {
+			return fmt.Errorf("build failed: %w", err)
+		}
+		slug = sanitizeForDocker(b.Config.Name)
+		network = b.Config.Network
+
+		if dependencies := b.GetPluginDependencies(); len(dependencies) > 0 {
+			if err := deployPluginDependencies(dependencies, containerName, instanceSlug+"-network", instanceSlug, quiet); err != nil {
+				return fmt.Errorf("failed to deploy plugin dependencies: %w", err)
+			}
+		}
+
+		if err := replaceInContainer(containerName, b.GetStagePath(), "/var/www/html/wp-content/plugins/"+slug); err != nil {
+			return err
+		}
+	}
+
+	if !plugin.Active {
+		return nil
+	}
+	activateArgs := []string{"plugin", "activate", slug}
+	if network && isMultisite(instanceSlug) {
+		activateArgs = append(activateArgs, "--network")
+	}
+	if output, err := wpCLICommand(instanceSlug, activateArgs...).CombinedOutput(); err != nil {
+		ui.PrintWarning("  Failed to activate plugin '%s': %v", slug, commandError(err, output))
+	}
+	return nil
+}
+
+// deploySiteTheme builds (or installs from its zip) one of a site's themes, with
+// its parent themes, and deploys it into the environment, activating it if it's active
+func deploySiteTheme(theme config.LocalTheme, containerName, instanceSlug string, stageOnly, quiet bool) error {
+	slug := theme.Slug
+
+	if theme.IsZip {
+		if err := installZipInContainer(containerName, instanceSlug, "theme", theme.Path); err != nil {
+			return err
+		}
+		if root, err := config.ZipRootDir(theme.Path); err == nil && root != "" {
+			slug = root
+		}
+	} else {
+		b := builder.NewThemeBuilder(theme.Path)
+		b.Quiet = true
+		b.StageOnly = stageOnly
+		if err := b.Build(); err != nil {
+			return fmt.Errorf("build failed: %w", err)
+		}
+		slug = sanitizeForDocker(b.Config.Name)
+
+		for _, parent := range b.GetAllParentThemes() {
+			if !quiet {
+				ui.PrintInfo("  Deploying parent theme '%s'...", parent.Name)
+			}
+			parentPath := "/var/www/html/wp-content/themes/" + sanitizeForDocker(parent.Name)
+			if err := replaceInContainer(containerName, parent.Path, parentPath); err != nil {
+				return fmt.Errorf("failed to deploy parent theme '%s': %w", parent.Name, err)
+			}
+		}
+
+		if err := replaceInContainer(containerName, b.GetStagePath(), "/var/www/html/wp-content/themes/"+slug); err != nil {
+			return err
+		}
+	}
+
+	if !theme.Active {
+		return nil
+	}
+	if output, err := wpCLICommand(instanceSlug, "theme", "activate", slug).CombinedOutput(); err != nil {
+		ui.PrintWarning("  Failed to activate theme '%s': %v", slug, commandError(err, output))
+	}
+	return nil
+}
+
+// installZipInContainer installs a plugin or theme zip into an environment with
+// WP-CLI, replacing any installed version
+func installZipInContainer(containerName, instanceSlug, kind, zipPath string) error {
+	containerPath, err := copyIntoEnvironment(containerName, zipPath)
+	if err != nil {
+		return err
+	}
+
+	output, err := wpCLICommand(instanceSlug, kind, "install", containerPath, "--force").CombinedOutput()
+	removeFromEnvironment(containerName, containerPath)
+	if err != nil {
+		return fmt.Errorf("failed to install %s: %w", filepath.Base(zipPath), commandError(err, output))
+	}
+	return nil
+}
+
+// ensureWordPressRunning starts the environment with wordpress start (using
+// propsFile, if set) unless its WordPress container is already running
+func ensureWordPressRunning(dir, propsFile, containerName string, quiet bool) error {
+	if isContainerRunning(containerName) {
+		return nil
+	}
+
+	ui.SetPhase("start")
+	if !quiet {
+		ui.PrintInfo("WordPress is not running, starting it...")
+		fmt.Println()
+	}
+	var startArgs []string
+	startArgs = append(startArgs, "wordpress", "start")
+	if propsFile != "" {
+		startArgs = append(startArgs, propsFile)
+	}
+	startArgs = append(startArgs, "--quiet")
+	startCmd := exec.Command(os.Args[0], startArgs...)
+	startCmd.Stdout = os.Stdout
+	if ui.JSONEvents() {
+		startCmd.Args = append(startCmd.Args, "--json-events")
+		startCmd.Stdout = ui.EventWriter()
+	}
+	startCmd.Stderr = os.Stderr
+	startCmd.Dir = dir
+	if err := startCmd.Run(); err != nil {
+		return err
+	}
+	fmt.Println()
+	return nil
+}
+
+// replaceInContainer replaces containerPath in a WordPress container with the contents of src
+func replaceInContainer(containerName, src, containerPath string) error {
+	if output, err := exec.Command("docker", "exec", containerName, "rm", "-rf", containerPath).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", containerPath, commandError(err, output))
+	}
+	if output, err := exec.Command("docker", "cp", src+"/.", containerName+":"+containerPath).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to copy %s to %s: %w", src, containerPath, commandError(err, output))
+	}
+	return nil
+}
+
+// deployToPath builds the project and copies its stage directory into a local wp-content
+// directory (Local, Valet, XAMPP, ...). With stageOnly the zip isn't created. If the wp CLI is installed, the plugin or theme is activated.
+func deployToPath(dir, targetPath string, isTheme, stageOnly, quiet bool) error {
+	wpContent, err := filepath.Abs(targetPath)
+	if err != nil {
+		return fmt.Errorf("invalid target path: %w", err)
+	}
+	if !isDir(filepath.Join(wpContent, "plugins")) || !isDir(filepath.Join(wpContent, "themes")) {
+		return fmt.Errorf("%s does not look like a wp-content directory (missing plugins/ or themes/)", targetPath)
+	}
+	wpRoot := filepath.Dir(wpContent)
+
+	kind := "plugin"
+	var slug string
+	if isTheme {
+		kind = "theme"
+		b := builder.NewThemeBuilder(dir)
+		b.Quiet = quiet
+		b.StageOnly = stageOnly
+		if err := b.Build(); err != nil {
+			return fmt.Errorf("build failed: %w", err)
+		}
+		slug = sanitizeForDocker(b.Config.Name)
+
+		if !quiet {
+			fmt.Println()
+			ui.PrintInfo("Deploying theme to %s...", wpContent)
+		}
+
+		// Deploy all parent themes first (grandparent, then parent, etc.)
+		for _, parent := range b.GetAllParentThemes() {
+			if !quiet {
+				ui.PrintInfo("Deploying parent theme '%s'...", parent.Name)
+			}
+			if err := replaceDir(parent.Path, filepath.Join(wpContent, "themes", sanitizeForDocker(parent.Name))); err != nil {
+				return fmt.Errorf("failed to deploy parent theme '%s': %w", parent.Name, err)
+			}
+		}
+
+		if err := replaceDir(b.GetStagePath(), filepath.Join(wpContent, "themes", slug)); err != nil {
+			return fmt.Errorf("failed to deploy: %w", err)
+		}
+	} else {
+		b := builder.New(dir)
+		b.Quiet = quiet
+		b.StageOnly = stageOnly
+		if err := b.Build(); err != nil {
+			return fmt.Errorf("build failed: %w", err)
+		}
+		slug = sanitizeForDocker(b.Config.Name)
+
+		if !quiet {
+			fmt.Println()
+			ui.PrintInfo("Deploying plugin to %s...", wpContent)
+		}
+
+		for _, dep := range b.GetPluginDependencies() {
+			if dep.Path == "" {
+				ui.PrintWarning("  Dependency '%s' is from WordPress.org; install it with: wp plugin install %s", dep.Slug, dep.Slug)
+				continue
+			}
+			if !quiet {
+				ui.PrintInfo("  Deploying dependency '%s'...", dep.Slug)
+			}
+			if err := replaceDir(dep.Path, filepath.Join(wpContent, "plugins", dep.Slug)); err != nil {
+				return fmt.Errorf("failed to deploy plugin '%s': %w", dep.Slug, err)
+			}
+		}
+
+		if err := replaceDir(b.GetStagePath(), filepath.Join(wpContent, "plugins", slug)); err != nil {
+			return fmt.Errorf("failed to deploy: %w", err)
+		}
+	}
+
+	// Activate with a local wp CLI if there is one
+	if !isCommandAvailable("wp") {
+		if !quiet {
+			ui.PrintInfo("wp CLI not found; activate '%s' in wp-admin", slug)
+		}
+		return nil
+	}
+	output, err := exec.Command("wp", kind, "activate", slug, "--path="+wpRoot).CombinedOutput()
+	if err != nil {
+		ui.PrintWarning("Failed to activate %s '%s': %v", kind, slug, commandError(err, output))
+	}
+	return nil
+}
+
+// replaceDir replaces dst with a copy of src
+func replaceDir(src, dst string) error {
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	return builder.CopyDir(src, dst)
+}
+
+// isDir checks if path is an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
 func init() {
 	deployCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	deployCmd.Flags().String("target-path", "", "Deploy to a local wp-content directory instead of Docker")
+	deployCmd.Flags().Bool("stage-only", false, "Deploy from build/work/stage without creating the zip")
+	deployCmd.Flags().String("profile", "", "Deploy profile whose settings are merged over the base settings (defaults to $WORDSMITH_PROFILE)")
+	deployCmd.Flags().Bool("only-deps", false, "Install and activate plugin dependencies without deploying the plugin itself")
+	deployCmd.Flags().Bool("all", false, "Build and deploy every plugin and theme in a site project's plugins/ and themes/")
 	rootCmd.AddCommand(deployCmd)
 }
 
@@ -316,6 +686,7 @@ func sanitizeForDocker(name string) string {
 // deployPluginDependencies deploys all plugin dependencies before the main plugin
 func deployPluginDependencies(deps []builder.PluginDependency, containerName, networkName, instanceSlug string, quiet bool) error {
 	mysqlContainer := instanceSlug + "-mysql"
+	credentials := instanceCredentials(instanceSlug)
 
 	for _, dep := range deps {
 		if dep.IsWPOrg {
@@ -329,9 +700,9 @@ func deployPluginDependencies(deps []builder.PluginDependency, containerName, ne
 				"--user", "33:33",
 				"-v", instanceSlug + "-wp:/var/www/html",
 				"-e", "WORDPRESS_DB_HOST=" + mysqlContainer,
-				"-e", "WORDPRESS_DB_USER=wordpress",
-				"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-				"-e", "WORDPRESS_DB_NAME=wordpress",
+				"-e", "WORDPRESS_DB_USER=" + credentials.DBUser,
+				"-e", "WORDPRESS_DB_PASSWORD=" + credentials.DBPassword,
+				"-e", "WORDPRESS_DB_NAME=" + credentials.DBName,
 				"wordpress:cli",
 				"wp", "plugin", "install", dep.Slug, "--activate",
 			}
@@ -341,8 +712,8 @@ func deployPluginDependencies(deps []builder.PluginDependency, containerName, ne
 			}
 
 			installCmd := exec.Command("docker", installArgs...)
-			if err := installCmd.Run(); err != nil {
-				return fmt.Errorf("failed to install plugin '%s': %w", dep.Slug, err)
+			if output, err := installCmd.CombinedOutput(); err != nil {
+				return fmt.Errorf("failed to install plugin '%s': %w", dep.Slug, commandError(err, output))
 			}
 		} else if dep.Path != "" {
 			// Deploy built/resolved plugin via docker cp
@@ -368,13 +739,15 @@ func deployPluginDependencies(deps []builder.PluginDependency, containerName, ne
 				"--user", "33:33",
 				"-v", instanceSlug+"-wp:/var/www/html",
 				"-e", "WORDPRESS_DB_HOST="+mysqlContainer,
-				"-e", "WORDPRESS_DB_USER=wordpress",
-				"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-				"-e", "WORDPRESS_DB_NAME=wordpress",
+				"-e", "WORDPRESS_DB_USER="+credentials.DBUser,
+				"-e", "WORDPRESS_DB_PASSWORD="+credentials.DBPassword,
+				"-e", "WORDPRESS_DB_NAME="+credentials.DBName,
 				"wordpress:cli",
 				"wp", "plugin", "activate", dep.Slug,
 			)
-			activateCmd.Run()
+			if output, err := activateCmd.CombinedOutput(); err != nil {
+				ui.PrintWarning("  Failed to activate dependency '%s': %v", dep.Slug, commandError(err, output))
+			}
 		}
 	}
 
@@ -388,6 +761,7 @@ func deployPluginSettings(settings map[string]interface{}, networkName, instance
 	}
 
 	mysqlContainer := instanceSlug + "-mysql"
+	credentials := instanceCredentials(instanceSlug)
 
 	for optionName, value := range settings {
 		if !quiet {
@@ -404,9 +778,9 @@ func deployPluginSettings(settings map[string]interface{}, networkName, instance
 				"--user", "33:33",
 				"-v", instanceSlug + "-wp:/var/www/html",
 				"-e", "WORDPRESS_DB_HOST=" + mysqlContainer,
-				"-e", "WORDPRESS_DB_USER=wordpress",
-				"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-				"-e", "WORDPRESS_DB_NAME=wordpress",
+				"-e", "WORDPRESS_DB_USER=" + credentials.DBUser,
+				"-e", "WORDPRESS_DB_PASSWORD=" + credentials.DBPassword,
+				"-e", "WORDPRESS_DB_NAME=" + credentials.DBName,
 				"wordpress:cli",
 				"wp", "option", "update", optionName, v,
 			}
@@ -422,9 +796,9 @@ func deployPluginSettings(settings map[string]interface{}, networkName, instance
 				"--user", "33:33",
 				"-v", instanceSlug + "-wp:/var/www/html",
 				"-e", "WORDPRESS_DB_HOST=" + mysqlContainer,
-				"-e", "WORDPRESS_DB_USER=wordpress",
-				"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-				"-e", "WORDPRESS_DB_NAME=wordpress",
+				"-e", "WORDPRESS_DB_USER=" + credentials.DBUser,
+				"-e", "WORDPRESS_DB_PASSWORD=" + credentials.DBPassword,
+				"-e", "WORDPRESS_DB_NAME=" + credentials.DBName,
 				"wordpress:cli",
 				"wp", "option", "update", optionName, string(jsonBytes), "--format=json",
 			}
@@ -435,9 +809,9 @@ func deployPluginSettings(settings map[string]interface{}, networkName, instance
 				"--user", "33:33",
 				"-v", instanceSlug + "-wp:/var/www/html",
 				"-e", "WORDPRESS_DB_HOST=" + mysqlContainer,
-				"-e", "WORDPRESS_DB_USER=wordpress",
-				"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-				"-e", "WORDPRESS_DB_NAME=wordpress",
+				"-e", "WORDPRESS_DB_USER=" + credentials.DBUser,
+				"-e", "WORDPRESS_DB_PASSWORD=" + credentials.DBPassword,
+				"-e", "WORDPRESS_DB_NAME=" + credentials.DBName,
 				"wordpress:cli",
 				"wp", "option", "update", optionName, fmt.Sprintf("%v", value),
 			}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -19,46 +20,70 @@ var (
 	initAuthor      string
 	initAuthorURI   string
 	initThemeType   string
+	initBlock       bool
 	initTemplate    string
 	initTemplateURI string
 	initGit         bool
 	initClaude      bool
+	initFrom        string
+	initOverwrite   bool
+	initFailOnExist bool
 )
 
+// initExitExisting is init's exit code when the project it would create already
+// exists, so scripts can tell it apart from other failures (exit code 1)
+const initExitExisting = 2
+
 var initCmd = &cobra.Command{
-	Use:   "init [plugin|theme|library]",
-	Short: "Initialize a new WordPress plugin, theme, or library",
-	Long:  "Create a new plugin, theme, or library with all necessary files and directories",
+	Use:       "init [plugin|block-plugin|theme|library|site]",
+	Short:     "Initialize a new WordPress plugin, theme, library, or site",
+	Long:      "Create a new plugin, block plugin, theme, library, or site with all necessary files and directories",
+	ValidArgs: []string{"plugin", "block-plugin", "theme", "library", "site"},
 	Run: func(cmd *cobra.Command, args []string) {
 		ui.PrintHeader(Version)
 
 		dir, err := os.Getwd()
 		if err != nil {
 			ui.PrintError("Failed to get current directory: %v", err)
-			os.Exit(1)
+			exit(1)
 		}
 
 		// Determine type from args (default to plugin)
 		buildType := "plugin"
 		if len(args) > 0 {
 			switch args[0] {
-			case "plugin", "theme", "library":
+			case "plugin", "theme", "library", "site":
 				buildType = args[0]
+			case "block-plugin":
+				buildType = "plugin"
+				initBlock = true
 			default:
-				ui.PrintError("Invalid type: %s (use 'plugin', 'theme', or 'library')", args[0])
-				os.Exit(1)
+				ui.PrintError("Invalid type: %s (use 'plugin', 'block-plugin', 'theme', 'library', or 'site')", args[0])
+				exit(1)
 			}
 		}
 
+		if initOverwrite && cmd.Flags().Changed("fail-on-existing") && initFailOnExist {
+			ui.PrintError("Use either --fail-on-existing or --overwrite, not both")
+			exit(1)
+		}
+		if !initFailOnExist {
+			initOverwrite = true
+		}
+
 		// Check if any flags were provided (non-interactive mode)
 		interactive := initName == "" && initDescription == "" && initAuthor == "" && initAuthorURI == "" && initThemeType == ""
 
 		var projectDir string
-		switch buildType {
-		case "theme":
+		switch {
+		case initFrom != "":
+			projectDir = importProject(dir, initFrom, buildType, len(args) > 0)
+		case buildType == "theme":
 			projectDir = initTheme(dir, interactive)
-		case "library":
+		case buildType == "library":
 			projectDir = initLibrary(dir, interactive)
+		case buildType == "site":
+			projectDir = initSite(dir, interactive)
 		default:
 			projectDir = initPlugin(dir, interactive)
 		}
@@ -83,15 +108,34 @@ var initCmd = &cobra.Command{
 }
 
 func init() {
-	initCmd.Flags().StringVar(&initName, "name", "", "Plugin/theme name")
-	initCmd.Flags().StringVar(&initDescription, "description", "", "Plugin/theme description")
+	initCmd.Flags().StringVar(&initName, "name", "", "Plugin/theme/site name")
+	initCmd.Flags().StringVar(&initDescription, "description", "", "Plugin/theme/site description")
 	initCmd.Flags().StringVar(&initAuthor, "author", "", "Author name")
 	initCmd.Flags().StringVar(&initAuthorURI, "author-uri", "", "Author website URL")
 	initCmd.Flags().StringVar(&initThemeType, "type", "", "Theme type: block, classic, hybrid, or child")
+	initCmd.Flags().BoolVar(&initBlock, "block", false, "Create a block plugin with block.json (same as init block-plugin)")
 	initCmd.Flags().StringVar(&initTemplate, "template", "", "Parent theme name (for child themes)")
 	initCmd.Flags().StringVar(&initTemplateURI, "template-uri", "", "Parent theme URL or path (for child themes)")
 	initCmd.Flags().BoolVarP(&initGit, "git", "g", false, "Generate GitHub Actions build workflow")
 	initCmd.Flags().BoolVarP(&initClaude, "claude", "c", false, "Generate Claude Code support files")
+	initCmd.Flags().StringVar(&initFrom, "from", "", "Import an existing plugin or theme (directory or zip) by reading its header")
+	initCmd.Flags().BoolVar(&initFailOnExist, "fail-on-existing", true, "Exit with code 2 if the project already exists (the default)")
+	initCmd.Flags().BoolVar(&initOverwrite, "overwrite", false, "Overwrite an existing project's properties and generated files")
+}
+
+// checkExisting stops init with exit code 2 if path, the properties file or
+// directory it would create, already exists, unless --overwrite is set
+func checkExisting(path string) {
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	if initOverwrite {
+		ui.PrintWarning("Overwriting %s", path)
+		return
+	}
+	ui.PrintError("%s already exists", path)
+	ui.PrintInfo("Use --overwrite to replace it")
+	exit(initExitExisting)
 }
 
 func initPlugin(dir string, interactive bool) string {
@@ -113,6 +157,9 @@ func initPlugin(dir string, interactive bool) string {
 		if author != "" {
 			authorURI = prompt(reader, "Author website", "")
 		}
+		if !initBlock {
+			initBlock = strings.ToLower(prompt(reader, "Plugin type (classic or block)", "classic")) == "block"
+		}
 
 		fmt.Println()
 	} else {
@@ -129,22 +176,18 @@ func initPlugin(dir string, interactive bool) string {
 		authorURI = initAuthorURI
 	}
 
-	// If current directory is not empty, create subdirectory
-	if !isEmptyDir(dir) {
+	// If current directory is not empty (and isn't already a plugin), create subdirectory
+	if !isEmptyDir(dir) && !config.PluginExists(dir) {
 		slug := sanitizeName(name)
 		newDir := filepath.Join(dir, slug)
 		if err := os.MkdirAll(newDir, 0755); err != nil {
 			ui.PrintError("Failed to create directory %s: %v", slug, err)
-			os.Exit(1)
+			exit(1)
 		}
 		dir = newDir
 	}
 
-	// Check if plugin.properties already exists
-	if config.PluginExists(dir) {
-		ui.PrintWarning("plugin.properties already exists")
-		os.Exit(1)
-	}
+	checkExisting(filepath.Join(dir, "plugin.properties"))
 
 	// Generate slug from name
 	slug := sanitizeName(name)
@@ -170,11 +213,20 @@ func initPlugin(dir string, interactive bool) string {
 	props = append(props, fmt.Sprintf("main=%s", mainFile))
 	props = append(props, "")
 	props = append(props, "# WordPress requirements")
-	props = append(props, "requires=5.0")
+	if initBlock {
+		// block.json apiVersion 3 needs WordPress 6.3
+		props = append(props, "requires=6.3")
+	} else {
+		props = append(props, "requires=5.0")
+	}
 	props = append(props, "requires-php=7.4")
 	props = append(props, "")
 	props = append(props, "# Files to include (supports wildcards)")
-	props = append(props, "include=includes,assets,languages")
+	if initBlock {
+		props = append(props, "include=includes,assets,languages,src,block.json")
+	} else {
+		props = append(props, "include=includes,assets,languages")
+	}
 	props = append(props, "")
 	props = append(props, "# Files to exclude")
 	props = append(props, "exclude=node_modules,tests,.*")
@@ -188,15 +240,18 @@ func initPlugin(dir string, interactive bool) string {
 	propsPath := filepath.Join(dir, "plugin.properties")
 	if err := os.WriteFile(propsPath, []byte(propsContent), 0644); err != nil {
 		ui.PrintError("Failed to create plugin.properties: %v", err)
-		os.Exit(1)
+		exit(1)
 	}
 
 	// Create main plugin file
 	mainContent := generateMainPluginFile(name, description, author, authorURI, slug)
+	if initBlock {
+		mainContent += generateBlockRegistration(slug)
+	}
 	mainPath := filepath.Join(dir, mainFile)
 	if err := os.WriteFile(mainPath, []byte(mainContent), 0644); err != nil {
 		ui.PrintError("Failed to create %s: %v", mainFile, err)
-		os.Exit(1)
+		exit(1)
 	}
 
 	// Create directories
@@ -218,6 +273,11 @@ func initPlugin(dir string, interactive bool) string {
 	jsPath := filepath.Join(dir, "assets", "js", slug+".js")
 	os.WriteFile(jsPath, []byte(jsContent), 0644)
 
+	// Create block.json and the block's editor script
+	if initBlock {
+		generateBlockFiles(dir, name, description, slug)
+	}
+
 	// Create readme.txt
 	readmeContent := generateReadme(name, description, author, slug)
 	readmePath := filepath.Join(dir, "readme.txt")
@@ -229,11 +289,20 @@ func initPlugin(dir string, interactive bool) string {
 	os.WriteFile(gitignorePath, []byte(gitignoreContent), 0644)
 
 	// Print success
-	ui.PrintSuccess("Created plugin: %s", name)
+	if initBlock {
+		ui.PrintSuccess("Created block plugin: %s", name)
+	} else {
+		ui.PrintSuccess("Created plugin: %s", name)
+	}
 	fmt.Println()
 	ui.PrintInfo("Files created:")
 	fmt.Printf("  • plugin.properties\n")
 	fmt.Printf("  • %s\n", mainFile)
+	if initBlock {
+		fmt.Printf("  • block.json\n")
+		fmt.Printf("  • src/index.js\n")
+		fmt.Printf("  • src/index.asset.php\n")
+	}
 	fmt.Printf("  • readme.txt\n")
 	fmt.Printf("  • includes/\n")
 	fmt.Printf("  • assets/css/%s.css\n", slug)
@@ -293,12 +362,12 @@ func initTheme(dir string, interactive bool) string {
 			template = prompt(reader, "Parent theme name", "")
 			if template == "" {
 				ui.PrintError("Parent theme name is required for child themes")
-				os.Exit(1)
+				exit(1)
 			}
 			templateURI = prompt(reader, "Parent theme URL or path", "")
 			if templateURI == "" {
 				ui.PrintError("Parent theme URL or path is required for child themes")
-				os.Exit(1)
+				exit(1)
 			}
 		}
 
@@ -330,31 +399,27 @@ func initTheme(dir string, interactive bool) string {
 		if themeType == "child" {
 			if template == "" {
 				ui.PrintError("--template is required for child themes")
-				os.Exit(1)
+				exit(1)
 			}
 			if templateURI == "" {
 				ui.PrintError("--template-uri is required for child themes")
-				os.Exit(1)
+				exit(1)
 			}
 		}
 	}
 
-	// If current directory is not empty, create subdirectory
-	if !isEmptyDir(dir) {
+	// If current directory is not empty (and isn't already a theme), create subdirectory
+	if !isEmptyDir(dir) && !config.ThemeExists(dir) {
 		slug := sanitizeName(name)
 		newDir := filepath.Join(dir, slug)
 		if err := os.MkdirAll(newDir, 0755); err != nil {
 			ui.PrintError("Failed to create directory %s: %v", slug, err)
-			os.Exit(1)
+			exit(1)
 		}
 		dir = newDir
 	}
 
-	// Check if theme.properties already exists
-	if config.ThemeExists(dir) {
-		ui.PrintWarning("theme.properties already exists")
-		os.Exit(1)
-	}
+	checkExisting(filepath.Join(dir, "theme.properties"))
 
 	// Generate slug from name
 	slug := sanitizeName(name)
@@ -386,7 +451,7 @@ func initTheme(dir string, interactive bool) string {
 
 	switch themeType {
 	case "block":
-		props = append(props, "include=*.php,theme.json,templates,parts,assets,languages")
+		props = append(props, "include=*.php,theme.json,templates,parts,patterns,styles,assets,languages")
 	case "classic":
 		props = append(props, "include=*.php,assets,languages,screenshot.png")
 	case "child":
@@ -415,7 +480,7 @@ func initTheme(dir string, interactive bool) string {
 	propsPath := filepath.Join(dir, "theme.properties")
 	if err := os.WriteFile(propsPath, []byte(propsContent), 0644); err != nil {
 		ui.PrintError("Failed to create theme.properties: %v", err)
-		os.Exit(1)
+		exit(1)
 	}
 
 	// Generate theme files based on type
@@ -441,7 +506,7 @@ func initTheme(dir string, interactive bool) string {
 
 func generateBlockTheme(dir, name, description, author, authorURI, slug string) {
 	// Create directories
-	dirs := []string{"templates", "parts", "assets", "assets/css", "assets/js", "languages"}
+	dirs := []string{"templates", "parts", "patterns", "styles", "assets", "assets/css", "assets/js", "languages"}
 	for _, d := range dirs {
 		os.MkdirAll(filepath.Join(dir, d), 0755)
 	}
@@ -513,6 +578,64 @@ func generateBlockTheme(dir, name, description, author, authorURI, slug string)
 `, name)
 	os.WriteFile(filepath.Join(dir, "parts", "footer.html"), []byte(footerHTML), 0644)
 
+	// patterns/hero.php (WordPress registers patterns from their Title and Slug headers)
+	heroPHP := fmt.Sprintf(`<?php
+/**
+ * Title: Hero
+ * Slug: %s/hero
+ * Categories: featured
+ * Description: A large heading with a short introduction.
+ */
+?>
+<!-- wp:group {"align":"full","layout":{"type":"constrained"}} -->
+<div class="wp-block-group alignfull">
+    <!-- wp:heading {"textAlign":"center","level":1} -->
+    <h1 class="wp-block-heading has-text-align-center">%s</h1>
+    <!-- /wp:heading -->
+
+    <!-- wp:paragraph {"align":"center"} -->
+    <p class="has-text-align-center">%s</p>
+    <!-- /wp:paragraph -->
+</div>
+<!-- /wp:group -->
+`, slug, name, description)
+	os.WriteFile(filepath.Join(dir, "patterns", "hero.php"), []byte(heroPHP), 0644)
+
+	// styles/dark.json (a style variation, listed in Appearance → Editor → Styles)
+	darkJSON := `{
+	"$schema": "https://schemas.wp.org/trunk/theme.json",
+	"version": 2,
+	"title": "Dark",
+	"settings": {
+		"color": {
+			"palette": [
+				{
+					"slug": "primary",
+					"color": "#4fb3e8",
+					"name": "Primary"
+				},
+				{
+					"slug": "secondary",
+					"color": "#e0e0e0",
+					"name": "Secondary"
+				},
+				{
+					"slug": "background",
+					"color": "#1e1e1e",
+					"name": "Background"
+				},
+				{
+					"slug": "foreground",
+					"color": "#f0f0f0",
+					"name": "Foreground"
+				}
+			]
+		}
+	}
+}
+`
+	os.WriteFile(filepath.Join(dir, "styles", "dark.json"), []byte(darkJSON), 0644)
+
 	ui.PrintInfo("Files created:")
 	fmt.Printf("  • theme.properties\n")
 	fmt.Printf("  • style.css\n")
@@ -521,6 +644,8 @@ func generateBlockTheme(dir, name, description, author, authorURI, slug string)
 	fmt.Printf("  • templates/index.html\n")
 	fmt.Printf("  • parts/header.html\n")
 	fmt.Printf("  • parts/footer.html\n")
+	fmt.Printf("  • patterns/hero.php\n")
+	fmt.Printf("  • styles/dark.json\n")
 	fmt.Printf("  • assets/\n")
 	fmt.Printf("  • languages/\n")
 }
@@ -698,8 +823,14 @@ $version_file = get_stylesheet_directory() . '/version.properties';
 $version = '1.0.0';
 if (file_exists($version_file)) {
     $props = parse_ini_file($version_file);
-    if ($props && isset($props['major'], $props['minor'], $props['maintenance'])) {
-        $version = $props['major'] . '.' . $props['minor'] . '.' . $props['maintenance'];
+    if ($props && isset($props['major'], $props['minor'], $props['patch'])) {
+        $version = $props['major'] . '.' . $props['minor'] . '.' . $props['patch'];
+        if (($props['prerelease'] ?? '') !== '') {
+            $version .= '-' . $props['prerelease'];
+        }
+        if (($props['build'] ?? '') !== '') {
+            $version .= '+' . $props['build'];
+        }
     }
 }
 define('%s_VERSION', $version);
@@ -859,8 +990,14 @@ $version_file = get_template_directory() . '/version.properties';
 $version = '1.0.0';
 if (file_exists($version_file)) {
     $props = parse_ini_file($version_file);
-    if ($props && isset($props['major'], $props['minor'], $props['maintenance'])) {
-        $version = $props['major'] . '.' . $props['minor'] . '.' . $props['maintenance'];
+    if ($props && isset($props['major'], $props['minor'], $props['patch'])) {
+        $version = $props['major'] . '.' . $props['minor'] . '.' . $props['patch'];
+        if (($props['prerelease'] ?? '') !== '') {
+            $version .= '-' . $props['prerelease'];
+        }
+        if (($props['build'] ?? '') !== '') {
+            $version .= '+' . $props['build'];
+        }
     }
 }
 define('%s_VERSION', $version);
@@ -912,8 +1049,14 @@ $version_file = get_template_directory() . '/version.properties';
 $version = '1.0.0';
 if (file_exists($version_file)) {
     $props = parse_ini_file($version_file);
-    if ($props && isset($props['major'], $props['minor'], $props['maintenance'])) {
-        $version = $props['major'] . '.' . $props['minor'] . '.' . $props['maintenance'];
+    if ($props && isset($props['major'], $props['minor'], $props['patch'])) {
+        $version = $props['major'] . '.' . $props['minor'] . '.' . $props['patch'];
+        if (($props['prerelease'] ?? '') !== '') {
+            $version .= '-' . $props['prerelease'];
+        }
+        if (($props['build'] ?? '') !== '') {
+            $version .= '+' . $props['build'];
+        }
     }
 }
 define('%s_VERSION', $version);
@@ -1024,8 +1167,14 @@ $version_file = get_template_directory() . '/version.properties';
 $version = '1.0.0';
 if (file_exists($version_file)) {
     $props = parse_ini_file($version_file);
-    if ($props && isset($props['major'], $props['minor'], $props['maintenance'])) {
-        $version = $props['major'] . '.' . $props['minor'] . '.' . $props['maintenance'];
+    if ($props && isset($props['major'], $props['minor'], $props['patch'])) {
+        $version = $props['major'] . '.' . $props['minor'] . '.' . $props['patch'];
+        if (($props['prerelease'] ?? '') !== '') {
+            $version .= '-' . $props['prerelease'];
+        }
+        if (($props['build'] ?? '') !== '') {
+            $version .= '+' . $props['build'];
+        }
     }
 }
 define('%s_VERSION', $version);
@@ -1355,8 +1504,14 @@ $version_file = %s_PATH . 'version.properties';
 $version = '1.0.0';
 if (file_exists($version_file)) {
     $props = parse_ini_file($version_file);
-    if ($props && isset($props['major'], $props['minor'], $props['maintenance'])) {
-        $version = $props['major'] . '.' . $props['minor'] . '.' . $props['maintenance'];
+    if ($props && isset($props['major'], $props['minor'], $props['patch'])) {
+        $version = $props['major'] . '.' . $props['minor'] . '.' . $props['patch'];
+        if (($props['prerelease'] ?? '') !== '') {
+            $version .= '-' . $props['prerelease'];
+        }
+        if (($props['build'] ?? '') !== '') {
+            $version .= '+' . $props['build'];
+        }
     }
 }
 define('%s_VERSION', $version);
@@ -1407,6 +1562,101 @@ add_action('wp_enqueue_scripts', '%s_enqueue_scripts');
 	return content
 }
 
+// generateBlockRegistration returns the code appended to a block plugin's main
+// file to register its block from block.json
+func generateBlockRegistration(slug string) string {
+	funcPrefix := strings.ReplaceAll(slug, "-", "_")
+
+	return fmt.Sprintf(`
+/**
+ * Register the block from block.json
+ */
+function %s_register_block() {
+    register_block_type(__DIR__ . '/block.json');
+}
+add_action('init', '%s_register_block');
+`, funcPrefix, funcPrefix)
+}
+
+// blockMetadata is the block.json written for a new block plugin, with the
+// fields in the order WordPress's examples use
+type blockMetadata struct {
+	Schema       string          `json:"$schema"`
+	APIVersion   int             `json:"apiVersion"`
+	Name         string          `json:"name"`
+	Version      string          `json:"version"`
+	Title        string          `json:"title"`
+	Category     string          `json:"category"`
+	Icon         string          `json:"icon"`
+	Description  string          `json:"description"`
+	Supports     map[string]bool `json:"supports"`
+	TextDomain   string          `json:"textdomain"`
+	EditorScript string          `json:"editorScript"`
+}
+
+// generateBlockFiles creates a block plugin's block.json, src/index.js, and
+// src/index.asset.php. The editor script uses the wp globals rather than JSX
+// and imports, so it runs without a JavaScript build step; index.asset.php
+// lists the script's dependencies, which WordPress reads when it registers
+// the block.
+func generateBlockFiles(dir, name, description, slug string) {
+	if err := os.MkdirAll(filepath.Join(dir, "src"), 0755); err != nil {
+		ui.PrintWarning("Failed to create directory src: %v", err)
+		return
+	}
+
+	blockJSON, err := json.MarshalIndent(blockMetadata{
+		Schema:       "https://schemas.wp.org/trunk/block.json",
+		APIVersion:   3,
+		Name:         slug + "/" + slug,
+		Version:      "0.1.0",
+		Title:        name,
+		Category:     "widgets",
+		Icon:         "smiley",
+		Description:  description,
+		Supports:     map[string]bool{"html": false},
+		TextDomain:   slug,
+		EditorScript: "file:./src/index.js",
+	}, "", "\t")
+	if err != nil {
+		ui.PrintWarning("Failed to create block.json: %v", err)
+		return
+	}
+	os.WriteFile(filepath.Join(dir, "block.json"), append(blockJSON, '\n'), 0644)
+
+	indexJS := fmt.Sprintf(`/**
+ * %s block
+ */
+
+(function(blocks, element, blockEditor) {
+    'use strict';
+
+    var el = element.createElement;
+    var useBlockProps = blockEditor.useBlockProps;
+
+    blocks.registerBlockType('%s/%s', {
+        edit: function() {
+            return el('p', useBlockProps(), '%s (editor)');
+        },
+        save: function() {
+            return el('p', useBlockProps.save(), '%s');
+        }
+    });
+
+})(window.wp.blocks, window.wp.element, window.wp.blockEditor);
+`, name, slug, slug, jsString(name), jsString(name))
+	os.WriteFile(filepath.Join(dir, "src", "index.js"), []byte(indexJS), 0644)
+
+	assetPHP := `<?php return array('dependencies' => array('wp-blocks', 'wp-element', 'wp-block-editor'), 'version' => '0.1.0');
+`
+	os.WriteFile(filepath.Join(dir, "src", "index.asset.php"), []byte(assetPHP), 0644)
+}
+
+// jsString escapes s for a single-quoted JavaScript string
+func jsString(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
+
 func initLibrary(dir string, interactive bool) string {
 	// Get default name from directory
 	defaultName := formatName(filepath.Base(dir))
@@ -1429,22 +1679,18 @@ func initLibrary(dir string, interactive bool) string {
 		}
 	}
 
-	// If current directory is not empty, create subdirectory
-	if !isEmptyDir(dir) {
+	// If current directory is not empty (and isn't already a library), create subdirectory
+	if !isEmptyDir(dir) && !config.LibraryExists(dir) {
 		slug := sanitizeName(name)
 		newDir := filepath.Join(dir, slug)
 		if err := os.MkdirAll(newDir, 0755); err != nil {
 			ui.PrintError("Failed to create directory %s: %v", slug, err)
-			os.Exit(1)
+			exit(1)
 		}
 		dir = newDir
 	}
 
-	// Check if library.properties already exists
-	if config.LibraryExists(dir) {
-		ui.PrintWarning("library.properties already exists")
-		os.Exit(1)
-	}
+	checkExisting(filepath.Join(dir, "library.properties"))
 
 	// Create library.properties
 	var props []string
@@ -1463,7 +1709,7 @@ func initLibrary(dir string, interactive bool) string {
 	propsPath := filepath.Join(dir, "library.properties")
 	if err := os.WriteFile(propsPath, []byte(propsContent), 0644); err != nil {
 		ui.PrintError("Failed to create library.properties: %v", err)
-		os.Exit(1)
+		exit(1)
 	}
 
 	// Create src directory
@@ -1489,6 +1735,70 @@ func initLibrary(dir string, interactive bool) string {
 	return dir
 }
 
+func initSite(dir string, interactive bool) string {
+	// Get default name from directory
+	defaultName := formatName(filepath.Base(dir))
+
+	var name, description, url, image string
+
+	if interactive {
+		reader := bufio.NewReader(os.Stdin)
+
+		ui.PrintInfo("Let's set up your WordPress site!")
+		fmt.Println()
+
+		name = prompt(reader, "Site name", defaultName)
+		description = prompt(reader, "Description", "A WordPress site")
+		url = prompt(reader, "Site URL", "")
+		image = prompt(reader, "Docker image", "wordpress:latest")
+
+		fmt.Println()
+	} else {
+		name = initName
+		if name == "" {
+			name = defaultName
+		}
+		description = initDescription
+		if description == "" {
+			description = "A WordPress site"
+		}
+	}
+
+	// If current directory is not empty (and isn't already a site), create subdirectory
+	if !isEmptyDir(dir) && !config.SiteExists(dir) {
+		slug := sanitizeName(name)
+		newDir := filepath.Join(dir, slug)
+		if err := os.MkdirAll(newDir, 0755); err != nil {
+			ui.PrintError("Failed to create directory %s: %v", slug, err)
+			exit(1)
+		}
+		dir = newDir
+	}
+
+	checkExisting(filepath.Join(dir, "site.properties"))
+
+	created, err := writeSiteFiles(dir, name, description, url, image)
+	if err != nil {
+		ui.PrintError("%v", err)
+		exit(1)
+	}
+
+	// Print success
+	ui.PrintSuccess("Created site: %s", name)
+	fmt.Println()
+	ui.PrintInfo("Files created:")
+	for _, f := range created {
+		fmt.Printf("  • %s\n", f)
+	}
+	fmt.Println()
+	ui.PrintInfo("Add plugins to plugins/ and themes to themes/")
+	ui.PrintInfo("Run 'wordsmith site start' to start WordPress")
+	ui.PrintInfo("Run 'wordsmith site build docker' to build a Docker image")
+	fmt.Println()
+
+	return dir
+}
+
 func generateReadme(name, description, author, slug string) string {
 	return fmt.Sprintf(`=== %s ===
 Contributors: %s
@@ -1517,3 +1827,257 @@ License URI: https://www.gnu.org/licenses/gpl-2.0.html
 * Initial release
 `, name, author, description, description, slug)
 }
+
+// importProject generates plugin.properties or theme.properties for an existing
+// plugin or theme from its header, extracting it first if from is a zip file
+func importProject(dir, from, buildType string, typeGiven bool) string {
+	if typeGiven && buildType != "plugin" && buildType != "theme" {
+		ui.PrintError("--from imports a plugin or theme, not a %s", buildType)
+		exit(1)
+	}
+
+	info, err := os.Stat(from)
+	if err != nil {
+		ui.PrintError("Failed to read %s: %v", from, err)
+		exit(1)
+	}
+
+	projectDir := from
+	if !info.IsDir() {
+		if !strings.HasSuffix(strings.ToLower(from), ".zip") {
+			ui.PrintError("--from must be a plugin or theme directory or zip file: %s", from)
+			exit(1)
+		}
+
+		// Extract into a directory named after the folder inside the zip
+		name, err := config.ZipRootDir(from)
+		if err != nil {
+			ui.PrintError("Failed to read %s: %v", from, err)
+			exit(1)
+		}
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(from), filepath.Ext(from))
+		}
+		projectDir = filepath.Join(dir, name)
+		checkExisting(projectDir)
+		if err := config.ExtractArchive(from, projectDir); err != nil {
+			ui.PrintError("Failed to extract %s: %v", from, err)
+			exit(1)
+		}
+	}
+	if abs, err := filepath.Abs(projectDir); err == nil {
+		projectDir = abs
+	}
+
+	mainFile, pluginHeader, err := config.FindPluginMainFile(projectDir)
+	if err != nil {
+		ui.PrintError("Failed to read plugin header: %v", err)
+		exit(1)
+	}
+	themeHeader, _ := config.ReadFileHeader(filepath.Join(projectDir, "style.css"), config.ThemeHeaderFields)
+
+	switch {
+	case (!typeGiven || buildType == "plugin") && mainFile != "":
+		importPlugin(projectDir, mainFile, pluginHeader)
+	case (!typeGiven || buildType == "theme") && themeHeader["Theme Name"] != "":
+		importTheme(projectDir, themeHeader)
+	case buildType == "theme" && typeGiven:
+		ui.PrintError("No style.css with a Theme Name header found in %s", projectDir)
+		exit(1)
+	default:
+		ui.PrintError("No PHP file with a Plugin Name header found in %s", projectDir)
+		exit(1)
+	}
+
+	return projectDir
+}
+
+// importPlugin writes plugin.properties for an existing plugin from its main file header
+func importPlugin(dir, mainFile string, header map[string]string) {
+	checkExisting(filepath.Join(dir, "plugin.properties"))
+
+	name := header["Plugin Name"]
+	slug := filepath.Base(dir)
+
+	var props []string
+	props = append(props, "# Plugin Configuration (imported from "+mainFile+")")
+	props = append(props, "")
+	props = append(props, fmt.Sprintf("name=%s", name))
+	if sanitizeName(name) != slug {
+		props = append(props, fmt.Sprintf("slug=%s", slug))
+	}
+	props = appendHeaderProps(props, header, [][2]string{
+		{"Version", "version"},
+		{"Description", "description"},
+		{"Author", "author"},
+		{"Author URI", "author-uri"},
+		{"Plugin URI", "plugin-uri"},
+		{"License", "license"},
+		{"License URI", "license-uri"},
+		{"Update URI", "update-uri"},
+	})
+	if strings.EqualFold(header["Network"], "true") {
+		props = append(props, "network=true")
+	}
+	props = append(props, "")
+	props = append(props, "# Main plugin file")
+	props = append(props, fmt.Sprintf("main=%s", mainFile))
+	props = appendRequirementProps(props, header)
+	if requires := header["Requires Plugins"]; requires != "" {
+		props = append(props, "")
+		props = append(props, "# Plugin dependencies (from Requires Plugins)")
+		props = append(props, fmt.Sprintf("plugins=%s", requires))
+	}
+	props = append(props, "")
+	props = append(props, "# Files to include (supports wildcards)")
+	props = append(props, fmt.Sprintf("include=%s", strings.Join(importIncludes(dir, mainFile), ",")))
+	props = append(props, "")
+	props = append(props, "# Files to exclude")
+	props = append(props, "exclude=node_modules,tests,.*")
+	props = appendTextDomainProps(props, dir, slug, header)
+
+	writeImportedProps(dir, "plugin.properties", props)
+	printImported("plugin", name, dir, "plugin.properties")
+}
+
+// importTheme writes theme.properties for an existing theme from its style.css header
+func importTheme(dir string, header map[string]string) {
+	checkExisting(filepath.Join(dir, "theme.properties"))
+
+	name := header["Theme Name"]
+	slug := filepath.Base(dir)
+
+	var props []string
+	props = append(props, "# Theme Configuration (imported from style.css)")
+	props = append(props, "")
+	props = append(props, fmt.Sprintf("name=%s", name))
+	if sanitizeName(name) != slug {
+		props = append(props, fmt.Sprintf("slug=%s", slug))
+	}
+	props = appendHeaderProps(props, header, [][2]string{
+		{"Version", "version"},
+		{"Description", "description"},
+		{"Author", "author"},
+		{"Author URI", "author-uri"},
+		{"Theme URI", "theme-uri"},
+		{"License", "license"},
+		{"License URI", "license-uri"},
+	})
+	props = append(props, "")
+	props = append(props, "# Main stylesheet file")
+	props = append(props, "main=style.css")
+	if template := header["Template"]; template != "" {
+		props = append(props, "")
+		props = append(props, "# Parent theme (add template-uri to install it in environments)")
+		props = append(props, fmt.Sprintf("template=%s", template))
+	}
+	props = appendRequirementProps(props, header)
+	props = append(props, "")
+	props = append(props, "# Files to include (supports wildcards)")
+	props = append(props, fmt.Sprintf("include=%s", strings.Join(importIncludes(dir, "style.css"), ",")))
+	props = append(props, "")
+	props = append(props, "# Files to exclude")
+	props = append(props, "exclude=node_modules,build,.*")
+	props = appendTextDomainProps(props, dir, slug, header)
+	if tags := header["Tags"]; tags != "" {
+		props = append(props, "")
+		props = append(props, "# Theme tags")
+		props = append(props, fmt.Sprintf("tags=%s", tags))
+	}
+
+	writeImportedProps(dir, "theme.properties", props)
+	printImported("theme", name, dir, "theme.properties")
+}
+
+// appendHeaderProps appends a key=value line for each header field that is set
+func appendHeaderProps(props []string, header map[string]string, fields [][2]string) []string {
+	for _, field := range fields {
+		if value := header[field[0]]; value != "" {
+			props = append(props, fmt.Sprintf("%s=%s", field[1], value))
+		}
+	}
+	return props
+}
+
+// appendRequirementProps appends the WordPress and PHP requirements from a header
+func appendRequirementProps(props []string, header map[string]string) []string {
+	if header["Requires at least"] == "" && header["Requires PHP"] == "" {
+		return props
+	}
+	props = append(props, "")
+	props = append(props, "# WordPress requirements")
+	return appendHeaderProps(props, header, [][2]string{
+		{"Requires at least", "requires"},
+		{"Requires PHP", "requires-php"},
+	})
+}
+
+// appendTextDomainProps appends the text domain and domain path, inferring them
+// from the slug and a languages/ directory when the header doesn't have them
+func appendTextDomainProps(props []string, dir, slug string, header map[string]string) []string {
+	textDomain := header["Text Domain"]
+	if textDomain == "" {
+		textDomain = slug
+	}
+	domainPath := header["Domain Path"]
+	if domainPath == "" {
+		if info, err := os.Stat(filepath.Join(dir, "languages")); err == nil && info.IsDir() {
+			domainPath = "/languages"
+		}
+	}
+
+	props = append(props, "")
+	props = append(props, "# Text domain for internationalization")
+	props = append(props, fmt.Sprintf("text-domain=%s", textDomain))
+	if domainPath != "" {
+		props = append(props, fmt.Sprintf("domain-path=%s", domainPath))
+	}
+	return props
+}
+
+// importIncludes lists an imported project's top-level directories and files to
+// include: everything except the main file, readme.txt (always copied), build
+// output, dependencies for development, and hidden files
+func importIncludes(dir, mainFile string) []string {
+	skip := map[string]bool{
+		mainFile: true, "readme.txt": true, "build": true, "node_modules": true,
+		"tests": true, "plugin.properties": true, "theme.properties": true,
+		"package.json": true, "package-lock.json": true, "composer.json": true,
+		"composer.lock": true, "phpunit.xml": true, "phpunit.xml.dist": true,
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var includes []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if skip[name] || strings.HasPrefix(name, ".") {
+			continue
+		}
+		includes = append(includes, name)
+	}
+	return includes
+}
+
+// writeImportedProps writes an imported project's properties file
+func writeImportedProps(dir, file string, props []string) {
+	props = append(props, "")
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(strings.Join(props, "\n")), 0644); err != nil {
+		ui.PrintError("Failed to create %s: %v", file, err)
+		exit(1)
+	}
+}
+
+// printImported prints the result of importing a plugin or theme
+func printImported(kind, name, dir, file string) {
+	ui.PrintSuccess("Imported %s: %s", kind, name)
+	fmt.Println()
+	ui.PrintInfo("Files created:")
+	fmt.Printf("  • %s\n", filepath.Join(dir, file))
+	fmt.Println()
+	ui.PrintInfo("Review the include list, then run 'wordsmith build' to build your %s", kind)
+	fmt.Println()
+}
@@ -3,20 +3,52 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
+	"wordsmith/internal/config"
 	"wordsmith/internal/ui"
 )
 
 // Version is set by ldflags during build
 var Version = "dev"
 
+// rootDir is set by the --root flag
+var rootDir string
+
+// jsonEvents is set by the --json-events flag
+var jsonEvents bool
+
+// noCache is set by the --no-cache flag
+var noCache bool
+
+// maxDownloadSize is set by the --max-download-size flag
+var maxDownloadSize string
+
 var rootCmd = &cobra.Command{
 	Use:   "wordsmith",
 	Short: "WordPress plugin, theme, and library build tool",
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		if dir, err := os.Getwd(); err == nil {
-			upgradeClaudeSkill(dir)
+		if jsonEvents {
+			ui.EnableJSONEvents()
+		}
+		if noCache {
+			// Set through the environment so child wordsmith processes inherit it
+			os.Setenv(config.NoCacheEnv, "1")
+		}
+		if maxDownloadSize != "" {
+			if _, err := config.ParseSize(maxDownloadSize); err != nil {
+				ui.PrintError("--max-download-size: %v", err)
+				exit(1)
+			}
+			os.Setenv(config.MaxDownloadSizeEnv, maxDownloadSize)
+		}
+		// Only from the project root (or --root), so running in a directory that
+		// isn't part of a parent project never rewrites that project's skill file
+		if dir, err := getProjectDir(); err == nil {
+			if cwd, err := os.Getwd(); err == nil && (rootDir != "" || cwd == dir) {
+				upgradeClaudeSkill(dir)
+			}
 		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
@@ -25,13 +57,56 @@ var rootCmd = &cobra.Command{
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	config.CleanupTempDirs()
+	if err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		exit(1)
+	}
+}
+
+// exit removes the temp directories this process created, which deferred cleanup
+// would miss, and exits with code. Commands call it instead of os.Exit.
+func exit(code int) {
+	config.CleanupTempDirs()
+	os.Exit(code)
+}
+
+// getProjectDir returns the project directory: the --root flag if set, otherwise the
+// nearest directory at or above the current directory with a properties file.
+// Falls back to the current directory when no project is found.
+func getProjectDir() (string, error) {
+	if rootDir != "" {
+		dir, err := filepath.Abs(rootDir)
+		if err != nil {
+			return "", err
+		}
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			return "", fmt.Errorf("project root is not a directory: %s", rootDir)
+		}
+		return dir, nil
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	if projectDir, found := config.FindProjectRoot(dir); found {
+		return projectDir, nil
 	}
+	return dir, nil
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&rootDir, "root", "", "Project root directory (defaults to the nearest parent with a properties file)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Bypass the library and download caches for this run (also WORDSMITH_NO_CACHE=1)")
+	rootCmd.PersistentFlags().StringVar(&maxDownloadSize, "max-download-size", "", "Largest plugin, theme, or library download to accept, e.g. 200MB or 0 for no limit (default 1GB; also WORDSMITH_MAX_DOWNLOAD_SIZE)")
+	rootCmd.PersistentFlags().BoolVar(&jsonEvents, "json-events", false, "Emit newline-delimited JSON progress events on stdout instead of styled output")
+	rootCmd.RegisterFlagCompletionFunc("root", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	})
 	rootCmd.Long = ui.Divider() + "\n" + ui.Banner() + "\n" + ui.VersionLine(Version) + "\n\n" + ui.Divider() + "\n\nA CLI tool for building WordPress plugins, themes, and libraries"
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(initCmd)
@@ -32,9 +32,10 @@ var siteStartCmd = &cobra.Command{
 }
 
 var siteStopCmd = &cobra.Command{
-	Use:   "stop [name]",
-	Short: "Stop WordPress site",
-	Long:  "Stop the WordPress development environment for the site",
+	Use:               "stop [name]",
+	Short:             "Stop WordPress site",
+	Long:              "Stop the WordPress development environment for the site",
+	ValidArgsFunction: completeEnvironments,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Delegate to wordpress stop command
 		stopCmd.Run(cmd, args)
@@ -42,9 +43,10 @@ var siteStopCmd = &cobra.Command{
 }
 
 var siteDeleteCmd = &cobra.Command{
-	Use:   "delete [name]",
-	Short: "Delete WordPress site environment",
-	Long:  "Delete the WordPress development environment and all data for the site",
+	Use:               "delete [name]",
+	Short:             "Delete WordPress site environment",
+	Long:              "Delete the WordPress development environment and all data for the site",
+	ValidArgsFunction: completeEnvironments,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Delegate to wordpress delete command
 		deleteCmd.Run(cmd, args)
@@ -61,21 +63,21 @@ var siteBuildCmd = &cobra.Command{
 			ui.PrintHeader(Version)
 		}
 
-		dir, err := os.Getwd()
+		dir, err := getProjectDir()
 		if err != nil {
-			ui.PrintError("Failed to get current directory: %v", err)
-			os.Exit(1)
+			ui.PrintError("Failed to determine project directory: %v", err)
+			exit(1)
 		}
 
 		if !config.SiteExists(dir) {
 			ui.PrintError("No site.properties found in current directory")
-			os.Exit(1)
+			exit(1)
 		}
 
 		siteConfig, err := config.LoadSiteProperties(dir)
 		if err != nil {
 			ui.PrintError("Failed to load site.properties: %v", err)
-			os.Exit(1)
+			exit(1)
 		}
 
 		if !quiet {
@@ -128,29 +130,42 @@ var siteBuildDockerCmd = &cobra.Command{
 			ui.PrintHeader(Version)
 		}
 
-		dir, err := os.Getwd()
+		dir, err := getProjectDir()
 		if err != nil {
-			ui.PrintError("Failed to get current directory: %v", err)
-			os.Exit(1)
+			ui.PrintError("Failed to determine project directory: %v", err)
+			exit(1)
 		}
 
 		if !config.SiteExists(dir) {
 			ui.PrintError("No site.properties found in current directory")
-			os.Exit(1)
+			exit(1)
 		}
 
 		siteConfig, err := config.LoadSiteProperties(dir)
 		if err != nil {
 			ui.PrintError("Failed to load site.properties: %v", err)
-			os.Exit(1)
+			exit(1)
+		}
+
+		labelFlags, _ := cmd.Flags().GetStringArray("label")
+		labels, err := config.ParseLabelList(labelFlags)
+		if err != nil {
+			ui.PrintError("%v", err)
+			exit(1)
 		}
 
 		d := builder.NewSiteDockerBuilder(dir, siteConfig)
 		d.Quiet = quiet
 		d.WordsmithVersion = Version
+		d.Labels = labels
+		d.PullPolicy, _ = cmd.Flags().GetString("base-image-pull-policy")
+		d.Strict, _ = cmd.Flags().GetBool("strict")
+		d.Push, _ = cmd.Flags().GetBool("push")
+		d.Registry, _ = cmd.Flags().GetString("registry")
+		d.CacheFrom, _ = cmd.Flags().GetStringArray("cache-from")
 		if err := d.Build(); err != nil {
 			ui.PrintError("Docker build failed: %v", err)
-			os.Exit(1)
+			exit(1)
 		}
 	},
 }
@@ -168,13 +183,13 @@ var siteInitCmd = &cobra.Command{
 		dir, err := os.Getwd()
 		if err != nil {
 			ui.PrintError("Failed to get current directory: %v", err)
-			os.Exit(1)
+			exit(1)
 		}
 
 		// Check if site.properties already exists
 		if config.SiteExists(dir) {
 			ui.PrintError("site.properties already exists in current directory")
-			os.Exit(1)
+			exit(1)
 		}
 
 		// Get site name from directory name or flag
@@ -183,13 +198,47 @@ var siteInitCmd = &cobra.Command{
 			name = filepath.Base(dir)
 		}
 
-		// Create site.properties
-		siteProps := fmt.Sprintf(`name: %s
-description: A WordPress site
-# url: https://example.com
+		created, err := writeSiteFiles(dir, name, "A WordPress site", "", "")
+		if err != nil {
+			ui.PrintError("%v", err)
+			exit(1)
+		}
+
+		if !quiet {
+			ui.PrintSuccess("Site initialized: %s", name)
+			fmt.Println()
+			ui.PrintInfo("Created:")
+			for _, f := range created {
+				ui.PrintInfo("  %s", f)
+			}
+			fmt.Println()
+			ui.PrintInfo("Add plugins to plugins/ and themes to themes/")
+			ui.PrintInfo("Run 'wordsmith site start' to start WordPress")
+		}
+	},
+}
+
+// writeSiteFiles creates site.properties, the plugins/ and themes/ directories with
+// READMEs describing their conventions, and a .gitignore. Existing READMEs and
+// .gitignore files are left untouched. Returns the list of created paths.
+func writeSiteFiles(dir, name, description, url, image string) ([]string, error) {
+	var created []string
+
+	urlLine := "# url: https://example.com"
+	if url != "" {
+		urlLine = "url: " + url
+	}
+	imageLine := "# image: wordpress:6.4-php8.2"
+	if image != "" {
+		imageLine = "image: " + image
+	}
+
+	siteProps := fmt.Sprintf(`name: %s
+description: %s
+%s
 
 # Docker image (defaults to wordpress:latest)
-# image: wordpress:6.4-php8.2
+%s
 
 # Plugins from WordPress.org, GitHub, or URLs
 plugins:
@@ -199,48 +248,87 @@ plugins:
 # Themes from WordPress.org, GitHub, or URLs
 themes:
   # - flavor
-`, name)
+`, name, description, urlLine, imageLine)
+
+	if err := os.WriteFile(filepath.Join(dir, "site.properties"), []byte(siteProps), 0644); err != nil {
+		return created, fmt.Errorf("failed to create site.properties: %w", err)
+	}
+	created = append(created, "site.properties")
+
+	readmes := map[string]string{
+		"plugins": `# Plugins
 
-		if err := os.WriteFile(filepath.Join(dir, "site.properties"), []byte(siteProps), 0644); err != nil {
-			ui.PrintError("Failed to create site.properties: %v", err)
-			os.Exit(1)
+Local plugins for this site. Each entry is installed and activated when the
+site starts or is built into a Docker image.
+
+- A directory containing plugin.properties is built with wordsmith
+- A .zip file is installed as-is (the file name is used as the slug)
+- A directory containing .zip files installs each zip
+`,
+		"themes": `# Themes
+
+Local themes for this site. The first theme is activated by default.
+
+- A directory containing theme.properties is built with wordsmith
+- A .zip file is installed as-is (the file name is used as the slug)
+- A directory containing .zip files installs each zip
+`,
+	}
+
+	for _, sub := range []string{"plugins", "themes"} {
+		subDir := filepath.Join(dir, sub)
+		if err := os.MkdirAll(subDir, 0755); err != nil {
+			return created, fmt.Errorf("failed to create %s directory: %w", sub, err)
 		}
+		created = append(created, sub+"/")
 
-		// Create plugins and themes directories
-		if err := os.MkdirAll(filepath.Join(dir, "plugins"), 0755); err != nil {
-			ui.PrintError("Failed to create plugins directory: %v", err)
-			os.Exit(1)
+		readmePath := filepath.Join(subDir, "README.md")
+		if !config.FileExists(readmePath) {
+			if err := os.WriteFile(readmePath, []byte(readmes[sub]), 0644); err != nil {
+				return created, fmt.Errorf("failed to create %s/README.md: %w", sub, err)
+			}
+			created = append(created, sub+"/README.md")
 		}
-		if err := os.MkdirAll(filepath.Join(dir, "themes"), 0755); err != nil {
-			ui.PrintError("Failed to create themes directory: %v", err)
-			os.Exit(1)
+	}
+
+	gitignorePath := filepath.Join(dir, ".gitignore")
+	if !config.FileExists(gitignorePath) {
+		gitignoreContent := `.DS_Store
+*.log
+build/
+.wordsmith/
+`
+		if err := os.WriteFile(gitignorePath, []byte(gitignoreContent), 0644); err != nil {
+			return created, fmt.Errorf("failed to create .gitignore: %w", err)
 		}
+		created = append(created, ".gitignore")
+	}
 
-		if !quiet {
-			ui.PrintSuccess("Site initialized: %s", name)
-			fmt.Println()
-			ui.PrintInfo("Created:")
-			ui.PrintInfo("  site.properties")
-			ui.PrintInfo("  plugins/")
-			ui.PrintInfo("  themes/")
-			fmt.Println()
-			ui.PrintInfo("Add plugins to plugins/ and themes to themes/")
-			ui.PrintInfo("Run 'wordsmith site start' to start WordPress")
-		}
-	},
+	return created, nil
 }
 
 func init() {
 	// Site command flags
 	siteStartCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	siteStartCmd.Flags().StringArray("label", nil, "Add a label to the containers (key=value, repeatable)")
+	siteStartCmd.Flags().Bool("open-site", false, "Open only the site in the browser (remembered for this project)")
+	siteStartCmd.Flags().Bool("open-admin", false, "Open only wp-admin in the browser (remembered for this project)")
+	siteStartCmd.Flags().Bool("manage-hosts", false, "Add the hostname to /etc/hosts (uses sudo)")
 	siteStopCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
 	siteDeleteCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	siteDeleteCmd.Flags().Bool("manage-hosts", false, "Remove the hostname wordsmith added to /etc/hosts (uses sudo)")
 	siteBuildCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
 	siteBuildDockerCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	siteBuildDockerCmd.Flags().StringArray("label", nil, "Add a label to the image (key=value, repeatable)")
+	siteBuildDockerCmd.Flags().String("base-image-pull-policy", "missing", "Base image pull policy: missing (use the cache), always (docker build --pull), or never")
+	siteBuildDockerCmd.Flags().Bool("strict", false, "Fail the build if any plugin or theme can't be built or downloaded")
 	siteInitCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
 	siteInitCmd.Flags().StringP("name", "n", "", "Site name")
 
 	// Add subcommands
+	siteBuildDockerCmd.Flags().Bool("push", false, "Push the versioned image to --registry after building")
+	siteBuildDockerCmd.Flags().String("registry", "", "Registry to push to, e.g. ghcr.io/owner (the image is pushed as <registry>/<slug>:v<version>)")
+	siteBuildDockerCmd.Flags().StringArray("cache-from", nil, "Reuse layers from an image, e.g. the last pushed one (repeatable)")
 	siteBuildCmd.AddCommand(siteBuildDockerCmd)
 	siteCmd.AddCommand(siteStartCmd)
 	siteCmd.AddCommand(siteStopCmd)
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/spf13/cobra"
+	"wordsmith/internal/builder"
+	"wordsmith/internal/config"
+	"wordsmith/internal/ui"
+)
+
+var (
+	// validVersion matches the major.minor.patch versions the build parses, with an
+	// optional -prerelease and +build suffix
+	validVersion = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+	// validRequiresVersion matches a WordPress or PHP version such as 6.0 or 7.4
+	validRequiresVersion = regexp.MustCompile(`^\d+(\.\d+){0,2}$`)
+	// validTextDomain matches a text domain: lowercase letters, numbers, and dashes
+	validTextDomain = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
+// validationProblem is a problem found in a properties file
+type validationProblem struct {
+	file    string
+	key     string // Offending property, if the problem is with one
+	message string
+}
+
+func (p validationProblem) String() string {
+	if p.key == "" {
+		return fmt.Sprintf("%s: %s", p.file, p.message)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.file, p.key, p.message)
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check properties files for problems",
+	Long:  "Load the project's properties files and check them for problems that would otherwise only show up during a build: a missing main file, include patterns that match nothing, child themes without a parent, and invalid versions and text domains",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ui.PrintHeader(Version)
+
+		dir, err := getProjectDir()
+		if err != nil {
+			ui.PrintError("Failed to determine project directory: %v", err)
+			exit(1)
+		}
+
+		files, problems := validateProject(dir)
+		if len(files) == 0 {
+			ui.PrintError("No properties files found in %s", dir)
+			exit(1)
+		}
+
+		for _, problem := range problems {
+			ui.PrintError("%s", problem)
+		}
+		if len(problems) > 0 {
+			fmt.Println()
+			ui.PrintError("Found %d problems", len(problems))
+			exit(1)
+		}
+
+		for _, file := range files {
+			ui.PrintSuccess("%s is valid", file)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
+
+// validateProject checks every properties file in dir, returning the files it
+// checked and the problems it found
+func validateProject(dir string) ([]string, []validationProblem) {
+	var files []string
+	var problems []validationProblem
+
+	if config.PluginExists(dir) {
+		files = append(files, "plugin.properties")
+		cfg, err := config.LoadPluginProperties(dir)
+		if err != nil {
+			problems = append(problems, validationProblem{"plugin.properties", "", err.Error()})
+		} else {
+			problems = append(problems, validateMain(dir, "plugin.properties", cfg.Main)...)
+			problems = append(problems, validateIncludes(dir, "plugin.properties", cfg.Include)...)
+			problems = append(problems, validateVersions("plugin.properties", cfg.Version, cfg.Requires, cfg.RequiresPHP)...)
+			problems = append(problems, validateTextDomain("plugin.properties", cfg.TextDomain)...)
+			if cfg.TestedUpTo != "" && !validRequiresVersion.MatchString(cfg.TestedUpTo) {
+				problems = append(problems, validationProblem{"plugin.properties", "tested-up-to", fmt.Sprintf("invalid WordPress version %q (use a version like 6.5)", cfg.TestedUpTo)})
+			}
+		}
+	}
+
+	if config.ThemeExists(dir) {
+		files = append(files, "theme.properties")
+		cfg, err := config.LoadThemeProperties(dir)
+		if err != nil {
+			problems = append(problems, validationProblem{"theme.properties", "", err.Error()})
+		} else {
+			problems = append(problems, validateMain(dir, "theme.properties", cfg.Main)...)
+			problems = append(problems, validateIncludes(dir, "theme.properties", cfg.Include)...)
+			problems = append(problems, validateVersions("theme.properties", cfg.Version, cfg.Requires, cfg.RequiresPHP)...)
+			problems = append(problems, validateTextDomain("theme.properties", cfg.TextDomain)...)
+
+			patternProblems, err := builder.CheckPatterns(dir)
+			if err != nil {
+				problems = append(problems, validationProblem{"theme.properties", "", fmt.Sprintf("failed to read block patterns: %v", err)})
+			}
+			for _, problem := range patternProblems {
+				problems = append(problems, validationProblem{problem.File, problem.Field, "missing required header"})
+			}
+
+			styleProblems, err := builder.CheckStyleVariations(dir)
+			if err != nil {
+				problems = append(problems, validationProblem{"theme.properties", "", fmt.Sprintf("failed to read style variations: %v", err)})
+			}
+			for _, problem := range styleProblems {
+				problems = append(problems, validationProblem{problem.File, "", problem.Message})
+			}
+
+			// A child theme needs both the parent's slug and where to get it from
+			if cfg.Template != "" && cfg.TemplateURI == "" {
+				problems = append(problems, validationProblem{"theme.properties", "template-uri", "child theme has a template but no template-uri to fetch the parent theme from"})
+			}
+			if cfg.TemplateURI != "" && cfg.Template == "" {
+				problems = append(problems, validationProblem{"theme.properties", "template", "child theme has a template-uri but no template (the parent theme's slug)"})
+			}
+		}
+	}
+
+	if config.LibraryExists(dir) {
+		files = append(files, "library.properties")
+		cfg, err := config.LoadLibraryProperties(dir)
+		if err != nil {
+			problems = append(problems, validationProblem{"library.properties", "", err.Error()})
+		} else {
+			problems = append(problems, validateIncludes(dir, "library.properties", cfg.Include)...)
+			problems = append(problems, validateVersions("library.properties", cfg.Version, "", "")...)
+		}
+	}
+
+	if config.WordPressExists(dir) {
+		files = append(files, "wordpress.properties")
+		if _, err := config.LoadWordPressProperties(dir); err != nil {
+			problems = append(problems, validationProblem{"wordpress.properties", "", err.Error()})
+		}
+	}
+
+	if config.SiteExists(dir) {
+		files = append(files, "site.properties")
+		if _, err := config.LoadSiteProperties(dir); err != nil {
+			problems = append(problems, validationProblem{"site.properties", "", err.Error()})
+		}
+	}
+
+	return files, problems
+}
+
+// validateMain checks that the main file exists
+func validateMain(dir, file, main string) []validationProblem {
+	if info, err := os.Stat(filepath.Join(dir, main)); err != nil || info.IsDir() {
+		return []validationProblem{{file, "main", fmt.Sprintf("file %s not found", main)}}
+	}
+	return nil
+}
+
+// validateIncludes checks that every include pattern matches at least one file or directory
+func validateIncludes(dir, file string, includes []string) []validationProblem {
+	var problems []validationProblem
+	for _, pattern := range includes {
+		matches, err := builder.ExpandGlob(dir, pattern)
+		if err != nil {
+			problems = append(problems, validationProblem{file, "include", fmt.Sprintf("invalid pattern %q: %v", pattern, err)})
+		} else if len(matches) == 0 {
+			problems = append(problems, validationProblem{file, "include", fmt.Sprintf("%q matches no files", pattern)})
+		}
+	}
+	return problems
+}
+
+// validateVersions checks the version and the WordPress and PHP requirements, when set
+func validateVersions(file, version, requires, requiresPHP string) []validationProblem {
+	var problems []validationProblem
+	if version != "" && !validVersion.MatchString(version) {
+		problems = append(problems, validationProblem{file, "version", fmt.Sprintf("invalid version %q (use major.minor.patch, like 1.2.0 or 1.2.0-beta.1)", version)})
+	}
+	if requires != "" && !validRequiresVersion.MatchString(requires) {
+		problems = append(problems, validationProblem{file, "requires", fmt.Sprintf("invalid WordPress version %q (use a version like 6.0)", requires)})
+	}
+	if requiresPHP != "" && !validRequiresVersion.MatchString(requiresPHP) {
+		problems = append(problems, validationProblem{file, "requires-php", fmt.Sprintf("invalid PHP version %q (use a version like 7.4)", requiresPHP)})
+	}
+	return problems
+}
+
+// validateTextDomain checks that the text domain, when set, is a valid slug
+func validateTextDomain(file, textDomain string) []validationProblem {
+	if textDomain != "" && !validTextDomain.MatchString(textDomain) {
+		return []validationProblem{{file, "text-domain", fmt.Sprintf("invalid text domain %q (use lowercase letters, numbers, and dashes, like my-plugin)", textDomain)}}
+	}
+	return nil
+}
@@ -5,193 +5,314 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/cobra"
 	"wordsmith/internal/builder"
 	"wordsmith/internal/config"
 	"wordsmith/internal/ui"
 )
 
+// watchDebounce is how long watch waits after the last change before running,
+// so saving several files (or an editor's write-and-rename) triggers one run
+const watchDebounce = 300 * time.Millisecond
+
 var watchCmd = &cobra.Command{
-	Use:   "watch [build|deploy]",
-	Short: "Watch for changes and build or deploy",
-	Args:  cobra.ExactArgs(1),
+	Use:       "watch [build|deploy]",
+	Short:     "Watch for changes and build or deploy",
+	Long:      "Watch the project's files and rebuild (build) or rebuild and redeploy to the running environment (deploy) whenever they change",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"build", "deploy"},
 	Run: func(cmd *cobra.Command, args []string) {
 		ui.PrintHeader(Version)
 
 		mode := args[0]
 		if mode != "build" && mode != "deploy" {
 			ui.PrintError("Invalid mode. Use 'build' or 'deploy'")
-			os.Exit(1)
+			exit(1)
+		}
+		assetsOnly, _ := cmd.Flags().GetBool("assets-only")
+		if assetsOnly && mode != "deploy" {
+			ui.PrintError("--assets-only only applies to deploy mode")
+			exit(1)
 		}
 
-		dir, err := os.Getwd()
+		dir, err := getProjectDir()
 		if err != nil {
-			ui.PrintError("Failed to get current directory: %v", err)
-			os.Exit(1)
+			ui.PrintError("Failed to determine project directory: %v", err)
+			exit(1)
 		}
 
-		isTheme := config.ThemeExists(dir)
-		isPlugin := config.PluginExists(dir)
-		isLibrary := config.LibraryExists(dir)
-
-		if !isTheme && !isPlugin && !isLibrary {
-			ui.PrintError("No plugin.properties, theme.properties, or library.properties found in current directory")
-			os.Exit(1)
+		project, err := loadWatchProject(dir)
+		if err != nil {
+			ui.PrintError("%v", err)
+			exit(1)
 		}
 
-		var mainFile string
-		var includes []string
-		var propsFile string
-
-		if isTheme {
-			cfg, err := config.LoadThemeProperties(dir)
-			if err != nil {
-				ui.PrintError("Failed to load theme.properties: %v", err)
-				os.Exit(1)
-			}
-			mainFile = cfg.Main
-			includes = cfg.Include
-			propsFile = "theme.properties"
-		} else if isPlugin {
-			cfg, err := config.LoadPluginProperties(dir)
-			if err != nil {
-				ui.PrintError("Failed to load plugin.properties: %v", err)
-				os.Exit(1)
+		// Changed CSS and JS is copied straight into the WordPress container
+		var containerName string
+		if assetsOnly {
+			if project.kind == "library" {
+				ui.PrintError("Libraries cannot be deployed directly to WordPress.")
+				exit(1)
 			}
-			mainFile = cfg.Main
-			includes = cfg.Include
-			propsFile = "plugin.properties"
-		} else {
-			cfg, err := config.LoadLibraryProperties(dir)
+			instanceName, err := getInstanceName(dir)
 			if err != nil {
-				ui.PrintError("Failed to load library.properties: %v", err)
-				os.Exit(1)
+				ui.PrintError("%v", err)
+				exit(1)
 			}
-			mainFile = ""
-			includes = cfg.Include
-			propsFile = "library.properties"
+			containerName = sanitizeForDocker(instanceName) + "-wordpress"
+		}
+
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			ui.PrintError("Failed to start file watcher: %v", err)
+			exit(1)
+		}
+		defer watcher.Close()
+		if err := addWatchDirs(watcher, dir, dir, project.excludes); err != nil {
+			ui.PrintError("Failed to watch %s: %v", dir, err)
+			exit(1)
 		}
 
 		// Run initial build/deploy
 		ui.PrintInfo("Running initial %s...", mode)
 		fmt.Println()
-
-		var initialCmd *exec.Cmd
-		if mode == "deploy" {
-			initialCmd = exec.Command(os.Args[0], "deploy", "--quiet")
-		} else {
-			initialCmd = exec.Command(os.Args[0], "build", "--quiet")
-		}
-		initialCmd.Stdout = os.Stdout
-		initialCmd.Stderr = os.Stderr
-		initialCmd.Dir = dir
-		initialCmd.Run()
+		runWatchMode(dir, mode)
 
 		fmt.Println()
 		ui.PrintInfo("Watching for changes (mode: %s)...", mode)
 		ui.PrintInfo("Press Ctrl+C to stop")
 		fmt.Println()
 
-		lastMod := time.Now()
-		debounce := 500 * time.Millisecond
+		timer := time.NewTimer(watchDebounce)
+		timer.Stop()
+		changed := make(map[string]bool)
 
 		for {
-			time.Sleep(500 * time.Millisecond)
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
 
-			changed, newMod := hasChangesGeneric(dir, mainFile, includes, propsFile, lastMod)
-			if !changed {
-				continue
-			}
+				// Watch directories created after startup, e.g. a new includes/ subdirectory
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						addWatchDirs(watcher, dir, event.Name, project.excludes)
+					}
+				}
+				if event.Op == fsnotify.Chmod {
+					continue
+				}
 
-			if time.Since(newMod) < debounce {
-				continue
-			}
+				rel, err := filepath.Rel(dir, event.Name)
+				if err != nil {
+					continue
+				}
 
-			lastMod = time.Now()
+				// Includes and excludes may have changed along with the properties or ignore file
+				if rel == project.propsFile || rel == builder.IgnoreFile {
+					if reloaded, err := loadWatchProject(dir); err == nil {
+						project = reloaded
+					} else {
+						ui.PrintWarning("%v", err)
+					}
+				}
+				if !project.watches(rel) {
+					continue
+				}
+				changed[rel] = true
+				timer.Reset(watchDebounce)
 
-			fmt.Println()
-			ui.PrintInfo("Changes detected, running %s...", mode)
-			fmt.Println()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				ui.PrintWarning("File watcher error: %v", err)
 
-			var buildCmd *exec.Cmd
-			if mode == "deploy" {
-				buildCmd = exec.Command(os.Args[0], "deploy", "--quiet")
-			} else {
-				buildCmd = exec.Command(os.Args[0], "build", "--quiet")
-			}
-			buildCmd.Stdout = os.Stdout
-			buildCmd.Stderr = os.Stderr
-			buildCmd.Dir = dir
-			buildCmd.Run()
+			case <-timer.C:
+				files := changed
+				changed = make(map[string]bool)
+
+				// Skip the full build when only stylesheets and scripts changed
+				if assets, ok := project.assets(dir, files); assetsOnly && ok && isContainerRunning(containerName) {
+					start := time.Now()
+					err := deployAssets(dir, containerName, project, assets)
+					if err == nil {
+						ui.PrintSuccess("Copied %s in %.1fs", strings.Join(assets, ", "), time.Since(start).Seconds())
+						continue
+					}
+					ui.PrintWarning("%v, running %s instead", err, mode)
+				}
+
+				fmt.Println()
+				ui.PrintInfo("Changes detected, running %s...", mode)
+				fmt.Println()
+
+				start := time.Now()
+				ok := runWatchMode(dir, mode)
+				elapsed := time.Since(start).Seconds()
 
-			fmt.Println()
-			ui.PrintInfo("Watching for changes...")
+				fmt.Println()
+				switch {
+				case ok && mode == "deploy":
+					ui.PrintSuccess("Rebuilt and deployed in %.1fs", elapsed)
+				case ok:
+					ui.PrintSuccess("Rebuilt in %.1fs", elapsed)
+				case mode == "deploy":
+					ui.PrintError("Deploy failed after %.1fs", elapsed)
+				default:
+					ui.PrintError("Build failed after %.1fs", elapsed)
+				}
+				ui.PrintInfo("Watching for changes...")
+			}
 		}
 	},
 }
 
 func init() {
+	watchCmd.Flags().Bool("assets-only", false, "In deploy mode, copy changed CSS and JS (minified if enabled) into the container instead of redeploying")
 	rootCmd.AddCommand(watchCmd)
 }
 
-func hasChangesGeneric(dir string, mainFile string, includes []string, propsFile string, since time.Time) (bool, time.Time) {
-	var latestMod time.Time
-	changed := false
+// watchProject is what watch needs from a project's properties file
+type watchProject struct {
+	kind          string // plugin, theme, or library
+	slug          string // Directory name in wp-content/plugins or wp-content/themes
+	mainFile      string
+	propsFile     string
+	includes      []string
+	excludes      []string
+	minify        bool
+	minifyExclude []string
+}
+
+// loadWatchProject loads the main file, include, and exclude patterns of the
+// plugin, theme, or library in dir, with the patterns in its .wordsmithignore
+func loadWatchProject(dir string) (*watchProject, error) {
+	project, err := loadWatchProperties(dir)
+	if err != nil {
+		return nil, err
+	}
+	if project.excludes, err = builder.EffectiveExcludes(dir, project.excludes); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
 
-	checkFile := func(path string) {
-		info, err := os.Stat(path)
+// loadWatchProperties loads the watchProject from the properties file in dir
+func loadWatchProperties(dir string) (*watchProject, error) {
+	switch {
+	case config.ThemeExists(dir):
+		cfg, err := config.LoadThemeProperties(dir)
 		if err != nil {
-			return
+			return nil, fmt.Errorf("failed to load theme.properties: %w", err)
 		}
-		if info.ModTime().After(since) {
-			changed = true
+		return &watchProject{"theme", sanitizeForDocker(cfg.Name), cfg.Main, "theme.properties", cfg.Include, cfg.Exclude, cfg.Minify, cfg.MinifyExclude}, nil
+	case config.PluginExists(dir):
+		cfg, err := config.LoadPluginProperties(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load plugin.properties: %w", err)
 		}
-		if info.ModTime().After(latestMod) {
-			latestMod = info.ModTime()
+		return &watchProject{"plugin", sanitizeForDocker(cfg.Name), cfg.Main, "plugin.properties", cfg.Include, cfg.Exclude, cfg.Minify, cfg.MinifyExclude}, nil
+	case config.LibraryExists(dir):
+		cfg, err := config.LoadLibraryProperties(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load library.properties: %w", err)
 		}
+		return &watchProject{"library", "", "", "library.properties", cfg.Include, cfg.Exclude, false, nil}, nil
 	}
+	return nil, fmt.Errorf("no plugin.properties, theme.properties, or library.properties found in current directory")
+}
 
-	mainPath := filepath.Join(dir, mainFile)
-	checkFile(mainPath)
+// watches checks if a change to rel (relative to the project directory) should
+// trigger a run: the main file, the properties file, or an included file that
+// isn't excluded. Hidden files, such as editor swap files, are ignored, apart
+// from .wordsmithignore.
+func (p *watchProject) watches(rel string) bool {
+	if rel == builder.IgnoreFile {
+		return true
+	}
+	if strings.HasPrefix(filepath.Base(rel), ".") || builder.IsExcludedPath(rel, p.excludes) {
+		return false
+	}
+	if rel == p.propsFile || (p.mainFile != "" && rel == filepath.Clean(p.mainFile)) {
+		return true
+	}
+	return builder.IsIncludedPath(rel, p.includes)
+}
 
-	for _, include := range includes {
-		// Expand glob patterns
-		expanded, err := builder.ExpandGlob(dir, include)
-		if err != nil {
-			continue
+// assets returns the changed files, sorted, if they are all CSS or JS files
+// that are copied into the build as they are (so not a theme's style.css)
+func (p *watchProject) assets(dir string, changed map[string]bool) ([]string, bool) {
+	var assets []string
+	for rel := range changed {
+		ext := filepath.Ext(rel)
+		if (ext != ".css" && ext != ".js") || rel == filepath.Clean(p.mainFile) {
+			return nil, false
+		}
+		if info, err := os.Stat(filepath.Join(dir, rel)); err != nil || info.IsDir() {
+			return nil, false
 		}
+		assets = append(assets, rel)
+	}
+	sort.Strings(assets)
+	return assets, len(assets) > 0
+}
 
-		for _, relPath := range expanded {
-			path := filepath.Join(dir, relPath)
-			info, err := os.Stat(path)
-			if err != nil {
-				continue
-			}
+// deployAssets copies changed CSS and JS files, minified if the project minifies
+// them, over the deployed copies in the WordPress container
+func deployAssets(dir, containerName string, p *watchProject, assets []string) error {
+	tempDir, err := os.MkdirTemp("", "wordsmith-assets")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
 
-			if info.IsDir() {
-				filepath.Walk(path, func(p string, i os.FileInfo, e error) error {
-					if e != nil || i.IsDir() {
-						return nil
-					}
-					if strings.HasPrefix(i.Name(), ".") {
-						return nil
-					}
-					checkFile(p)
-					return nil
-				})
-			} else {
-				if !strings.HasPrefix(info.Name(), ".") {
-					checkFile(path)
-				}
+	for _, rel := range assets {
+		src := filepath.Join(dir, rel)
+		if p.minify && builder.ShouldMinify(rel, p.minifyExclude) {
+			minified := filepath.Join(tempDir, rel)
+			if err := builder.CopyAndMinify(src, minified, true); err != nil {
+				return fmt.Errorf("failed to minify %s: %w", rel, err)
 			}
+			src = minified
+		}
+
+		containerPath := fmt.Sprintf("/var/www/html/wp-content/%ss/%s/%s", p.kind, p.slug, filepath.ToSlash(rel))
+		if output, err := exec.Command("docker", "cp", src, containerName+":"+containerPath).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", rel, commandError(err, output))
 		}
 	}
+	return nil
+}
 
-	propsPath := filepath.Join(dir, propsFile)
-	checkFile(propsPath)
+// addWatchDirs watches root and its subdirectories, skipping hidden and excluded
+// directories and the build output
+func addWatchDirs(watcher *fsnotify.Watcher, projectDir, root string, excludes []string) error {
+	return filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+		if path != projectDir {
+			rel, _ := filepath.Rel(projectDir, path)
+			if rel == "build" || strings.HasPrefix(entry.Name(), ".") || builder.IsExcluded(rel, excludes) {
+				return filepath.SkipDir
+			}
+		}
+		return watcher.Add(path)
+	})
+}
 
-	return changed, latestMod
+// runWatchMode runs wordsmith build or deploy quietly in dir, reporting whether it succeeded
+func runWatchMode(dir, mode string) bool {
+	runCmd := exec.Command(os.Args[0], mode, "--quiet")
+	runCmd.Stdout = os.Stdout
+	runCmd.Stderr = os.Stderr
+	runCmd.Dir = dir
+	return runCmd.Run() == nil
 }
@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -26,19 +31,21 @@ var wordpressCmd = &cobra.Command{
 }
 
 var startCmd = &cobra.Command{
-	Use:   "start [file]",
-	Short: "Start WordPress in Docker",
-	Args:  cobra.MaximumNArgs(1),
+	Use:               "start [file]",
+	Short:             "Start WordPress in Docker",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completePropertiesFiles,
 	Run: func(cmd *cobra.Command, args []string) {
 		quiet, _ := cmd.Flags().GetBool("quiet")
+		manageHosts, _ := cmd.Flags().GetBool("manage-hosts")
 		if !quiet {
 			ui.PrintHeader(Version)
 		}
 
-		dir, err := os.Getwd()
+		dir, err := getProjectDir()
 		if err != nil {
-			ui.PrintError("Failed to get current directory: %v", err)
-			os.Exit(1)
+			ui.PrintError("Failed to determine project directory: %v", err)
+			exit(1)
 		}
 
 		// Determine which properties file to use
@@ -51,7 +58,7 @@ var startCmd = &cobra.Command{
 			}
 			if !config.FileExists(propsFile) {
 				ui.PrintError("Properties file not found: %s", propsFile)
-				os.Exit(1)
+				exit(1)
 			}
 		} else {
 			// Check for site.properties, wordpress.properties, then plugin/theme
@@ -71,13 +78,14 @@ var startCmd = &cobra.Command{
 			} else {
 				ui.PrintError("No properties file found")
 				ui.PrintInfo("Create site.properties, wordpress.properties, plugin.properties, or theme.properties")
-				os.Exit(1)
+				exit(1)
 			}
 		}
 
 		// Load configuration based on file type
 		var wpConfig *config.WordPressConfig
 		var dockerImage string = "wordpress:latest"
+		dbImage := config.DefaultDBImage
 		var envName string
 
 		filename := filepath.Base(propsFile)
@@ -87,31 +95,33 @@ var startCmd = &cobra.Command{
 			siteConfig, err := config.LoadSiteProperties(baseDir)
 			if err != nil {
 				ui.PrintError("Failed to load %s: %v", filename, err)
-				os.Exit(1)
+				exit(1)
 			}
 			wpConfig = siteConfig.ToWordPressConfig()
 			dockerImage = siteConfig.Image
+			dbImage = siteConfig.DBImage
 			envName = siteConfig.Name
 		case "wordpress.properties":
 			wpConfig, err = config.LoadWordPressProperties(baseDir)
 			if err != nil {
 				ui.PrintError("Failed to load %s: %v", filename, err)
-				os.Exit(1)
+				exit(1)
 			}
 			dockerImage = wpConfig.Image
+			dbImage = wpConfig.DBImage
 			envName = wpConfig.Name
 		case "plugin.properties":
 			cfg, err := config.LoadPluginProperties(baseDir)
 			if err != nil {
 				ui.PrintError("Failed to load %s: %v", filename, err)
-				os.Exit(1)
+				exit(1)
 			}
 			envName = cfg.Name
 		case "theme.properties":
 			cfg, err := config.LoadThemeProperties(baseDir)
 			if err != nil {
 				ui.PrintError("Failed to load %s: %v", filename, err)
-				os.Exit(1)
+				exit(1)
 			}
 			envName = cfg.Name
 		default:
@@ -119,9 +129,10 @@ var startCmd = &cobra.Command{
 			wpConfig, err = config.LoadWordPressProperties(baseDir)
 			if err != nil {
 				ui.PrintError("Failed to load %s: %v", propsFile, err)
-				os.Exit(1)
+				exit(1)
 			}
 			dockerImage = wpConfig.Image
+			dbImage = wpConfig.DBImage
 			envName = wpConfig.Name
 		}
 
@@ -147,10 +158,25 @@ var startCmd = &cobra.Command{
 
 		pluginSlug := sanitizePluginName(envName)
 
+		labelFlags, _ := cmd.Flags().GetStringArray("label")
+		labels, err := config.ParseLabelList(labelFlags)
+		if err != nil {
+			ui.PrintError("%v", err)
+			exit(1)
+		}
+		if wpConfig != nil {
+			labels = config.MergeLabels(wpConfig.Labels, labels)
+		}
+
+		openPref := resolveOpenPreference(cmd, dir)
+		credentials := environmentCredentials(wpConfig)
+		multisite := wpConfig != nil && wpConfig.Multisite
+		subdomains := multisite && wpConfig.Subdomains
+
 		if !isCommandAvailable("docker") {
 			ui.PrintError("Docker is not installed or not in PATH")
 			ui.PrintInfo("Please install Docker: https://docs.docker.com/get-docker/")
-			os.Exit(1)
+			exit(1)
 		}
 
 		if isContainerRunning(pluginSlug + "-wordpress") {
@@ -158,18 +184,40 @@ var startCmd = &cobra.Command{
 			wpPort := getContainerPort(pluginSlug + "-wordpress")
 			if wpPort != "" {
 				wpURL := "http://localhost:" + wpPort
+				if wpConfig != nil && wpConfig.Hostname != "" {
+					wpURL = fmt.Sprintf("http://%s:%s", wpConfig.Hostname, wpPort)
+				}
 				ui.PrintInfo("WordPress: %s", ui.Highlight(wpURL))
 				ui.PrintInfo("Admin:     %s", ui.Highlight(wpURL+"/wp-admin"))
 				fmt.Println()
-				openBrowser(wpURL)
-				openBrowser(wpURL + "/wp-admin")
+				openURLs(wpURL, openPref)
 			}
-			os.Exit(0)
+			exit(0)
 		}
 
 		if containerExists(pluginSlug + "-wordpress") {
+			// Stopped containers keep the ports they were created with
+			if wpConfig != nil {
+				for _, p := range []struct {
+					key, container, containerPort string
+					want                          int
+				}{
+					{"port", pluginSlug + "-wordpress", "80", wpConfig.Port},
+					{"db-port", pluginSlug + "-mysql", "3306", wpConfig.DBPort},
+				} {
+					if bound := boundPort(p.container, p.containerPort); p.want != 0 && bound != "" && bound != fmt.Sprintf("%d", p.want) {
+						ui.PrintError("The existing environment [%s] uses port %s, but %s in %s is %d", pluginSlug, bound, p.key, filepath.Base(propsFile), p.want)
+						ui.PrintInfo("Run 'wordsmith wordpress stop' to remove the containers (data is kept), then start again")
+						exit(1)
+					}
+				}
+			}
+
 			ui.PrintInfo("Starting existing WordPress environment [%s]...", pluginSlug)
 			exec.Command("docker", "start", pluginSlug+"-mysql").Run()
+			if wpConfig != nil && wpConfig.Redis {
+				exec.Command("docker", "start", pluginSlug+"-redis").Run()
+			}
 			exec.Command("docker", "start", pluginSlug+"-wordpress").Run()
 
 			wpPort := getContainerPort(pluginSlug + "-wordpress")
@@ -183,43 +231,78 @@ var startCmd = &cobra.Command{
 				ui.PrintInfo("Installing WordPress...")
 				port := 0
 				fmt.Sscanf(wpPort, "%d", &port)
-				if err := installWordPress(pluginSlug, port, envName); err != nil {
+				if err := installWordPress(pluginSlug, port, envName, credentials, multisite, subdomains); err != nil {
 					ui.PrintWarning("Auto-install failed: %v", err)
 				}
 			}
 
+			if wpConfig != nil && wpConfig.Hostname != "" {
+				fmt.Println()
+				hostURL, err := applyHostname(pluginSlug, wpConfig.Hostname, wpPort, manageHosts)
+				if err != nil {
+					ui.PrintWarning("%v", err)
+				} else {
+					wpURL = hostURL
+				}
+			}
+
 			fmt.Println()
 			ui.PrintSuccess("WordPress is running!")
 			fmt.Println()
 			ui.PrintInfo("WordPress: %s", ui.Highlight(wpURL))
 			ui.PrintInfo("Admin:     %s", ui.Highlight(wpURL+"/wp-admin"))
-			ui.PrintInfo("Username:  %s", ui.Highlight("admin"))
-			ui.PrintInfo("Password:  %s", ui.Highlight("admin"))
+			ui.PrintInfo("Username:  %s", ui.Highlight(credentials.AdminUser))
+			ui.PrintInfo("Password:  %s", ui.Highlight(credentials.AdminPassword))
 			fmt.Println()
-			openBrowser(wpURL)
-			openBrowser(wpURL + "/wp-admin")
-			os.Exit(0)
+			openURLs(wpURL, openPref)
+			exit(0)
 		}
 
 		ui.PrintInfo("Starting WordPress environment [%s]...", pluginSlug)
 
-		wpPort := findAvailablePort(8080, 8099)
-		if wpPort == 0 {
+		// Reuse the ports from the last start when they're free, so bookmarks keep working
+		state, err := config.LoadState(dir)
+		if err != nil {
+			ui.PrintWarning("%v", err)
+			state = &config.State{}
+		}
+
+		// A port set in the properties file is used exactly, never swapped for another
+		var wpPort, mysqlPort int
+		if wpConfig != nil && wpConfig.Port != 0 {
+			if !isPortAvailable(wpConfig.Port) {
+				ui.PrintError("Port %d (port in %s) is already in use", wpConfig.Port, filepath.Base(propsFile))
+				exit(1)
+			}
+			wpPort = wpConfig.Port
+		} else if wpPort = choosePort(state.WordPressPort, 8080, 8099); wpPort == 0 {
 			ui.PrintError("No available ports in range 8080-8099")
-			os.Exit(1)
+			exit(1)
 		}
 
-		mysqlPort := findAvailablePort(3306, 3399)
-		if mysqlPort == 0 {
+		if wpConfig != nil && wpConfig.DBPort != 0 {
+			if !isPortAvailable(wpConfig.DBPort) {
+				ui.PrintError("Port %d (db-port in %s) is already in use", wpConfig.DBPort, filepath.Base(propsFile))
+				exit(1)
+			}
+			mysqlPort = wpConfig.DBPort
+		} else if mysqlPort = choosePort(state.MySQLPort, 3306, 3399); mysqlPort == 0 {
 			ui.PrintError("No available ports in range 3306-3399")
-			os.Exit(1)
+			exit(1)
 		}
 
 		fmt.Printf("\033[38;2;59;130;246m• Using ports - WordPress: \033[0m%s\033[38;2;59;130;246m, MySQL: \033[0m%s\n", ui.Highlight(fmt.Sprintf("%d", wpPort)), ui.Highlight(fmt.Sprintf("%d", mysqlPort)))
 
-		if err := startContainers(pluginSlug, dir, wpPort, mysqlPort, dockerImage); err != nil {
+		if err := startContainers(pluginSlug, baseDir, wpPort, mysqlPort, dockerImage, dbImage, wpConfig, labels); err != nil {
 			ui.PrintError("Failed to start containers: %v", err)
-			os.Exit(1)
+			exit(1)
+		}
+
+		if wpPort != state.WordPressPort || mysqlPort != state.MySQLPort {
+			state.WordPressPort, state.MySQLPort = wpPort, mysqlPort
+			if err := state.Save(dir); err != nil {
+				ui.PrintWarning("Failed to save ports: %v", err)
+			}
 		}
 
 		fmt.Println()
@@ -228,11 +311,20 @@ var startCmd = &cobra.Command{
 		wpURL := fmt.Sprintf("http://localhost:%d", wpPort)
 		if !waitForWordPress(wpURL, 60) {
 			ui.PrintWarning("WordPress took too long to start, but containers are running")
+			ui.PrintInfo("Run 'wordsmith wordpress logs' to see what it's doing")
+		}
+
+		if wpConfig != nil && len(wpConfig.PHPExtensions) > 0 {
+			fmt.Println()
+			installPHPExtensions(pluginSlug, wpConfig.PHPExtensions)
+		}
+		if wpConfig != nil && (wpConfig.UploadLimit != "" || wpConfig.MemoryLimit != "") {
+			applyPHPLimits(pluginSlug, wpConfig.UploadLimit, wpConfig.MemoryLimit)
 		}
 
 		if needsInstall(wpURL) {
 			ui.PrintInfo("Installing WordPress...")
-			if err := installWordPress(pluginSlug, wpPort, envName); err != nil {
+			if err := installWordPress(pluginSlug, wpPort, envName, credentials, multisite, subdomains); err != nil {
 				ui.PrintWarning("Auto-install failed: %v", err)
 				ui.PrintInfo("You may need to complete setup manually")
 			}
@@ -241,6 +333,12 @@ var startCmd = &cobra.Command{
 		// Install plugins and themes from wordpress.properties
 		if wpConfig != nil {
 			baseDir := filepath.Dir(propsFile)
+			// Shared plugins and themes are fetched only here, so an unreachable list
+			// doesn't stop the environment from starting
+			if err := wpConfig.ApplyPluginsFrom(); err != nil {
+				fmt.Println()
+				ui.PrintWarning("%v, installing only the plugins and themes in the properties file", err)
+			}
 			if len(wpConfig.Plugins) > 0 {
 				fmt.Println()
 				ui.PrintInfo("Installing plugins...")
@@ -252,24 +350,41 @@ var startCmd = &cobra.Command{
 			}
 		}
 
+		// Enable the Redis object cache
+		if wpConfig != nil && wpConfig.Redis {
+			fmt.Println()
+			enableRedisCache(pluginSlug)
+		}
+
+		// Point the site at a custom hostname
+		if wpConfig != nil && wpConfig.Hostname != "" {
+			fmt.Println()
+			hostURL, err := applyHostname(pluginSlug, wpConfig.Hostname, fmt.Sprintf("%d", wpPort), manageHosts)
+			if err != nil {
+				ui.PrintWarning("%v", err)
+			} else {
+				wpURL = hostURL
+			}
+		}
+
 		fmt.Println()
 		ui.PrintSuccess("WordPress is running!")
 		fmt.Println()
 		ui.PrintInfo("WordPress: %s", ui.Highlight(wpURL))
 		ui.PrintInfo("Admin:     %s", ui.Highlight(wpURL+"/wp-admin"))
-		ui.PrintInfo("Username:  %s", ui.Highlight("admin"))
-		ui.PrintInfo("Password:  %s", ui.Highlight("admin"))
+		ui.PrintInfo("Username:  %s", ui.Highlight(credentials.AdminUser))
+		ui.PrintInfo("Password:  %s", ui.Highlight(credentials.AdminPassword))
 		fmt.Println()
 
-		openBrowser(wpURL)
-		openBrowser(wpURL + "/wp-admin")
+		openURLs(wpURL, openPref)
 	},
 }
 
 var stopCmd = &cobra.Command{
-	Use:   "stop [name]",
-	Short: "Stop WordPress Docker environment",
-	Args:  cobra.MaximumNArgs(1),
+	Use:               "stop [name]",
+	Short:             "Stop WordPress Docker environment",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
 	Run: func(cmd *cobra.Command, args []string) {
 		ui.PrintHeader(Version)
 
@@ -284,14 +399,14 @@ var stopCmd = &cobra.Command{
 				pluginSlug = instance
 			} else {
 				ui.PrintError("WordPress container '%s' not found", instance)
-				os.Exit(1)
+				exit(1)
 			}
 		} else {
 			// Get from properties files
-			dir, err := os.Getwd()
+			dir, err := getProjectDir()
 			if err != nil {
-				ui.PrintError("Failed to get current directory: %v", err)
-				os.Exit(1)
+				ui.PrintError("Failed to determine project directory: %v", err)
+				exit(1)
 			}
 
 			var name string
@@ -301,14 +416,14 @@ var stopCmd = &cobra.Command{
 				siteConfig, err := config.LoadSiteProperties(dir)
 				if err != nil {
 					ui.PrintError("Failed to load site.properties: %v", err)
-					os.Exit(1)
+					exit(1)
 				}
 				name = siteConfig.Name
 			} else if config.WordPressExists(dir) {
 				wpConfig, err := config.LoadWordPressProperties(dir)
 				if err != nil {
 					ui.PrintError("Failed to load wordpress.properties: %v", err)
-					os.Exit(1)
+					exit(1)
 				}
 				name = wpConfig.Name
 			}
@@ -319,14 +434,14 @@ var stopCmd = &cobra.Command{
 					cfg, err := config.LoadPluginProperties(dir)
 					if err != nil {
 						ui.PrintError("Failed to load plugin.properties: %v", err)
-						os.Exit(1)
+						exit(1)
 					}
 					name = cfg.Name
 				} else if config.ThemeExists(dir) {
 					cfg, err := config.LoadThemeProperties(dir)
 					if err != nil {
 						ui.PrintError("Failed to load theme.properties: %v", err)
-						os.Exit(1)
+						exit(1)
 					}
 					name = cfg.Name
 				}
@@ -335,7 +450,7 @@ var stopCmd = &cobra.Command{
 			if name == "" {
 				ui.PrintError("No site.properties, wordpress.properties, plugin.properties, or theme.properties found in current directory")
 				ui.PrintInfo("Specify instance name: wordsmith wordpress stop <name>")
-				os.Exit(1)
+				exit(1)
 			}
 
 			pluginSlug = sanitizePluginName(name)
@@ -345,40 +460,1276 @@ var stopCmd = &cobra.Command{
 
 		stopContainer(pluginSlug + "-wordpress")
 		stopContainer(pluginSlug + "-mysql")
+		stopContainer(pluginSlug + "-redis")
 
 		removeContainer(pluginSlug + "-wordpress")
 		removeContainer(pluginSlug + "-mysql")
+		removeContainer(pluginSlug + "-redis")
 
 		ui.PrintSuccess("WordPress stopped")
 		fmt.Println()
 	},
 }
 
+var logsCmd = &cobra.Command{
+	Use:               "logs [name]",
+	Short:             "Follow the container logs of an environment",
+	Long:              "Stream the logs of the WordPress container (or MySQL with --mysql) until interrupted, e.g. to see why WordPress is slow to start",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		mysql, _ := cmd.Flags().GetBool("mysql")
+		tail, _ := cmd.Flags().GetString("tail")
+
+		pluginSlug := resolveInstance(args, "wordsmith wordpress logs <name>")
+		container := pluginSlug + "-wordpress"
+		if mysql {
+			container = pluginSlug + "-mysql"
+		}
+		if !containerExists(container) {
+			ui.PrintError("Container '%s' not found", container)
+			ui.PrintInfo("Run 'wordsmith wordpress start' first")
+			exit(1)
+		}
+
+		// Streamed as-is so the output can be piped or grepped; Ctrl+C stops following
+		logs := exec.Command("docker", "logs", "--follow", "--tail", tail, container)
+		logs.Stdout = os.Stdout
+		logs.Stderr = os.Stderr
+		if err := logs.Run(); err != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok && !exitErr.Exited() {
+				return // interrupted
+			}
+			ui.PrintError("Failed to read logs of %s: %v", container, err)
+			exit(1)
+		}
+	},
+}
+
+var shellCmd = &cobra.Command{
+	Use:               "shell [name]",
+	Short:             "Open a shell in the WordPress container",
+	Long:              "Open an interactive shell (bash, or sh if the image has no bash) in the running WordPress container, or with --wp a shell in a WP-CLI container connected to the environment's files and database",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		wp, _ := cmd.Flags().GetBool("wp")
+
+		pluginSlug := resolveInstance(args, "wordsmith wordpress shell <name>")
+		requireRunning(pluginSlug)
+
+		var shell *exec.Cmd
+		if wp {
+			shell = exec.Command("docker", append(wpCLIRunArgs(pluginSlug), "-it", "wordpress:cli", "bash")...)
+		} else {
+			container := pluginSlug + "-wordpress"
+			shell = exec.Command("docker", "exec", "-it", container, containerShell(container))
+		}
+		shell.Stdin = os.Stdin
+		shell.Stdout = os.Stdout
+		shell.Stderr = os.Stderr
+		if err := shell.Run(); err != nil {
+			// The shell's own exit status, e.g. from its last command, is passed on quietly
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				exit(exitErr.ExitCode())
+			}
+			ui.PrintError("Failed to open a shell in %s: %v", pluginSlug, err)
+			exit(1)
+		}
+	},
+}
+
+// containerShell returns bash if a container has it, or sh otherwise
+func containerShell(container string) string {
+	if exec.Command("docker", "exec", container, "sh", "-c", "command -v bash").Run() == nil {
+		return "bash"
+	}
+	return "sh"
+}
+
+var pauseCmd = &cobra.Command{
+	Use:               "pause [name]",
+	Short:             "Freeze a WordPress environment without stopping it",
+	Long:              "Pause the WordPress, MySQL, and Redis containers of an environment with docker pause. They keep their state and ports but use no CPU until 'wordsmith wordpress unpause'.",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		ui.PrintHeader(Version)
+		pluginSlug := resolveInstance(args, "wordsmith wordpress pause <name>")
+		setPaused(pluginSlug, true)
+		fmt.Println()
+	},
+}
+
+var unpauseCmd = &cobra.Command{
+	Use:               "unpause [name]",
+	Short:             "Resume a paused WordPress environment",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		ui.PrintHeader(Version)
+		pluginSlug := resolveInstance(args, "wordsmith wordpress unpause <name>")
+		setPaused(pluginSlug, false)
+		fmt.Println()
+	},
+}
+
+// setPaused pauses or unpauses an environment's running containers, skipping
+// those already in the requested state
+func setPaused(pluginSlug string, pause bool) {
+	action, done := "unpause", "resumed"
+	if pause {
+		action, done = "pause", "paused"
+	}
+
+	changed := 0
+	for _, name := range []string{pluginSlug + "-wordpress", pluginSlug + "-mysql", pluginSlug + "-redis"} {
+		if !isContainerRunning(name) || isContainerPaused(name) == pause {
+			continue
+		}
+		if output, err := exec.Command("docker", action, name).CombinedOutput(); err != nil {
+			ui.PrintError("Failed to %s %s: %v", action, name, commandError(err, output))
+			exit(1)
+		}
+		changed++
+	}
+
+	if changed == 0 {
+		if !isContainerRunning(pluginSlug + "-wordpress") {
+			ui.PrintError("WordPress [%s] is not running", pluginSlug)
+			exit(1)
+		}
+		ui.PrintInfo("WordPress [%s] is already %s", pluginSlug, done)
+		return
+	}
+	ui.PrintSuccess("WordPress [%s] %s", pluginSlug, done)
+}
+
+// isContainerPaused checks if a container is frozen with docker pause
+func isContainerPaused(name string) bool {
+	output, err := exec.Command("docker", "inspect", "-f", "{{.State.Paused}}", name).Output()
+	return err == nil && strings.TrimSpace(string(output)) == "true"
+}
+
 var browseCmd = &cobra.Command{
-	Use:   "browse [admin]",
-	Short: "Open WordPress in browser",
+	Use:       "browse [admin]",
+	Short:     "Open WordPress in browser",
+	ValidArgs: []string{"admin"},
 	Run: func(cmd *cobra.Command, args []string) {
 		pluginSlug := getProjectSlug()
 
-		if !isContainerRunning(pluginSlug + "-wordpress") {
-			ui.PrintError("WordPress is not running. Run 'wordsmith wordpress start' first")
-			os.Exit(1)
-		}
+		if !isContainerRunning(pluginSlug + "-wordpress") {
+			ui.PrintError("WordPress is not running. Run 'wordsmith wordpress start' first")
+			exit(1)
+		}
+
+		wpPort := getContainerPort(pluginSlug + "-wordpress")
+		if wpPort == "" {
+			ui.PrintError("Could not determine WordPress port")
+			exit(1)
+		}
+
+		wpURL := "http://localhost:" + wpPort
+
+		if len(args) > 0 && args[0] == "admin" {
+			openBrowser(wpURL + "/wp-admin")
+		} else {
+			openBrowser(wpURL)
+		}
+	},
+}
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy <hostname> [name]",
+	Short: "Serve WordPress on a custom hostname",
+	Long:  "Point the WordPress site URL at a custom hostname (e.g. myplugin.local) and print the /etc/hosts entry to resolve it",
+	Args:  cobra.RangeArgs(1, 2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 1 {
+			return completeEnvironments(cmd, nil, toComplete)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		manageHosts, _ := cmd.Flags().GetBool("manage-hosts")
+		if !quiet {
+			ui.PrintHeader(Version)
+		}
+
+		hostname := args[0]
+		pluginSlug := resolveInstance(args[1:], "wordsmith wordpress proxy <hostname> <name>")
+		requireRunning(pluginSlug)
+
+		wpPort := getContainerPort(pluginSlug + "-wordpress")
+		if wpPort == "" {
+			ui.PrintError("Could not determine WordPress port")
+			exit(1)
+		}
+
+		wpURL, err := applyHostname(pluginSlug, hostname, wpPort, manageHosts)
+		if err != nil {
+			ui.PrintError("%v", err)
+			exit(1)
+		}
+
+		fmt.Println()
+		ui.PrintSuccess("WordPress [%s] now uses %s", pluginSlug, hostname)
+		ui.PrintInfo("WordPress: %s", ui.Highlight(wpURL))
+		ui.PrintInfo("Admin:     %s", ui.Highlight(wpURL+"/wp-admin"))
+		fmt.Println()
+	},
+}
+
+var switchThemeCmd = &cobra.Command{
+	Use:   "switch-theme [slug] [name]",
+	Short: "Switch the active theme",
+	Long:  "Activate a theme in the running WordPress environment, installing it from WordPress.org first if needed. Defaults to the current project's theme.",
+	Args:  cobra.MaximumNArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 1 {
+			return completeEnvironments(cmd, nil, toComplete)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		if !quiet {
+			ui.PrintHeader(Version)
+		}
+
+		var slug string
+		if len(args) > 0 {
+			slug = args[0]
+		}
+		projectTheme := slug == ""
+		if projectTheme {
+			dir, err := getProjectDir()
+			if err != nil {
+				ui.PrintError("Failed to determine project directory: %v", err)
+				exit(1)
+			}
+			if !config.ThemeExists(dir) {
+				ui.PrintError("No theme.properties found in current directory")
+				ui.PrintInfo("Specify a theme: wordsmith wordpress switch-theme <slug>")
+				exit(1)
+			}
+			cfg, err := config.LoadThemeProperties(dir)
+			if err != nil {
+				ui.PrintError("Failed to load theme.properties: %v", err)
+				exit(1)
+			}
+			slug = sanitizeForDocker(cfg.Name)
+		}
+
+		var instance []string
+		if len(args) > 1 {
+			instance = args[1:]
+		}
+		pluginSlug := resolveInstance(instance, "wordsmith wordpress switch-theme <slug> <name>")
+		requireRunning(pluginSlug)
+
+		if err := wpCLICommand(pluginSlug, "theme", "is-installed", slug).Run(); err != nil {
+			if projectTheme {
+				ui.PrintError("Theme '%s' is not installed", slug)
+				ui.PrintInfo("Run 'wordsmith deploy' to install it")
+				exit(1)
+			}
+			ui.PrintInfo("Installing theme '%s' from WordPress.org...", slug)
+			if output, err := wpCLICommand(pluginSlug, "theme", "install", slug).CombinedOutput(); err != nil {
+				ui.PrintError("Failed to install theme '%s': %v", slug, commandError(err, output))
+				exit(1)
+			}
+		}
+
+		if output, err := wpCLICommand(pluginSlug, "theme", "activate", slug).CombinedOutput(); err != nil {
+			ui.PrintError("Failed to activate theme '%s': %v", slug, commandError(err, output))
+			exit(1)
+		}
+
+		ui.PrintSuccess("Switched WordPress [%s] to theme '%s'", pluginSlug, slug)
+		if !quiet {
+			fmt.Println()
+		}
+	},
+}
+
+var portCmd = &cobra.Command{
+	Use:               "port [name]",
+	Short:             "Print the published WordPress port",
+	Long:              "Print only the host port WordPress (or MySQL with --mysql) is published on, for use in scripts",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		mysql, _ := cmd.Flags().GetBool("mysql")
+
+		pluginSlug := resolveInstance(args, "wordsmith wordpress port <name>")
+
+		container, containerPort := pluginSlug+"-wordpress", "80"
+		if mysql {
+			container, containerPort = pluginSlug+"-mysql", "3306"
+		}
+		if !isContainerRunning(container) {
+			fmt.Fprintf(os.Stderr, "%s is not running\n", container)
+			exit(1)
+		}
+
+		port := getPublishedPort(container, containerPort)
+		if port == "" {
+			fmt.Fprintf(os.Stderr, "Could not determine the port for %s\n", container)
+			exit(1)
+		}
+		fmt.Println(port)
+	},
+}
+
+var sitesCmd = &cobra.Command{
+	Use:   "sites",
+	Short: "Manage sites in a multisite network",
+	Run: func(cmd *cobra.Command, args []string) {
+		ui.PrintHeader(Version)
+		cmd.Help()
+	},
+}
+
+var sitesCreateCmd = &cobra.Command{
+	Use:   "create <site-slug> [name]",
+	Short: "Create a site in the multisite network",
+	Long:  "Create an additional network site with `wp site create` in a running multisite WordPress environment",
+	Args:  cobra.RangeArgs(1, 2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 1 {
+			return completeEnvironments(cmd, nil, toComplete)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		title, _ := cmd.Flags().GetString("title")
+		if !quiet {
+			ui.PrintHeader(Version)
+		}
+
+		siteSlug := args[0]
+		pluginSlug := resolveInstance(args[1:], "wordsmith wordpress sites create <site-slug> <name>")
+		requireRunning(pluginSlug)
+		requireMultisite(pluginSlug)
+
+		wpArgs := []string{"site", "create", "--slug=" + siteSlug, "--porcelain"}
+		if title != "" {
+			wpArgs = append(wpArgs, "--title="+title)
+		}
+		output, err := wpCLICommand(pluginSlug, wpArgs...).CombinedOutput()
+		if err != nil {
+			ui.PrintError("Failed to create site '%s': %v", siteSlug, commandError(err, output))
+			exit(1)
+		}
+
+		ui.PrintSuccess("Created site '%s' (ID %s) in WordPress [%s]", siteSlug, strings.TrimSpace(string(output)), pluginSlug)
+		if !quiet {
+			fmt.Println()
+		}
+	},
+}
+
+var sitesListCmd = &cobra.Command{
+	Use:               "list [name]",
+	Short:             "List the sites in the multisite network",
+	Long:              "List the network sites in a running multisite WordPress environment using `wp site list`",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		format, _ := cmd.Flags().GetString("format")
+
+		if format != "table" && format != "json" {
+			ui.PrintError("Invalid format '%s'. Use 'table' or 'json'", format)
+			exit(1)
+		}
+
+		// JSON output is meant for scripts, so skip the header
+		if !quiet && format != "json" {
+			ui.PrintHeader(Version)
+		}
+
+		pluginSlug := resolveInstance(args, "wordsmith wordpress sites list <name>")
+		requireRunning(pluginSlug)
+		requireMultisite(pluginSlug)
+
+		wpCmd := wpCLICommand(pluginSlug, "site", "list", "--fields=blog_id,url,last_updated", "--format="+format)
+		wpCmd.Stdout = os.Stdout
+		wpCmd.Stderr = os.Stderr
+		if err := wpCmd.Run(); err != nil {
+			ui.PrintError("Failed to list sites: %v", err)
+			exit(1)
+		}
+	},
+}
+
+var cleanUploadsCmd = &cobra.Command{
+	Use:               "clean-uploads [name]",
+	Short:             "Remove files from wp-content/uploads",
+	Long:              "Remove uploaded files from a running WordPress environment, optionally only those older than a number of days, without deleting the rest of the volume",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		olderThan, _ := cmd.Flags().GetInt("older-than")
+		regenerate, _ := cmd.Flags().GetBool("regenerate")
+		force, _ := cmd.Flags().GetBool("force")
+		if !quiet {
+			ui.PrintHeader(Version)
+		}
+
+		if olderThan < 0 {
+			ui.PrintError("--older-than must be zero or more days")
+			exit(1)
+		}
+
+		pluginSlug := resolveInstance(args, "wordsmith wordpress clean-uploads <name>")
+		requireRunning(pluginSlug)
+		container := pluginSlug + "-wordpress"
+
+		// A site that has never had an upload has no uploads directory yet
+		if exec.Command("docker", "exec", container, "test", "-d", uploadsDir).Run() != nil {
+			ui.PrintInfo("No uploads to clean in WordPress [%s]", pluginSlug)
+			return
+		}
+
+		// Only stdout lists files; find's warnings go to stderr
+		findArgs := uploadsFindArgs(olderThan)
+		output, err := exec.Command("docker", append([]string{"exec", container}, findArgs...)...).Output()
+		if err != nil {
+			var stderr []byte
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				stderr = exitErr.Stderr
+			}
+			ui.PrintError("Failed to list uploads: %v", commandError(err, stderr))
+			exit(1)
+		}
+		listing := strings.TrimSpace(string(output))
+		count := 0
+		if listing != "" {
+			count = len(strings.Split(listing, "\n"))
+		}
+		if count == 0 {
+			ui.PrintInfo("No uploads to clean in WordPress [%s]", pluginSlug)
+			return
+		}
+
+		what := fmt.Sprintf("%d file(s)", count)
+		if olderThan > 0 {
+			what = fmt.Sprintf("%d file(s) older than %d day(s)", count, olderThan)
+		}
+		if !force {
+			fmt.Printf("  Delete %s from wp-content/uploads in [%s]? [y/N]: ", what, pluginSlug)
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			answer = strings.ToLower(strings.TrimSpace(answer))
+			if answer != "y" && answer != "yes" {
+				ui.PrintInfo("Cancelled")
+				return
+			}
+		}
+
+		// Delete the files, then any directories they leave empty
+		script := strings.Join(append(findArgs, "-delete"), " ") +
+			" && find " + uploadsDir + " -mindepth 1 -type d -empty -delete"
+		if output, err := exec.Command("docker", "exec", "-u", "root", container, "sh", "-c", script).CombinedOutput(); err != nil {
+			ui.PrintError("Failed to clean uploads: %v", commandError(err, output))
+			exit(1)
+		}
+		ui.PrintSuccess("Deleted %s from WordPress [%s]", what, pluginSlug)
+
+		if regenerate {
+			ui.PrintInfo("Regenerating thumbnails...")
+			if output, err := wpCLICommand(pluginSlug, "media", "regenerate", "--yes", "--only-missing").CombinedOutput(); err != nil {
+				ui.PrintWarning("Failed to regenerate thumbnails: %v", commandError(err, output))
+			}
+		}
+		if !quiet {
+			fmt.Println()
+		}
+	},
+}
+
+// uploadsDir is the uploads directory inside the WordPress container
+const uploadsDir = "/var/www/html/wp-content/uploads"
+
+// uploadsFindArgs returns the find command matching uploaded files, limited to
+// files older than olderThan days when it is positive
+func uploadsFindArgs(olderThan int) []string {
+	args := []string{"find", uploadsDir, "-type", "f"}
+	if olderThan > 0 {
+		args = append(args, "-mtime", fmt.Sprintf("+%d", olderThan-1))
+	}
+	return args
+}
+
+var whoamiCmd = &cobra.Command{
+	Use:               "whoami [name]",
+	Short:             "Show the admin login for an environment",
+	Long:              "Print the admin username, email, and login URL of a WordPress environment. The password is only printed with --show-password.",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		showPassword, _ := cmd.Flags().GetBool("show-password")
+		if !quiet {
+			ui.PrintHeader(Version)
+		}
+
+		pluginSlug := resolveInstance(args, "wordsmith wordpress whoami <name>")
+		requireRunning(pluginSlug)
+
+		credentials := projectCredentials(pluginSlug)
+		user, email := credentials.AdminUser, credentials.AdminEmail
+		if output, err := wpCLICommand(pluginSlug, "user", "list", "--role=administrator", "--fields=user_login,user_email", "--format=csv").Output(); err == nil {
+			// The first line is the CSV header
+			lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+			if len(lines) > 1 {
+				if fields := strings.SplitN(strings.TrimSpace(lines[1]), ",", 2); len(fields) == 2 {
+					user, email = fields[0], fields[1]
+				}
+			}
+		}
+
+		siteURL := "http://localhost:" + getContainerPort(pluginSlug+"-wordpress")
+		if output, err := wpCLICommand(pluginSlug, "option", "get", "siteurl").Output(); err == nil && strings.TrimSpace(string(output)) != "" {
+			siteURL = strings.TrimSpace(string(output))
+		}
+
+		password := "(hidden, use --show-password)"
+		if showPassword {
+			password = credentials.AdminPassword + " (as set by wordsmith; changes made in wp-admin aren't tracked)"
+		}
+
+		fmt.Println()
+		ui.PrintKeyValue("Environment", pluginSlug)
+		ui.PrintKeyValue("Username", "   "+user)
+		ui.PrintKeyValue("Email", "      "+email)
+		ui.PrintKeyValue("Password", "   "+password)
+		ui.PrintKeyValue("Login", "      "+siteURL+"/wp-admin")
+		fmt.Println()
+	},
+}
+
+var copyFromCmd = &cobra.Command{
+	Use:   "copy-from <source> [name]",
+	Short: "Copy another environment's database into this one",
+	Long:  "Replace an environment's database with a copy of another environment's, then rewrite the source site URL to the target's with wp search-replace",
+	Args:  cobra.RangeArgs(1, 2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) < 2 {
+			return completeEnvironments(cmd, nil, toComplete)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		force, _ := cmd.Flags().GetBool("force")
+		if !quiet {
+			ui.PrintHeader(Version)
+		}
+
+		source := resolveInstance(args[:1], "wordsmith wordpress copy-from <source> <name>")
+		target := resolveInstance(args[1:], "wordsmith wordpress copy-from <source> <name>")
+		if source == target {
+			ui.PrintError("Source and target are the same environment [%s]", source)
+			exit(1)
+		}
+		requireRunning(source)
+		requireRunning(target)
+
+		if !force {
+			fmt.Printf("  Replace the database of [%s] with a copy of [%s]? [y/N]: ", target, source)
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			answer = strings.ToLower(strings.TrimSpace(answer))
+			if answer != "y" && answer != "yes" {
+				ui.PrintInfo("Cancelled")
+				return
+			}
+		}
+
+		sourceURL, err := siteURL(source)
+		if err != nil {
+			ui.PrintError("Failed to read the site URL of [%s]: %v", source, err)
+			exit(1)
+		}
+		targetURL, err := siteURL(target)
+		if err != nil {
+			ui.PrintError("Failed to read the site URL of [%s]: %v", target, err)
+			exit(1)
+		}
+
+		if !quiet {
+			ui.PrintInfo("Copying database from [%s] to [%s]...", source, target)
+		}
+		if err := copyDatabase(source, target); err != nil {
+			ui.PrintError("Failed to copy database: %v", err)
+			exit(1)
+		}
+
+		if sourceURL != targetURL {
+			if !quiet {
+				ui.PrintInfo("Replacing %s with %s...", sourceURL, targetURL)
+			}
+			output, err := wpCLICommand(target, "search-replace", sourceURL, targetURL, "--all-tables", "--skip-columns=guid").CombinedOutput()
+			if err != nil {
+				ui.PrintError("Failed to update site URLs: %v", commandError(err, output))
+				exit(1)
+			}
+		}
+
+		ui.PrintSuccess("Copied [%s] into WordPress [%s]", source, target)
+		if !quiet {
+			ui.PrintInfo("WordPress: %s", ui.Highlight(targetURL))
+			fmt.Println()
+		}
+	},
+}
+
+var searchReplaceCmd = &cobra.Command{
+	Use:   "search-replace <old> [new] [name]",
+	Short: "Replace a value throughout the database",
+	Long:  "Run wp search-replace in a WordPress environment, e.g. to fix URLs after a database import. <new> defaults to the URL the environment is served at (its configured hostname, or localhost, on its port); GUIDs are left alone.",
+	Args:  cobra.RangeArgs(1, 3),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 2 {
+			return completeEnvironments(cmd, nil, toComplete)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if !quiet {
+			ui.PrintHeader(Version)
+		}
+
+		var name []string
+		if len(args) > 2 {
+			name = args[2:]
+		}
+		pluginSlug := resolveInstance(name, "wordsmith wordpress search-replace <old> <new> <name>")
+		requireRunning(pluginSlug)
+
+		old := args[0]
+		var replacement string
+		if len(args) > 1 {
+			replacement = args[1]
+		}
+		if replacement == "" {
+			// Not the siteurl option, which is the stale value after an import or port change
+			port := getContainerPort(pluginSlug + "-wordpress")
+			if port == "" {
+				ui.PrintError("Failed to find the port of WordPress [%s]; pass <new> explicitly", pluginSlug)
+				exit(1)
+			}
+			host := "localhost"
+			if hostname := projectHostname(pluginSlug); hostname != "" {
+				host = hostname
+			}
+			replacement = "http://" + host + ":" + port
+		}
+		if old == replacement {
+			ui.PrintError("Nothing to replace: '%s' is the same as the replacement", old)
+			exit(1)
+		}
+
+		wpArgs := []string{"search-replace", old, replacement, "--skip-columns=guid", "--report-changed-only"}
+		if dryRun {
+			wpArgs = append(wpArgs, "--dry-run")
+		}
+		if !quiet {
+			ui.PrintInfo("Replacing %s with %s...", old, replacement)
+		}
+		output, err := wpCLICommand(pluginSlug, wpArgs...).CombinedOutput()
+		if err != nil {
+			ui.PrintError("Search-replace failed: %v", commandError(err, output))
+			exit(1)
+		}
+		if !quiet {
+			fmt.Println()
+			fmt.Print(string(output))
+			fmt.Println()
+		}
+
+		if dryRun {
+			ui.PrintSuccess("Dry run complete, nothing was changed in [%s]", pluginSlug)
+			return
+		}
+		ui.PrintSuccess("Replaced %s with %s in [%s]", old, replacement, pluginSlug)
+	},
+}
+
+var diffOptionsCmd = &cobra.Command{
+	Use:               "diff-options [name]",
+	Short:             "Compare the database options with plugin settings",
+	Long:              "Read each option in the plugin's settings with wp option get and print the ones whose database value differs from the configured value, to confirm deployed settings took or detect manual changes. With --apply the configured values are written for the options that differ.",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		apply, _ := cmd.Flags().GetBool("apply")
+		profile, _ := cmd.Flags().GetString("profile")
+		if profile == "" {
+			profile = os.Getenv("WORDSMITH_PROFILE")
+		}
+		if !quiet {
+			ui.PrintHeader(Version)
+		}
+
+		dir, err := getProjectDir()
+		if err != nil {
+			ui.PrintError("Failed to determine project directory: %v", err)
+			exit(1)
+		}
+		if !config.PluginExists(dir) {
+			ui.PrintError("No plugin.properties found in current directory")
+			exit(1)
+		}
+		cfg, err := config.LoadPluginProperties(dir)
+		if err != nil {
+			ui.PrintError("Failed to load plugin.properties: %v", err)
+			exit(1)
+		}
+		settings, err := cfg.SettingsForProfile(profile)
+		if err != nil {
+			ui.PrintError("%v", err)
+			exit(1)
+		}
+		if len(settings) == 0 {
+			ui.PrintInfo("No settings in plugin.properties")
+			return
+		}
+
+		pluginSlug := resolveInstance(args, "wordsmith wordpress diff-options <name>")
+		requireRunning(pluginSlug)
+
+		names := make([]string, 0, len(settings))
+		for name := range settings {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		differing := make(map[string]interface{})
+		for _, name := range names {
+			current, exists, err := getOption(pluginSlug, name)
+			if err != nil {
+				ui.PrintError("%v", err)
+				exit(1)
+			}
+			if exists && optionMatches(settings[name], current) {
+				continue
+			}
+			differing[name] = settings[name]
+			if !exists {
+				ui.PrintWarning("%s: not set, configured %s", name, formatOptionValue(settings[name]))
+			} else {
+				ui.PrintWarning("%s: %s, configured %s", name, formatOptionValue(current), formatOptionValue(settings[name]))
+			}
+		}
+
+		if len(differing) == 0 {
+			ui.PrintSuccess("All %d options match the settings in [%s]", len(names), pluginSlug)
+			return
+		}
+		if !apply {
+			fmt.Println()
+			ui.PrintError("%d of %d options differ from the settings", len(differing), len(names))
+			ui.PrintInfo("Run with --apply to write the configured values")
+			exit(1)
+		}
+
+		if !quiet {
+			fmt.Println()
+			ui.PrintInfo("Applying settings...")
+		}
+		if err := deployPluginSettings(differing, pluginSlug+"-network", pluginSlug, quiet); err != nil {
+			ui.PrintError("Failed to apply settings: %v", err)
+			exit(1)
+		}
+		ui.PrintSuccess("Applied %d options in [%s]", len(differing), pluginSlug)
+	},
+}
+
+// getOption reads an option with wp option get, reporting whether it exists
+func getOption(pluginSlug, name string) (interface{}, bool, error) {
+	output, err := wpCLICommand(pluginSlug, "option", "get", name, "--format=json").Output()
+	if err != nil {
+		// wp option get fails with "Could not get '<name>' option" when it doesn't exist
+		if exitErr, ok := err.(*exec.ExitError); ok && strings.Contains(string(exitErr.Stderr), "Could not get") {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to read option '%s': %w", name, err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(output, &value); err != nil {
+		return nil, false, fmt.Errorf("failed to parse option '%s': %w", name, err)
+	}
+	return value, true, nil
+}
+
+// optionMatches checks if an option's database value is the configured value.
+// Configured values are strings or nested maps of strings, so scalars are
+// compared as PHP would print them (true as 1, false and null as empty).
+func optionMatches(want, got interface{}) bool {
+	if wantMap, ok := want.(map[string]interface{}); ok {
+		gotMap, ok := got.(map[string]interface{})
+		if !ok || len(gotMap) != len(wantMap) {
+			return false
+		}
+		for key, value := range wantMap {
+			if gotValue, ok := gotMap[key]; !ok || !optionMatches(value, gotValue) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch v := got.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	case nil:
+		return fmt.Sprintf("%v", want) == ""
+	case bool:
+		if v {
+			return fmt.Sprintf("%v", want) == "1"
+		}
+		return fmt.Sprintf("%v", want) == ""
+	}
+	return fmt.Sprintf("%v", want) == fmt.Sprintf("%v", got)
+}
+
+// formatOptionValue formats an option value as JSON for printing
+func formatOptionValue(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
+
+// themeJSONOrigins are the origins WP_Theme_JSON_Resolver::get_merged_data
+// accepts; each includes the data of the origins before it
+var themeJSONOrigins = []string{"default", "blocks", "theme", "custom"}
+
+var themeJSONDumpCmd = &cobra.Command{
+	Use:               "theme-json-dump [name]",
+	Short:             "Print the active theme's resolved theme.json",
+	Long:              "Print the theme.json data WordPress computes for the active theme, merged with core defaults, block settings, and user global styles",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		origin, _ := cmd.Flags().GetString("origin")
+		valid := false
+		for _, o := range themeJSONOrigins {
+			valid = valid || o == origin
+		}
+		if !valid {
+			ui.PrintError("Invalid origin '%s'. Use %s", origin, strings.Join(themeJSONOrigins, ", "))
+			exit(1)
+		}
+
+		// The output is JSON meant for piping into jq or a file, so there's no header
+		pluginSlug := resolveInstance(args, "wordsmith wordpress theme-json-dump <name>")
+		requireRunning(pluginSlug)
+
+		script := fmt.Sprintf(`if (!class_exists('WP_Theme_JSON_Resolver')) { WP_CLI::error('theme.json needs WordPress 5.9 or later'); }
+echo wp_json_encode(WP_Theme_JSON_Resolver::get_merged_data(%s)->get_raw_data(), JSON_PRETTY_PRINT | JSON_UNESCAPED_SLASHES);`, phpString(origin))
+		output, err := wpCLICommand(pluginSlug, "eval", script).CombinedOutput()
+		if err != nil {
+			ui.PrintError("Failed to read theme.json: %v", commandError(err, output))
+			exit(1)
+		}
+		fmt.Println(strings.TrimSpace(string(output)))
+	},
+}
+
+// debugLogPathScript prints where WordPress writes its debug log, or nothing if
+// debug logging is disabled
+const debugLogPathScript = `if (!WP_DEBUG || !WP_DEBUG_LOG) { return; }
+echo is_string(WP_DEBUG_LOG) ? WP_DEBUG_LOG : WP_CONTENT_DIR . '/debug.log';`
+
+var debugLogCmd = &cobra.Command{
+	Use:               "debug-log [name]",
+	Short:             "Copy debug.log out of an environment",
+	Long:              "Copy the WordPress debug log from a running environment to the current directory (or --output), to attach to a bug report. With --clear the log is truncated afterwards.",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		output, _ := cmd.Flags().GetString("output")
+		clearLog, _ := cmd.Flags().GetBool("clear")
+		if !quiet {
+			ui.PrintHeader(Version)
+		}
+
+		pluginSlug := resolveInstance(args, "wordsmith wordpress debug-log <name>")
+		requireRunning(pluginSlug)
+		container := pluginSlug + "-wordpress"
+
+		out, err := wpCLICommand(pluginSlug, "eval", debugLogPathScript).CombinedOutput()
+		if err != nil {
+			ui.PrintError("Failed to read the debug log settings: %v", commandError(err, out))
+			exit(1)
+		}
+		logPath := strings.TrimSpace(string(out))
+		if logPath == "" {
+			ui.PrintWarning("Debug logging isn't enabled in WordPress [%s]", pluginSlug)
+			ui.PrintInfo("Enable it in wp-config.php with define('WP_DEBUG', true); and define('WP_DEBUG_LOG', true);")
+			ui.PrintInfo("or set WORDPRESS_DEBUG=1 and WORDPRESS_CONFIG_EXTRA=define('WP_DEBUG_LOG', true); in an env-file")
+			exit(1)
+		}
+
+		if err := exec.Command("docker", "exec", container, "test", "-f", logPath).Run(); err != nil {
+			ui.PrintInfo("Nothing has been logged to %s yet", logPath)
+			return
+		}
+
+		if output == "" {
+			output = path.Base(logPath)
+		}
+		if info, err := os.Stat(output); err == nil && info.IsDir() {
+			output = filepath.Join(output, path.Base(logPath))
+		}
+		if out, err := exec.Command("docker", "cp", container+":"+logPath, output).CombinedOutput(); err != nil {
+			ui.PrintError("Failed to copy %s: %v", logPath, commandError(err, out))
+			exit(1)
+		}
+		ui.PrintSuccess("Copied %s to %s", logPath, ui.Highlight(output))
+
+		if clearLog {
+			// Truncate rather than delete so the file keeps its owner and permissions
+			if out, err := exec.Command("docker", "exec", "-u", "root", container, "sh", "-c", ": > \"$1\"", "sh", logPath).CombinedOutput(); err != nil {
+				ui.PrintError("Failed to clear %s: %v", logPath, commandError(err, out))
+				exit(1)
+			}
+			ui.PrintSuccess("Cleared %s", logPath)
+		}
+		if !quiet {
+			fmt.Println()
+		}
+	},
+}
+
+var runUpgradeCmd = &cobra.Command{
+	Use:               "run-upgrade [name]",
+	Short:             "Test upgrading the plugin from an older version",
+	Long:              "Install an older version of the current plugin (--from), optionally import a database snapshot taken with it, then deploy the working copy over it and re-run its activation hooks and an optional upgrade eval, to test schema migrations such as dbDelta upgrades",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		from, _ := cmd.Flags().GetString("from")
+		snapshot, _ := cmd.Flags().GetString("snapshot")
+		eval, _ := cmd.Flags().GetString("eval")
+		if !quiet {
+			ui.PrintHeader(Version)
+		}
+
+		if from == "" {
+			ui.PrintError("Specify the version to upgrade from with --from (a WordPress.org version, zip file, or URL)")
+			exit(1)
+		}
+		if snapshot != "" && !config.FileExists(snapshot) {
+			ui.PrintError("Snapshot not found: %s", snapshot)
+			exit(1)
+		}
+
+		dir, err := getProjectDir()
+		if err != nil {
+			ui.PrintError("Failed to determine project directory: %v", err)
+			exit(1)
+		}
+		if !config.PluginExists(dir) {
+			ui.PrintError("No plugin.properties found in current directory")
+			exit(1)
+		}
+		cfg, err := config.LoadPluginProperties(dir)
+		if err != nil {
+			ui.PrintError("Failed to load plugin.properties: %v", err)
+			exit(1)
+		}
+		slug := sanitizeForDocker(cfg.Name)
+
+		pluginSlug := resolveInstance(args, "wordsmith wordpress run-upgrade <name>")
+		requireRunning(pluginSlug)
+		container := pluginSlug + "-wordpress"
+
+		// Install and activate the old version
+		ui.PrintInfo("Installing %s from %s...", slug, from)
+		// WP-CLI downloads URLs itself; local zips are copied in, anything else is a WordPress.org version
+		installArgs := []string{"plugin", "install", from, "--force", "--activate"}
+		isURL := strings.HasPrefix(from, "http://") || strings.HasPrefix(from, "https://")
+		zipPath := ""
+		if !isURL && config.FileExists(from) {
+			zipPath, err = copyIntoEnvironment(container, from)
+			if err != nil {
+				ui.PrintError("%v", err)
+				exit(1)
+			}
+			installArgs[2] = zipPath
+		} else if !isURL {
+			installArgs = []string{"plugin", "install", slug, "--version=" + from, "--force", "--activate"}
+		}
+		out, err := wpCLICommand(pluginSlug, installArgs...).CombinedOutput()
+		if zipPath != "" {
+			removeFromEnvironment(container, zipPath)
+		}
+		if err != nil {
+			ui.PrintError("Failed to install %s from %s: %v", slug, from, commandError(err, out))
+			exit(1)
+		}
+
+		// Import the database as it was with the old version
+		if snapshot != "" {
+			ui.PrintInfo("Importing snapshot %s...", snapshot)
+			sqlPath, err := copyIntoEnvironment(container, snapshot)
+			if err != nil {
+				ui.PrintError("%v", err)
+				exit(1)
+			}
+			out, err := wpCLICommand(pluginSlug, "db", "import", sqlPath).CombinedOutput()
+			removeFromEnvironment(container, sqlPath)
+			if err != nil {
+				ui.PrintError("Failed to import %s: %v", snapshot, commandError(err, out))
+				exit(1)
+			}
+		}
+
+		// Deploy the working copy over the old version
+		if !quiet {
+			fmt.Println()
+		}
+		b := builder.New(dir)
+		b.Quiet = quiet
+		if err := b.Build(); err != nil {
+			ui.PrintError("Build failed: %v", err)
+			exit(1)
+		}
+		if !quiet {
+			fmt.Println()
+		}
+		ui.PrintInfo("Deploying %s %s...", slug, b.Version.String())
+		if err := replaceInContainer(container, b.GetStagePath(), "/var/www/html/wp-content/plugins/"+slug); err != nil {
+			ui.PrintError("Failed to deploy: %v", err)
+			exit(1)
+		}
+
+		// Re-run the activation hooks, where plugins usually check their schema version
+		ui.PrintInfo("Running activation hooks...")
+		wpCLICommand(pluginSlug, "plugin", "deactivate", slug).Run()
+		if out, err := wpCLICommand(pluginSlug, "plugin", "activate", slug).CombinedOutput(); err != nil {
+			ui.PrintError("Failed to activate %s: %v", slug, commandError(err, out))
+			exit(1)
+		}
+
+		if eval != "" {
+			ui.PrintInfo("Running upgrade eval...")
+			out, err := wpCLICommand(pluginSlug, "eval", eval).CombinedOutput()
+			if err != nil {
+				ui.PrintError("Upgrade eval failed: %v", commandError(err, out))
+				exit(1)
+			}
+			if output := strings.TrimSpace(string(out)); output != "" {
+				fmt.Println(output)
+			}
+		}
+
+		if !quiet {
+			fmt.Println()
+		}
+		ui.PrintSuccess("Upgraded %s from %s to %s in [%s]", slug, from, b.Version.String(), pluginSlug)
+		if !quiet {
+			fmt.Println()
+		}
+	},
+}
+
+// copyIntoEnvironment copies a local file into an environment's WordPress volume,
+// where WP-CLI containers can read it, and returns its path there
+func copyIntoEnvironment(container, localPath string) (string, error) {
+	containerPath := "/var/www/html/" + config.TempPrefix + filepath.Base(localPath)
+	if out, err := exec.Command("docker", "cp", localPath, container+":"+containerPath).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to copy %s into the environment: %w", localPath, commandError(err, out))
+	}
+	return containerPath, nil
+}
+
+// removeFromEnvironment removes a file copied by copyIntoEnvironment
+func removeFromEnvironment(container, containerPath string) {
+	exec.Command("docker", "exec", container, "rm", "-f", containerPath).Run()
+}
+
+var mailtoCmd = &cobra.Command{
+	Use:               "mailto [name]",
+	Short:             "Send a test email from an environment",
+	Long:              "Send a test message with wp_mail to check that mail from a WordPress environment is delivered. The recipient defaults to the admin email.\n\nThe message is sent from a WP-CLI container that shares the environment's files and database, so SMTP plugins and mail settings in wp-config.php apply. PHP's own mail setup and the env-file variables of the WordPress container do not.",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		to, _ := cmd.Flags().GetString("to")
+		subject, _ := cmd.Flags().GetString("subject")
+		if !quiet {
+			ui.PrintHeader(Version)
+		}
+
+		pluginSlug := resolveInstance(args, "wordsmith wordpress mailto <name>")
+		requireRunning(pluginSlug)
+
+		if to == "" {
+			to = config.DefaultAdminEmail
+			if output, err := wpCLICommand(pluginSlug, "option", "get", "admin_email").Output(); err == nil && strings.TrimSpace(string(output)) != "" {
+				to = strings.TrimSpace(string(output))
+			}
+		}
+		message := fmt.Sprintf("This is a test email from the wordsmith environment [%s], sent at %s.", pluginSlug, time.Now().Format(time.RFC1123))
+
+		if !quiet {
+			ui.PrintInfo("Sending test email to %s...", to)
+		}
+		output, err := wpCLICommand(pluginSlug, "eval", mailTestScript(to, subject, message)).CombinedOutput()
+		if err != nil {
+			ui.PrintError("Failed to send test email: %v", commandError(err, output))
+			exit(1)
+		}
+
+		ui.PrintSuccess("Sent test email to %s", to)
+		if !quiet {
+			// wp_mail only reports that PHP handed the message off, not that it arrived
+			ui.PrintInfo("Check the inbox of %s to confirm it was delivered", to)
+			fmt.Println()
+		}
+	},
+}
+
+// mailTestScript returns PHP for wp eval that sends a message with wp_mail and
+// exits non-zero with the wp_mail_failed error if it isn't accepted
+func mailTestScript(to, subject, message string) string {
+	return fmt.Sprintf(`$error = 'wp_mail returned false';
+add_action('wp_mail_failed', function ($e) use (&$error) { $error = $e->get_error_message(); });
+if (!wp_mail(%s, %s, %s)) { WP_CLI::error($error); }`, phpString(to), phpString(subject), phpString(message))
+}
+
+// phpString quotes s as a single-quoted PHP string literal
+func phpString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+// siteURL returns an environment's siteurl option
+func siteURL(pluginSlug string) (string, error) {
+	output, err := wpCLICommand(pluginSlug, "option", "get", "siteurl").CombinedOutput()
+	if err != nil {
+		return "", commandError(err, output)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// mariadbTools maps the MySQL client tools to the names MariaDB 11+ images ship
+// them under, since those images no longer include the mysql* names
+var mariadbTools = map[string]string{
+	"mysql":      "mariadb",
+	"mysqladmin": "mariadb-admin",
+	"mysqldump":  "mariadb-dump",
+}
+
+// dbToolCommand builds a docker exec command that runs a MySQL client tool in a
+// database container, using the MariaDB name of the tool when the image has it.
+// stdin is attached so the command can be fed a dump.
+func dbToolCommand(container, tool string, args ...string) *exec.Cmd {
+	script := `if command -v "$1" >/dev/null 2>&1; then tool="$1"; else tool="$2"; fi; shift 2; exec "$tool" "$@"`
+	dockerArgs := []string{"exec", "-i", container, "sh", "-c", script, "sh", mariadbTools[tool], tool}
+	return exec.Command("docker", append(dockerArgs, args...)...)
+}
+
+// copyDatabase streams a mysqldump of the source environment's database into
+// the target environment's database
+func copyDatabase(source, target string) error {
+	exportCmd := dbToolCommand(source+"-mysql",
+		"mysqldump", "-uroot", "-prootpassword", "--single-transaction", instanceCredentials(source).DBName)
+	importCmd := dbToolCommand(target+"-mysql",
+		"mysql", "-uroot", "-prootpassword", instanceCredentials(target).DBName)
+
+	dump, err := exportCmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	importCmd.Stdin = dump
+
+	var exportErr, importErr strings.Builder
+	exportCmd.Stderr = &exportErr
+	importCmd.Stderr = &importErr
+
+	if err := exportCmd.Start(); err != nil {
+		return fmt.Errorf("failed to export database: %w", err)
+	}
+	if err := importCmd.Run(); err != nil {
+		exportCmd.Process.Kill()
+		exportCmd.Wait()
+		return fmt.Errorf("failed to import database: %w", commandError(err, []byte(importErr.String())))
+	}
+	if err := exportCmd.Wait(); err != nil {
+		return fmt.Errorf("failed to export database: %w", commandError(err, []byte(exportErr.String())))
+	}
+	return nil
+}
+
+var listPluginsCmd = &cobra.Command{
+	Use:               "list-plugins [name]",
+	Short:             "List plugins installed in WordPress",
+	Long:              "List the plugins installed in the running WordPress environment using WP-CLI",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		runWPList(cmd, args, "plugin", "wordsmith wordpress list-plugins <name>")
+	},
+}
+
+var listThemesCmd = &cobra.Command{
+	Use:               "list-themes [name]",
+	Short:             "List themes installed in WordPress",
+	Long:              "List the themes installed in the running WordPress environment using WP-CLI",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	Run: func(cmd *cobra.Command, args []string) {
+		runWPList(cmd, args, "theme", "wordsmith wordpress list-themes <name>")
+	},
+}
+
+// runWPList runs `wp plugin list` or `wp theme list` against an environment
+func runWPList(cmd *cobra.Command, args []string, kind, usage string) {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	format, _ := cmd.Flags().GetString("format")
+	active, _ := cmd.Flags().GetBool("active")
+
+	if format != "table" && format != "json" {
+		ui.PrintError("Invalid format '%s'. Use 'table' or 'json'", format)
+		exit(1)
+	}
+
+	// JSON output is meant for scripts, so skip the header
+	if !quiet && format != "json" {
+		ui.PrintHeader(Version)
+	}
 
-		wpPort := getContainerPort(pluginSlug + "-wordpress")
-		if wpPort == "" {
-			ui.PrintError("Could not determine WordPress port")
-			os.Exit(1)
-		}
+	pluginSlug := resolveInstance(args, usage)
+	requireRunning(pluginSlug)
 
-		wpURL := "http://localhost:" + wpPort
+	wpArgs := []string{kind, "list", "--format=" + format}
+	if active {
+		wpArgs = append(wpArgs, "--status=active")
+	}
 
-		if len(args) > 0 && args[0] == "admin" {
-			openBrowser(wpURL + "/wp-admin")
-		} else {
-			openBrowser(wpURL)
-		}
-	},
+	wpCmd := wpCLICommand(pluginSlug, wpArgs...)
+	wpCmd.Stdout = os.Stdout
+	wpCmd.Stderr = os.Stderr
+	if err := wpCmd.Run(); err != nil {
+		ui.PrintError("Failed to list %ss: %v", kind, err)
+		exit(1)
+	}
 }
 
 var psCmd = &cobra.Command{
@@ -395,7 +1746,7 @@ var psCmd = &cobra.Command{
 		output, err := dockerCmd.Output()
 		if err != nil {
 			ui.PrintError("Failed to list containers: %v", err)
-			os.Exit(1)
+			exit(1)
 		}
 
 		// Parse output and group by project
@@ -470,7 +1821,10 @@ var psCmd = &cobra.Command{
 			var wpLen int
 			var mysqlLen int
 
-			if wp.status != "" && strings.Contains(wp.status, "Up") {
+			if strings.Contains(wp.status, "(Paused)") {
+				wpStatus = "\033[36mpaused\033[0m"
+				wpLen = 6
+			} else if wp.status != "" && strings.Contains(wp.status, "Up") {
 				if wp.port != "" {
 					wpStatus = fmt.Sprintf("\033[32mrunning\033[0m \033[97m[%s]\033[0m", wp.port)
 					wpLen = 7 + 3 + len(wp.port) // "running" + " []" + port
@@ -483,7 +1837,10 @@ var psCmd = &cobra.Command{
 				wpLen = 7
 			}
 
-			if mysql.status != "" && strings.Contains(mysql.status, "Up") {
+			if strings.Contains(mysql.status, "(Paused)") {
+				mysqlStatus = "\033[36mpaused\033[0m"
+				mysqlLen = 6
+			} else if mysql.status != "" && strings.Contains(mysql.status, "Up") {
 				if mysql.port != "" {
 					mysqlStatus = fmt.Sprintf("\033[32mrunning\033[0m \033[97m[%s]\033[0m", mysql.port)
 					mysqlLen = 7 + 3 + len(mysql.port)
@@ -520,9 +1877,10 @@ var psCmd = &cobra.Command{
 }
 
 var deleteCmd = &cobra.Command{
-	Use:   "delete [name]",
-	Short: "Delete WordPress environment and all data",
-	Args:  cobra.MaximumNArgs(1),
+	Use:               "delete [name]",
+	Short:             "Delete WordPress environment and all data",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
 	Run: func(cmd *cobra.Command, args []string) {
 		ui.PrintHeader(Version)
 
@@ -533,10 +1891,10 @@ var deleteCmd = &cobra.Command{
 			pluginSlug = args[0]
 		} else {
 			// Get from properties files
-			dir, err := os.Getwd()
+			dir, err := getProjectDir()
 			if err != nil {
-				ui.PrintError("Failed to get current directory: %v", err)
-				os.Exit(1)
+				ui.PrintError("Failed to determine project directory: %v", err)
+				exit(1)
 			}
 
 			var name string
@@ -546,14 +1904,14 @@ var deleteCmd = &cobra.Command{
 				siteConfig, err := config.LoadSiteProperties(dir)
 				if err != nil {
 					ui.PrintError("Failed to load site.properties: %v", err)
-					os.Exit(1)
+					exit(1)
 				}
 				name = siteConfig.Name
 			} else if config.WordPressExists(dir) {
 				wpConfig, err := config.LoadWordPressProperties(dir)
 				if err != nil {
 					ui.PrintError("Failed to load wordpress.properties: %v", err)
-					os.Exit(1)
+					exit(1)
 				}
 				name = wpConfig.Name
 			}
@@ -564,14 +1922,14 @@ var deleteCmd = &cobra.Command{
 					cfg, err := config.LoadPluginProperties(dir)
 					if err != nil {
 						ui.PrintError("Failed to load plugin.properties: %v", err)
-						os.Exit(1)
+						exit(1)
 					}
 					name = cfg.Name
 				} else if config.ThemeExists(dir) {
 					cfg, err := config.LoadThemeProperties(dir)
 					if err != nil {
 						ui.PrintError("Failed to load theme.properties: %v", err)
-						os.Exit(1)
+						exit(1)
 					}
 					name = cfg.Name
 				}
@@ -580,7 +1938,7 @@ var deleteCmd = &cobra.Command{
 			if name == "" {
 				ui.PrintError("No site.properties, wordpress.properties, plugin.properties, or theme.properties found in current directory")
 				ui.PrintInfo("Specify instance name: wordsmith wordpress delete <name>")
-				os.Exit(1)
+				exit(1)
 			}
 
 			pluginSlug = sanitizePluginName(name)
@@ -590,14 +1948,22 @@ var deleteCmd = &cobra.Command{
 
 		stopContainer(pluginSlug + "-wordpress")
 		stopContainer(pluginSlug + "-mysql")
+		stopContainer(pluginSlug + "-redis")
 
 		removeContainer(pluginSlug + "-wordpress")
 		removeContainer(pluginSlug + "-mysql")
+		removeContainer(pluginSlug + "-redis")
 
 		exec.Command("docker", "volume", "rm", pluginSlug+"-wp").Run()
 		exec.Command("docker", "volume", "rm", pluginSlug+"-db").Run()
 		exec.Command("docker", "network", "rm", pluginSlug+"-network").Run()
 
+		if manageHosts, _ := cmd.Flags().GetBool("manage-hosts"); manageHosts {
+			if err := writeHostsEntry(pluginSlug, ""); err != nil {
+				ui.PrintWarning("Failed to update %s: %v", hostsPath, err)
+			}
+		}
+
 		ui.PrintSuccess("WordPress environment deleted")
 		fmt.Println()
 	},
@@ -605,11 +1971,78 @@ var deleteCmd = &cobra.Command{
 
 func init() {
 	startCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	startCmd.Flags().StringArray("label", nil, "Add a label to the containers (key=value, repeatable)")
+	startCmd.Flags().Bool("open-site", false, "Open only the site in the browser (remembered for this project)")
+	startCmd.Flags().Bool("open-admin", false, "Open only wp-admin in the browser (remembered for this project)")
+	startCmd.Flags().Bool("manage-hosts", false, "Add the hostname to /etc/hosts (uses sudo)")
 	wordpressCmd.AddCommand(startCmd)
 	wordpressCmd.AddCommand(stopCmd)
+	logsCmd.Flags().Bool("mysql", false, "Show the MySQL container's logs instead of WordPress")
+	logsCmd.Flags().String("tail", "all", "Number of lines to show from the end of the logs before following")
+	wordpressCmd.AddCommand(logsCmd)
+	shellCmd.Flags().Bool("wp", false, "Open a shell in a WP-CLI container instead")
+	wordpressCmd.AddCommand(shellCmd)
+	wordpressCmd.AddCommand(pauseCmd)
+	wordpressCmd.AddCommand(unpauseCmd)
 	wordpressCmd.AddCommand(psCmd)
 	wordpressCmd.AddCommand(browseCmd)
+	deleteCmd.Flags().Bool("manage-hosts", false, "Remove the hostname wordsmith added to /etc/hosts (uses sudo)")
 	wordpressCmd.AddCommand(deleteCmd)
+	proxyCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	proxyCmd.Flags().Bool("manage-hosts", false, "Add the hostname to /etc/hosts (uses sudo)")
+	wordpressCmd.AddCommand(proxyCmd)
+	portCmd.Flags().Bool("mysql", false, "Print the MySQL port instead")
+	wordpressCmd.AddCommand(portCmd)
+	switchThemeCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	wordpressCmd.AddCommand(switchThemeCmd)
+	sitesCreateCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	sitesCreateCmd.Flags().String("title", "", "Title of the new site")
+	sitesCmd.AddCommand(sitesCreateCmd)
+	sitesListCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	sitesListCmd.Flags().String("format", "table", "Output format: table or json")
+	sitesCmd.AddCommand(sitesListCmd)
+	wordpressCmd.AddCommand(sitesCmd)
+	whoamiCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	whoamiCmd.Flags().Bool("show-password", false, "Print the admin password")
+	wordpressCmd.AddCommand(whoamiCmd)
+	copyFromCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	copyFromCmd.Flags().BoolP("force", "f", false, "Replace the database without asking for confirmation")
+	wordpressCmd.AddCommand(copyFromCmd)
+	mailtoCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	mailtoCmd.Flags().String("to", "", "Recipient address (default: the site's admin email)")
+	mailtoCmd.Flags().String("subject", "wordsmith test email", "Subject line")
+	wordpressCmd.AddCommand(mailtoCmd)
+	debugLogCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	debugLogCmd.Flags().StringP("output", "o", "", "File or directory to copy the log to (default: ./debug.log)")
+	debugLogCmd.Flags().Bool("clear", false, "Truncate the log after copying it")
+	wordpressCmd.AddCommand(debugLogCmd)
+	runUpgradeCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	runUpgradeCmd.Flags().String("from", "", "Old version to upgrade from: a WordPress.org version, zip file, or URL")
+	runUpgradeCmd.Flags().String("snapshot", "", "SQL file to import after installing the old version")
+	runUpgradeCmd.Flags().String("eval", "", "PHP code run with wp eval after activation, e.g. to call the upgrade routine")
+	wordpressCmd.AddCommand(runUpgradeCmd)
+	searchReplaceCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	searchReplaceCmd.Flags().Bool("dry-run", false, "Report what would change without writing to the database")
+	wordpressCmd.AddCommand(searchReplaceCmd)
+	themeJSONDumpCmd.Flags().String("origin", "custom", "Merge up to this origin: default (core), blocks, theme, or custom (user global styles)")
+	wordpressCmd.AddCommand(themeJSONDumpCmd)
+	diffOptionsCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	diffOptionsCmd.Flags().Bool("apply", false, "Write the configured values for the options that differ")
+	diffOptionsCmd.Flags().String("profile", "", "Deploy profile whose settings are merged over the base settings (defaults to $WORDSMITH_PROFILE)")
+	wordpressCmd.AddCommand(diffOptionsCmd)
+	cleanUploadsCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	cleanUploadsCmd.Flags().Int("older-than", 0, "Only remove files older than this many days")
+	cleanUploadsCmd.Flags().Bool("regenerate", false, "Run wp media regenerate afterwards")
+	cleanUploadsCmd.Flags().BoolP("force", "f", false, "Delete without asking for confirmation")
+	wordpressCmd.AddCommand(cleanUploadsCmd)
+	listPluginsCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	listPluginsCmd.Flags().String("format", "table", "Output format: table or json")
+	listPluginsCmd.Flags().Bool("active", false, "Only list active plugins")
+	wordpressCmd.AddCommand(listPluginsCmd)
+	listThemesCmd.Flags().BoolP("quiet", "q", false, "Suppress header output")
+	listThemesCmd.Flags().String("format", "table", "Output format: table or json")
+	listThemesCmd.Flags().Bool("active", false, "Only list the active theme")
+	wordpressCmd.AddCommand(listThemesCmd)
 	rootCmd.AddCommand(wordpressCmd)
 }
 
@@ -627,10 +2060,10 @@ func sanitizePluginName(name string) string {
 
 // getProjectSlug returns the sanitized project slug from plugin.properties or theme.properties
 func getProjectSlug() string {
-	dir, err := os.Getwd()
+	dir, err := getProjectDir()
 	if err != nil {
-		ui.PrintError("Failed to get current directory: %v", err)
-		os.Exit(1)
+		ui.PrintError("Failed to determine project directory: %v", err)
+		exit(1)
 	}
 
 	isTheme := config.ThemeExists(dir)
@@ -638,7 +2071,7 @@ func getProjectSlug() string {
 
 	if !isTheme && !isPlugin {
 		ui.PrintError("No plugin.properties or theme.properties found in current directory")
-		os.Exit(1)
+		exit(1)
 	}
 
 	var name string
@@ -646,14 +2079,14 @@ func getProjectSlug() string {
 		cfg, err := config.LoadThemeProperties(dir)
 		if err != nil {
 			ui.PrintError("Failed to load theme.properties: %v", err)
-			os.Exit(1)
+			exit(1)
 		}
 		name = cfg.Name
 	} else {
 		cfg, err := config.LoadPluginProperties(dir)
 		if err != nil {
 			ui.PrintError("Failed to load plugin.properties: %v", err)
-			os.Exit(1)
+			exit(1)
 		}
 		name = cfg.Name
 	}
@@ -663,15 +2096,31 @@ func getProjectSlug() string {
 
 func findAvailablePort(start, end int) int {
 	for port := start; port <= end; port++ {
-		ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
-		if err == nil {
-			ln.Close()
+		if isPortAvailable(port) {
 			return port
 		}
 	}
 	return 0
 }
 
+// choosePort returns saved if it's set and free, otherwise the first free port in start-end
+func choosePort(saved, start, end int) int {
+	if saved != 0 && isPortAvailable(saved) {
+		return saved
+	}
+	return findAvailablePort(start, end)
+}
+
+// isPortAvailable checks if nothing is listening on a local TCP port
+func isPortAvailable(port int) bool {
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	ln.Close()
+	return true
+}
+
 func isContainerRunning(name string) bool {
 	cmd := exec.Command("docker", "ps", "-q", "-f", fmt.Sprintf("name=%s", name))
 	output, err := cmd.Output()
@@ -685,53 +2134,153 @@ func containerExists(name string) bool {
 }
 
 func getContainerPort(name string) string {
-	cmd := exec.Command("docker", "port", name, "80")
+	return getPublishedPort(name, "80")
+}
+
+// boundPort returns the host port a container was created to publish containerPort
+// on, which unlike getPublishedPort works for stopped containers
+func boundPort(name, containerPort string) string {
+	format := fmt.Sprintf(`{{range (index .HostConfig.PortBindings "%s/tcp")}}{{println .HostPort}}{{end}}`, containerPort)
+	output, err := exec.Command("docker", "inspect", "--format", format, name).Output()
+	if err != nil {
+		return ""
+	}
+	port, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(port)
+}
+
+// getPublishedPort returns the host port a container port is published on. docker port
+// prints one line per address (e.g. 0.0.0.0:8080 and [::]:8080); the first is used.
+func getPublishedPort(name, containerPort string) string {
+	cmd := exec.Command("docker", "port", name, containerPort)
 	output, err := cmd.Output()
 	if err != nil {
 		return ""
 	}
-	parts := strings.Split(strings.TrimSpace(string(output)), ":")
-	if len(parts) == 2 {
-		return parts[1]
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if idx := strings.LastIndex(line, ":"); idx != -1 && idx < len(line)-1 {
+			return line[idx+1:]
+		}
 	}
 	return ""
 }
 
-func startContainers(pluginSlug, projectDir string, wpPort, mysqlPort int, dockerImage string) error {
+// startContainers creates the network, MySQL, and WordPress containers for an environment.
+// projectDir is the directory containing the properties file and is used to resolve
+// relative paths in wpConfig, which may be nil for plain plugin/theme projects.
+// labels are added to both containers alongside the wordsmith.* labels.
+func startContainers(pluginSlug, projectDir string, wpPort, mysqlPort int, dockerImage, dbImage string, wpConfig *config.WordPressConfig, labels map[string]string) error {
+	// Check the env-file before creating anything
+	var envFile string
+	if wpConfig != nil && wpConfig.EnvFile != "" {
+		var err error
+		envFile, err = config.ResolveEnvFile(projectDir, wpConfig.EnvFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	credentials := environmentCredentials(wpConfig)
 	networkName := pluginSlug + "-network"
 	exec.Command("docker", "network", "create", networkName).Run()
 
-	mysqlCmd := exec.Command("docker", "run", "-d",
-		"--name", pluginSlug+"-mysql",
+	mysqlArgs := []string{"run", "-d",
+		"--name", pluginSlug + "-mysql",
 		"--network", networkName,
 		"-p", fmt.Sprintf("%d:3306", mysqlPort),
-		"-e", "MYSQL_DATABASE=wordpress",
-		"-e", "MYSQL_USER=wordpress",
-		"-e", "MYSQL_PASSWORD=wordpress",
+		"-e", "MYSQL_DATABASE=" + credentials.DBName,
+		"-e", "MYSQL_USER=" + credentials.DBUser,
+		"-e", "MYSQL_PASSWORD=" + credentials.DBPassword,
 		"-e", "MYSQL_ROOT_PASSWORD=rootpassword",
-		"-v", pluginSlug+"-db:/var/lib/mysql",
+		"-v", pluginSlug + "-db:/var/lib/mysql",
+	}
+
+	// Mount init SQL so MySQL runs it when the database volume is first created
+	if wpConfig != nil && wpConfig.InitSQL != "" {
+		initSQL := wpConfig.InitSQL
+		if !filepath.IsAbs(initSQL) {
+			initSQL = filepath.Join(projectDir, initSQL)
+		}
+		if !config.FileExists(initSQL) {
+			return fmt.Errorf("init-sql file not found: %s", initSQL)
+		}
+		mysqlArgs = append(mysqlArgs, "-v", initSQL+":/docker-entrypoint-initdb.d/"+filepath.Base(initSQL)+":ro")
+	}
+
+	// Restart policy (defaults to no, so environments stay down after a reboot)
+	restartPolicy := "no"
+	if wpConfig != nil && wpConfig.RestartPolicy != "" {
+		restartPolicy = wpConfig.RestartPolicy
+	}
+	mysqlArgs = append(mysqlArgs, "--restart", restartPolicy)
+
+	// Memory and CPU caps apply to each container of the environment
+	var resourceArgs []string
+	if wpConfig != nil && wpConfig.Memory != "" {
+		resourceArgs = append(resourceArgs, "--memory", wpConfig.Memory)
+	}
+	if wpConfig != nil && wpConfig.CPUs != "" {
+		resourceArgs = append(resourceArgs, "--cpus", wpConfig.CPUs)
+	}
+	mysqlArgs = append(mysqlArgs, resourceArgs...)
+
+	mysqlArgs = append(mysqlArgs, builder.LabelArgs(labels)...)
+	mysqlArgs = append(mysqlArgs,
 		"--label", "wordsmith.type=mysql",
 		"--label", "wordsmith.project="+pluginSlug,
-		"mysql:8.0",
+		dbImage,
 	)
+
+	mysqlCmd := exec.Command("docker", mysqlArgs...)
 	if err := mysqlCmd.Run(); err != nil {
 		return fmt.Errorf("failed to start MySQL: %w", err)
 	}
 
-	wpCmd := exec.Command("docker", "run", "-d",
-		"--name", pluginSlug+"-wordpress",
+	// Redis object cache server, reachable from WordPress as <slug>-redis
+	if wpConfig != nil && wpConfig.Redis {
+		redisArgs := []string{"run", "-d",
+			"--name", pluginSlug + "-redis",
+			"--network", networkName,
+			"--restart", restartPolicy,
+		}
+		redisArgs = append(redisArgs, resourceArgs...)
+		redisArgs = append(redisArgs, builder.LabelArgs(labels)...)
+		redisArgs = append(redisArgs,
+			"--label", "wordsmith.type=redis",
+			"--label", "wordsmith.project="+pluginSlug,
+			"redis:7-alpine",
+		)
+		if err := exec.Command("docker", redisArgs...).Run(); err != nil {
+			return fmt.Errorf("failed to start Redis: %w", err)
+		}
+	}
+
+	wpArgs := []string{"run", "-d",
+		"--name", pluginSlug + "-wordpress",
 		"--network", networkName,
 		"-p", fmt.Sprintf("%d:80", wpPort),
-		"-e", "WORDPRESS_DB_HOST="+pluginSlug+"-mysql",
-		"-e", "WORDPRESS_DB_USER=wordpress",
-		"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-		"-e", "WORDPRESS_DB_NAME=wordpress",
-		"-v", pluginSlug+"-wp:/var/www/html",
+		"-e", "WORDPRESS_DB_HOST=" + pluginSlug + "-mysql",
+		"-e", "WORDPRESS_DB_USER=" + credentials.DBUser,
+		"-e", "WORDPRESS_DB_PASSWORD=" + credentials.DBPassword,
+		"-e", "WORDPRESS_DB_NAME=" + credentials.DBName,
+		"-v", pluginSlug + "-wp:/var/www/html",
+		"--restart", restartPolicy,
+	}
+	wpArgs = append(wpArgs, resourceArgs...)
+
+	// Variables from the env-file; docker gives the -e database settings above precedence
+	if envFile != "" {
+		wpArgs = append(wpArgs, "--env-file", envFile)
+	}
+	wpArgs = append(wpArgs, builder.LabelArgs(labels)...)
+	wpArgs = append(wpArgs,
 		"--label", "wordsmith.type=wordpress",
 		"--label", "wordsmith.project="+pluginSlug,
 		dockerImage,
 	)
-	_ = projectDir
+
+	wpCmd := exec.Command("docker", wpArgs...)
 	if err := wpCmd.Run(); err != nil {
 		return fmt.Errorf("failed to start WordPress: %w", err)
 	}
@@ -747,6 +2296,45 @@ func removeContainer(name string) {
 	exec.Command("docker", "rm", name).Run()
 }
 
+// resolveOpenPreference returns which URLs `start` should open. The --open-site and
+// --open-admin flags override the remembered preference and are saved for next time.
+func resolveOpenPreference(cmd *cobra.Command, dir string) string {
+	state, err := config.LoadState(dir)
+	if err != nil {
+		ui.PrintWarning("%v", err)
+		state = &config.State{}
+	}
+
+	openSite, _ := cmd.Flags().GetBool("open-site")
+	openAdmin, _ := cmd.Flags().GetBool("open-admin")
+	if !openSite && !openAdmin {
+		return state.OpenPreference()
+	}
+
+	switch {
+	case openSite && openAdmin:
+		state.Open = config.OpenBoth
+	case openSite:
+		state.Open = config.OpenSite
+	default:
+		state.Open = config.OpenAdmin
+	}
+	if err := state.Save(dir); err != nil {
+		ui.PrintWarning("Failed to save open preference: %v", err)
+	}
+	return state.Open
+}
+
+// openURLs opens the site and/or admin in the browser
+func openURLs(wpURL, open string) {
+	if open != config.OpenAdmin {
+		openBrowser(wpURL)
+	}
+	if open != config.OpenSite {
+		openBrowser(wpURL + "/wp-admin")
+	}
+}
+
 func openBrowser(url string) {
 	var cmd *exec.Cmd
 	switch {
@@ -797,37 +2385,50 @@ func needsInstall(url string) bool {
 	return false
 }
 
-func installWordPress(pluginSlug string, port int, pluginName string) error {
+// installWordPress runs the WordPress installer with the admin login from admin,
+// connecting to the database with the login the environment was started with.
+// With multisite it installs a network (of subdomain sites with subdomains) and
+// network-activates the project's plugin.
+func installWordPress(pluginSlug string, port int, pluginName string, admin config.Credentials, multisite, subdomains bool) error {
 	containerName := pluginSlug + "-wordpress"
 	networkName := pluginSlug + "-network"
+	credentials := instanceCredentials(pluginSlug)
 
 	mysqlContainer := pluginSlug + "-mysql"
 	for i := 0; i < 30; i++ {
-		checkCmd := exec.Command("docker", "exec", mysqlContainer, "mysqladmin", "ping", "-h", "localhost", "-uroot", "-prootpassword", "--silent")
+		checkCmd := dbToolCommand(mysqlContainer, "mysqladmin", "ping", "-h", "localhost", "-uroot", "-prootpassword", "--silent")
 		if err := checkCmd.Run(); err == nil {
 			break
 		}
 		time.Sleep(1 * time.Second)
 	}
 
-	installCmd := exec.Command("docker", "run", "--rm",
+	// On a network, the title becomes the network's title as well as the main site's
+	installCommand := "install"
+	if multisite {
+		installCommand = "multisite-install"
+	}
+	installArgs := []string{"run", "--rm",
 		"--network", networkName,
 		"--user", "33:33",
-		"-v", pluginSlug+"-wp:/var/www/html",
-		"-e", "WORDPRESS_DB_HOST="+mysqlContainer,
-		"-e", "WORDPRESS_DB_USER=wordpress",
-		"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-		"-e", "WORDPRESS_DB_NAME=wordpress",
+		"-v", pluginSlug + "-wp:/var/www/html",
+		"-e", "WORDPRESS_DB_HOST=" + mysqlContainer,
+		"-e", "WORDPRESS_DB_USER=" + credentials.DBUser,
+		"-e", "WORDPRESS_DB_PASSWORD=" + credentials.DBPassword,
+		"-e", "WORDPRESS_DB_NAME=" + credentials.DBName,
 		"wordpress:cli",
-		"wp", "core", "install",
-		"--url=http://localhost:"+fmt.Sprintf("%d", port),
-		"--title=WordPress "+pluginName,
-		"--admin_user=admin",
-		"--admin_password=admin",
-		"--admin_email=admin@localhost.com",
+		"wp", "core", installCommand,
+		"--url=http://localhost:" + fmt.Sprintf("%d", port),
+		"--title=WordPress " + pluginName,
+		"--admin_user=" + admin.AdminUser,
+		"--admin_password=" + admin.AdminPassword,
+		"--admin_email=" + admin.AdminEmail,
 		"--skip-email",
-	)
-	output, err := installCmd.CombinedOutput()
+	}
+	if subdomains {
+		installArgs = append(installArgs, "--subdomains")
+	}
+	output, err := exec.Command("docker", installArgs...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%w: %s", err, string(output))
 	}
@@ -837,12 +2438,15 @@ func installWordPress(pluginSlug string, port int, pluginName string) error {
 		"--user", "33:33",
 		"-v", pluginSlug+"-wp:/var/www/html",
 		"-e", "WORDPRESS_DB_HOST="+mysqlContainer,
-		"-e", "WORDPRESS_DB_USER=wordpress",
-		"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-		"-e", "WORDPRESS_DB_NAME=wordpress",
+		"-e", "WORDPRESS_DB_USER="+credentials.DBUser,
+		"-e", "WORDPRESS_DB_PASSWORD="+credentials.DBPassword,
+		"-e", "WORDPRESS_DB_NAME="+credentials.DBName,
 		"wordpress:cli",
 		"wp", "plugin", "activate", pluginSlug,
 	)
+	if multisite {
+		activateCmd.Args = append(activateCmd.Args, "--network")
+	}
 	activateCmd.Run()
 
 	_ = containerName
@@ -855,9 +2459,10 @@ func installWordPress(pluginSlug string, port int, pluginName string) error {
 func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig, baseDir string) {
 	networkName := pluginSlug + "-network"
 	mysqlContainer := pluginSlug + "-mysql"
+	credentials := instanceCredentials(pluginSlug)
 
-	// Install plugins
-	for _, plugin := range wpConfig.Plugins {
+	// Install plugins, activating them in their configured order
+	for _, plugin := range config.SortPlugins(wpConfig.Plugins) {
 		// Resolve the plugin URI to determine how to install
 		resolution := config.ResolvePluginURI(baseDir, plugin)
 
@@ -868,7 +2473,7 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 
 		// Resolve GitHub repository URLs to release asset URLs
 		if resolution.ZipPath != "" && strings.Contains(resolution.ZipPath, "github.com") {
-			resolvedURL, err := config.ResolveGitHubURL(resolution.ZipPath, plugin.Slug, plugin.Version)
+			resolvedURL, err := config.ResolveGitHubURL(resolution.ZipPath, plugin.Slug, plugin.Version, plugin.Release, plugin.AssetPattern)
 			if err != nil {
 				ui.PrintError("  Failed to resolve GitHub release for '%s': %v", plugin.Slug, err)
 				continue
@@ -889,9 +2494,9 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 				continue
 			}
 
-			// Get the actual WordPress slug from the built plugin's properties
-			// This is the sanitized name that WordPress will use as the plugin directory
-			wpSlug = b.GetPluginSlug()
+			// Get the actual WordPress slug from the built plugin's properties: the
+			// directory the zip unpacks to, its zip-folder or the sanitized name
+			wpSlug = b.InstallName(b.GetPluginSlug())
 
 			// Find the built zip file
 			buildDir := filepath.Join(resolution.BuildDir, "build")
@@ -919,6 +2524,18 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 			ui.PrintInfo("  Installing plugin '%s' from build...", plugin.Slug)
 		}
 
+		// A URL with a sha256 is downloaded and checked here, then installed from the file
+		var downloadDir string
+		if isHTTPURL(resolution.ZipPath) && plugin.Sha256 != "" {
+			zipPath, err := downloadVerifiedZip(resolution.ZipPath, plugin.Sha256)
+			if err != nil {
+				ui.PrintWarning("  Failed to download plugin '%s': %v", plugin.Slug, err)
+				continue
+			}
+			downloadDir = filepath.Dir(zipPath)
+			resolution.ZipPath, resolution.IsLocal = zipPath, true
+		}
+
 		if resolution.IsLocal && resolution.ZipPath != "" {
 			// Install from local file
 			if !strings.HasPrefix(resolution.ZipPath, "http://") && !strings.HasPrefix(resolution.ZipPath, "https://") {
@@ -945,9 +2562,9 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 					"-v", pluginSlug+"-wp:/var/www/html",
 					"-v", zipDir+":"+containerMountPath+":ro",
 					"-e", "WORDPRESS_DB_HOST="+mysqlContainer,
-					"-e", "WORDPRESS_DB_USER=wordpress",
-					"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-					"-e", "WORDPRESS_DB_NAME=wordpress",
+					"-e", "WORDPRESS_DB_USER="+credentials.DBUser,
+					"-e", "WORDPRESS_DB_PASSWORD="+credentials.DBPassword,
+					"-e", "WORDPRESS_DB_NAME="+credentials.DBName,
 					"wordpress:cli",
 					"wp", "plugin", "install", containerZipPath,
 				)
@@ -959,9 +2576,9 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 					"--user", "33:33",
 					"-v", pluginSlug+"-wp:/var/www/html",
 					"-e", "WORDPRESS_DB_HOST="+mysqlContainer,
-					"-e", "WORDPRESS_DB_USER=wordpress",
-					"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-					"-e", "WORDPRESS_DB_NAME=wordpress",
+					"-e", "WORDPRESS_DB_USER="+credentials.DBUser,
+					"-e", "WORDPRESS_DB_PASSWORD="+credentials.DBPassword,
+					"-e", "WORDPRESS_DB_NAME="+credentials.DBName,
 					"wordpress:cli",
 					"wp", "plugin", "install", resolution.ZipPath,
 				)
@@ -974,9 +2591,9 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 				"--user", "33:33",
 				"-v", pluginSlug+"-wp:/var/www/html",
 				"-e", "WORDPRESS_DB_HOST="+mysqlContainer,
-				"-e", "WORDPRESS_DB_USER=wordpress",
-				"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-				"-e", "WORDPRESS_DB_NAME=wordpress",
+				"-e", "WORDPRESS_DB_USER="+credentials.DBUser,
+				"-e", "WORDPRESS_DB_PASSWORD="+credentials.DBPassword,
+				"-e", "WORDPRESS_DB_NAME="+credentials.DBName,
 				"wordpress:cli",
 				"wp", "plugin", "install", resolution.ZipPath,
 			)
@@ -988,9 +2605,9 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 				"--user", "33:33",
 				"-v", pluginSlug + "-wp:/var/www/html",
 				"-e", "WORDPRESS_DB_HOST=" + mysqlContainer,
-				"-e", "WORDPRESS_DB_USER=wordpress",
-				"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-				"-e", "WORDPRESS_DB_NAME=wordpress",
+				"-e", "WORDPRESS_DB_USER=" + credentials.DBUser,
+				"-e", "WORDPRESS_DB_PASSWORD=" + credentials.DBPassword,
+				"-e", "WORDPRESS_DB_NAME=" + credentials.DBName,
 				"wordpress:cli",
 				"wp", "plugin", "install", plugin.Slug,
 			}
@@ -1000,8 +2617,10 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 			installCmd = exec.Command("docker", installArgs...)
 		}
 
-		if err := installCmd.Run(); err != nil {
-			ui.PrintWarning("  Failed to install plugin '%s': %v", plugin.Slug, err)
+		output, err := installCmd.CombinedOutput()
+		config.RemoveTempDir(downloadDir)
+		if err != nil {
+			ui.PrintWarning("  Failed to install plugin '%s': %v", plugin.Slug, commandError(err, output))
 			continue
 		}
 
@@ -1012,13 +2631,18 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 				"--user", "33:33",
 				"-v", pluginSlug+"-wp:/var/www/html",
 				"-e", "WORDPRESS_DB_HOST="+mysqlContainer,
-				"-e", "WORDPRESS_DB_USER=wordpress",
-				"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-				"-e", "WORDPRESS_DB_NAME=wordpress",
+				"-e", "WORDPRESS_DB_USER="+credentials.DBUser,
+				"-e", "WORDPRESS_DB_PASSWORD="+credentials.DBPassword,
+				"-e", "WORDPRESS_DB_NAME="+credentials.DBName,
 				"wordpress:cli",
 				"wp", "plugin", "activate", wpSlug,
 			)
-			activateCmd.Run()
+			if wpConfig.Multisite {
+				activateCmd.Args = append(activateCmd.Args, "--network")
+			}
+			if output, err := activateCmd.CombinedOutput(); err != nil {
+				ui.PrintWarning("  Failed to activate plugin '%s': %v", wpSlug, commandError(err, output))
+			}
 		}
 	}
 
@@ -1034,7 +2658,7 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 
 		// Resolve GitHub repository URLs to release asset URLs
 		if resolution.ZipPath != "" && strings.Contains(resolution.ZipPath, "github.com") {
-			resolvedURL, err := config.ResolveGitHubURL(resolution.ZipPath, theme.Slug, theme.Version)
+			resolvedURL, err := config.ResolveGitHubURL(resolution.ZipPath, theme.Slug, theme.Version, theme.Release, theme.AssetPattern)
 			if err != nil {
 				ui.PrintError("  Failed to resolve GitHub release for '%s': %v", theme.Slug, err)
 				continue
@@ -1055,9 +2679,9 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 				continue
 			}
 
-			// Get the actual WordPress slug from the built theme's properties
-			// This is the sanitized name that WordPress will use as the theme directory
-			wpSlug = b.GetThemeSlug()
+			// Get the actual WordPress slug from the built theme's properties: the
+			// directory the zip unpacks to, its zip-folder or the sanitized name
+			wpSlug = b.InstallName(b.GetThemeSlug())
 
 			// Find the built zip file
 			buildDir := filepath.Join(resolution.BuildDir, "build")
@@ -1085,6 +2709,18 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 			ui.PrintInfo("  Installing theme '%s' from build...", theme.Slug)
 		}
 
+		// A URL with a sha256 is downloaded and checked here, then installed from the file
+		var downloadDir string
+		if isHTTPURL(resolution.ZipPath) && theme.Sha256 != "" {
+			zipPath, err := downloadVerifiedZip(resolution.ZipPath, theme.Sha256)
+			if err != nil {
+				ui.PrintWarning("  Failed to download theme '%s': %v", theme.Slug, err)
+				continue
+			}
+			downloadDir = filepath.Dir(zipPath)
+			resolution.ZipPath, resolution.IsLocal = zipPath, true
+		}
+
 		if resolution.IsLocal && resolution.ZipPath != "" {
 			// Install from local file
 			if !strings.HasPrefix(resolution.ZipPath, "http://") && !strings.HasPrefix(resolution.ZipPath, "https://") {
@@ -1111,9 +2747,9 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 					"-v", pluginSlug+"-wp:/var/www/html",
 					"-v", zipDir+":"+containerMountPath+":ro",
 					"-e", "WORDPRESS_DB_HOST="+mysqlContainer,
-					"-e", "WORDPRESS_DB_USER=wordpress",
-					"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-					"-e", "WORDPRESS_DB_NAME=wordpress",
+					"-e", "WORDPRESS_DB_USER="+credentials.DBUser,
+					"-e", "WORDPRESS_DB_PASSWORD="+credentials.DBPassword,
+					"-e", "WORDPRESS_DB_NAME="+credentials.DBName,
 					"wordpress:cli",
 					"wp", "theme", "install", containerZipPath,
 				)
@@ -1125,9 +2761,9 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 					"--user", "33:33",
 					"-v", pluginSlug+"-wp:/var/www/html",
 					"-e", "WORDPRESS_DB_HOST="+mysqlContainer,
-					"-e", "WORDPRESS_DB_USER=wordpress",
-					"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-					"-e", "WORDPRESS_DB_NAME=wordpress",
+					"-e", "WORDPRESS_DB_USER="+credentials.DBUser,
+					"-e", "WORDPRESS_DB_PASSWORD="+credentials.DBPassword,
+					"-e", "WORDPRESS_DB_NAME="+credentials.DBName,
 					"wordpress:cli",
 					"wp", "theme", "install", resolution.ZipPath,
 				)
@@ -1140,9 +2776,9 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 				"--user", "33:33",
 				"-v", pluginSlug + "-wp:/var/www/html",
 				"-e", "WORDPRESS_DB_HOST=" + mysqlContainer,
-				"-e", "WORDPRESS_DB_USER=wordpress",
-				"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-				"-e", "WORDPRESS_DB_NAME=wordpress",
+				"-e", "WORDPRESS_DB_USER=" + credentials.DBUser,
+				"-e", "WORDPRESS_DB_PASSWORD=" + credentials.DBPassword,
+				"-e", "WORDPRESS_DB_NAME=" + credentials.DBName,
 				"wordpress:cli",
 				"wp", "theme", "install", theme.Slug,
 			}
@@ -1152,25 +2788,433 @@ func installPluginsAndThemes(pluginSlug string, wpConfig *config.WordPressConfig
 			installCmd = exec.Command("docker", installArgs...)
 		}
 
-		if err := installCmd.Run(); err != nil {
-			ui.PrintWarning("  Failed to install theme '%s': %v", theme.Slug, err)
+		output, err := installCmd.CombinedOutput()
+		config.RemoveTempDir(downloadDir)
+		if err != nil {
+			ui.PrintWarning("  Failed to install theme '%s': %v", theme.Slug, commandError(err, output))
 			continue
 		}
 
-		// Activate if requested
+		// Activate if requested; a network has to enable the theme first
 		if theme.Active {
+			if wpConfig.Multisite {
+				if output, err := wpCLICommand(pluginSlug, "theme", "enable", wpSlug, "--network").CombinedOutput(); err != nil {
+					ui.PrintWarning("  Failed to network-enable theme '%s': %v", wpSlug, commandError(err, output))
+				}
+			}
 			activateCmd := exec.Command("docker", "run", "--rm",
 				"--network", networkName,
 				"--user", "33:33",
 				"-v", pluginSlug+"-wp:/var/www/html",
 				"-e", "WORDPRESS_DB_HOST="+mysqlContainer,
-				"-e", "WORDPRESS_DB_USER=wordpress",
-				"-e", "WORDPRESS_DB_PASSWORD=wordpress",
-				"-e", "WORDPRESS_DB_NAME=wordpress",
+				"-e", "WORDPRESS_DB_USER="+credentials.DBUser,
+				"-e", "WORDPRESS_DB_PASSWORD="+credentials.DBPassword,
+				"-e", "WORDPRESS_DB_NAME="+credentials.DBName,
 				"wordpress:cli",
 				"wp", "theme", "activate", wpSlug,
 			)
-			activateCmd.Run()
+			if output, err := activateCmd.CombinedOutput(); err != nil {
+				ui.PrintWarning("  Failed to activate theme '%s': %v", wpSlug, commandError(err, output))
+			}
+		}
+	}
+}
+
+// isHTTPURL checks if s is an http(s) URL
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// downloadVerifiedZip downloads a plugin or theme zip into a temp directory and
+// checks it against its sha256, so it can be installed from the checked file
+// instead of letting wp fetch the URL. Release it with config.RemoveTempDir on
+// the zip's directory.
+func downloadVerifiedZip(url, checksum string) (string, error) {
+	tempDir, err := config.NewTempDir("download")
+	if err != nil {
+		return "", err
+	}
+	// The directory is mounted into the WP-CLI container, which runs as www-data
+	if err := os.Chmod(tempDir, 0755); err != nil {
+		config.RemoveTempDir(tempDir)
+		return "", err
+	}
+	zipPath := filepath.Join(tempDir, "package.zip")
+	if err := builder.DownloadFile(url, zipPath, checksum); err != nil {
+		config.RemoveTempDir(tempDir)
+		return "", err
+	}
+	return zipPath, nil
+}
+
+// commandError adds the last few lines of a failed command's output to its error,
+// so WP-CLI failures explain why (e.g. "Warning: Plugin already installed.")
+func commandError(err error, output []byte) error {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) > 5 {
+		lines = lines[len(lines)-5:]
+	}
+	tail := strings.TrimSpace(strings.Join(lines, "\n"))
+	if tail == "" {
+		return err
+	}
+	return fmt.Errorf("%w: %s", err, tail)
+}
+
+// installPHPExtensions installs PHP extensions into a running WordPress container
+// and gracefully restarts Apache so they're loaded
+func installPHPExtensions(pluginSlug string, extensions []string) {
+	commands, unknown := builder.PHPExtensionCommands(extensions)
+	for _, name := range unknown {
+		ui.PrintWarning("Unknown PHP extension '%s', skipping (known: %s)", name, strings.Join(builder.KnownPHPExtensions(), ", "))
+	}
+	if len(commands) == 0 {
+		return
+	}
+
+	ui.PrintInfo("Installing PHP extensions...")
+	script := "set -e\n" + strings.Join(commands, "\n") + "\napache2ctl -k graceful"
+	output, err := exec.Command("docker", "exec", "-u", "root", pluginSlug+"-wordpress", "sh", "-c", script).CombinedOutput()
+	if err != nil {
+		ui.PrintWarning("Failed to install PHP extensions: %v", commandError(err, output))
+		return
+	}
+	ui.PrintSuccess("PHP extensions installed")
+}
+
+// applyPHPLimits writes the upload and memory limit settings into a running
+// WordPress container and gracefully restarts Apache so they take effect
+func applyPHPLimits(pluginSlug, uploadLimit, memoryLimit string) {
+	script := "cat > " + builder.PHPLimitsFile + " && apache2ctl -k graceful"
+	cmd := exec.Command("docker", "exec", "-i", "-u", "root", pluginSlug+"-wordpress", "sh", "-c", script)
+	cmd.Stdin = strings.NewReader(builder.PHPLimitsINI(uploadLimit, memoryLimit))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		ui.PrintWarning("Failed to set PHP limits: %v", commandError(err, output))
+		return
+	}
+	ui.PrintSuccess("PHP limits raised (upload: %s, memory: %s)", limitOrDefault(uploadLimit), limitOrDefault(memoryLimit))
+}
+
+// limitOrDefault describes a PHP limit for output, where "" keeps the image's default
+func limitOrDefault(limit string) string {
+	if limit == "" {
+		return "default"
+	}
+	return limit
+}
+
+// enableRedisCache installs the redis-cache plugin, points it at the environment's
+// Redis container, and enables the object cache drop-in
+func enableRedisCache(pluginSlug string) {
+	ui.PrintInfo("Enabling Redis object cache...")
+
+	steps := [][]string{
+		{"config", "set", "WP_REDIS_HOST", pluginSlug + "-redis"},
+		{"plugin", "install", "redis-cache", "--activate"},
+		{"redis", "enable", "--force"},
+	}
+	for _, args := range steps {
+		output, err := wpCLICommand(pluginSlug, args...).CombinedOutput()
+		if err != nil {
+			ui.PrintWarning("Failed to run wp %s: %v", strings.Join(args, " "), commandError(err, output))
+			return
+		}
+	}
+	ui.PrintSuccess("Redis object cache enabled")
+}
+
+// getInstanceName returns the environment name from the properties files in dir,
+// checking site.properties, wordpress.properties, then plugin/theme properties
+func getInstanceName(dir string) (string, error) {
+	var name string
+
+	if config.SiteExists(dir) {
+		siteConfig, err := config.LoadSiteProperties(dir)
+		if err != nil {
+			return "", fmt.Errorf("failed to load site.properties: %w", err)
+		}
+		name = siteConfig.Name
+	} else if config.WordPressExists(dir) {
+		wpConfig, err := config.LoadWordPressProperties(dir)
+		if err != nil {
+			return "", fmt.Errorf("failed to load wordpress.properties: %w", err)
+		}
+		name = wpConfig.Name
+	}
+
+	if name == "" {
+		if config.PluginExists(dir) {
+			cfg, err := config.LoadPluginProperties(dir)
+			if err != nil {
+				return "", fmt.Errorf("failed to load plugin.properties: %w", err)
+			}
+			name = cfg.Name
+		} else if config.ThemeExists(dir) {
+			cfg, err := config.LoadThemeProperties(dir)
+			if err != nil {
+				return "", fmt.Errorf("failed to load theme.properties: %w", err)
+			}
+			name = cfg.Name
+		}
+	}
+
+	return name, nil
+}
+
+// resolveInstance returns the environment slug from an optional instance name argument,
+// falling back to the properties files in the current directory. Exits on failure.
+func resolveInstance(args []string, usage string) string {
+	if len(args) > 0 && args[0] != "" {
+		instance := args[0]
+		if !containerExists(instance + "-wordpress") {
+			ui.PrintError("WordPress container '%s' not found", instance)
+			exit(1)
+		}
+		return instance
+	}
+
+	dir, err := getProjectDir()
+	if err != nil {
+		ui.PrintError("Failed to determine project directory: %v", err)
+		exit(1)
+	}
+
+	name, err := getInstanceName(dir)
+	if err != nil {
+		ui.PrintError("%v", err)
+		exit(1)
+	}
+
+	if name == "" {
+		ui.PrintError("No site.properties, wordpress.properties, plugin.properties, or theme.properties found in current directory")
+		ui.PrintInfo("Specify instance name: %s", usage)
+		exit(1)
+	}
+
+	return sanitizePluginName(name)
+}
+
+// requireRunning exits with a helpful message if the environment isn't running
+func requireRunning(pluginSlug string) {
+	if !isContainerRunning(pluginSlug + "-wordpress") {
+		ui.PrintError("WordPress [%s] is not running", pluginSlug)
+		ui.PrintInfo("Run 'wordsmith wordpress start' first")
+		exit(1)
+	}
+	if isContainerPaused(pluginSlug+"-wordpress") || isContainerPaused(pluginSlug+"-mysql") {
+		ui.PrintError("WordPress [%s] is paused", pluginSlug)
+		ui.PrintInfo("Run 'wordsmith wordpress unpause' first")
+		exit(1)
+	}
+}
+
+// isMultisite checks if an environment is installed as a multisite network
+func isMultisite(pluginSlug string) bool {
+	return wpCLICommand(pluginSlug, "core", "is-installed", "--network").Run() == nil
+}
+
+// requireMultisite exits with an error if an environment isn't a multisite network
+func requireMultisite(pluginSlug string) {
+	if !isMultisite(pluginSlug) {
+		ui.PrintError("WordPress [%s] is not a multisite network", pluginSlug)
+		ui.PrintInfo("Set 'multisite: true' in wordpress.properties or site.properties")
+		exit(1)
+	}
+}
+
+// wpCLICommand builds a docker command that runs WP-CLI against an environment
+func wpCLICommand(pluginSlug string, args ...string) *exec.Cmd {
+	dockerArgs := append(wpCLIRunArgs(pluginSlug), "wordpress:cli", "wp")
+	return exec.Command("docker", append(dockerArgs, args...)...)
+}
+
+// wpCLIRunArgs returns the docker run arguments, up to the image, for a WP-CLI
+// container connected to an environment's volume and database
+func wpCLIRunArgs(pluginSlug string) []string {
+	credentials := instanceCredentials(pluginSlug)
+	return []string{"run", "--rm",
+		"--network", pluginSlug + "-network",
+		"--user", "33:33",
+		"-v", pluginSlug + "-wp:/var/www/html",
+		"-e", "WORDPRESS_DB_HOST=" + pluginSlug + "-mysql",
+		"-e", "WORDPRESS_DB_USER=" + credentials.DBUser,
+		"-e", "WORDPRESS_DB_PASSWORD=" + credentials.DBPassword,
+		"-e", "WORDPRESS_DB_NAME=" + credentials.DBName,
+	}
+}
+
+// environmentCredentials returns the logins for a new environment from its
+// configuration, or the defaults for a plain plugin or theme project
+func environmentCredentials(wpConfig *config.WordPressConfig) config.Credentials {
+	if wpConfig == nil {
+		return config.DefaultCredentials()
+	}
+	return wpConfig.Credentials
+}
+
+// instanceCredentials returns the database login an environment's WordPress container
+// was started with, so WP-CLI connects the same way even if wordpress.properties has
+// changed since. The admin login isn't recorded on the container and keeps the defaults.
+func instanceCredentials(pluginSlug string) config.Credentials {
+	credentials := config.DefaultCredentials()
+
+	output, err := exec.Command("docker", "inspect", "--format", "{{range .Config.Env}}{{println .}}{{end}}", pluginSlug+"-wordpress").Output()
+	if err != nil {
+		return credentials
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || value == "" {
+			continue
+		}
+		switch key {
+		case "WORDPRESS_DB_NAME":
+			credentials.DBName = value
+		case "WORDPRESS_DB_USER":
+			credentials.DBUser = value
+		case "WORDPRESS_DB_PASSWORD":
+			credentials.DBPassword = value
+		}
+	}
+	return credentials
+}
+
+// projectCredentials returns the logins configured in the current directory's
+// site.properties or wordpress.properties when they belong to the environment,
+// or the defaults otherwise
+func projectCredentials(pluginSlug string) config.Credentials {
+	dir, err := getProjectDir()
+	if err != nil {
+		return config.DefaultCredentials()
+	}
+	if name, err := getInstanceName(dir); err != nil || sanitizePluginName(name) != pluginSlug {
+		return config.DefaultCredentials()
+	}
+
+	if config.SiteExists(dir) {
+		if siteConfig, err := config.LoadSiteProperties(dir); err == nil {
+			return siteConfig.Credentials
+		}
+	} else if config.WordPressExists(dir) {
+		if wpConfig, err := config.LoadWordPressProperties(dir); err == nil {
+			return wpConfig.Credentials
+		}
+	}
+	return config.DefaultCredentials()
+}
+
+// projectHostname returns the hostname configured in the current directory's
+// site.properties or wordpress.properties when they belong to the environment,
+// or "" otherwise
+func projectHostname(pluginSlug string) string {
+	dir, err := getProjectDir()
+	if err != nil {
+		return ""
+	}
+	if name, err := getInstanceName(dir); err != nil || sanitizePluginName(name) != pluginSlug {
+		return ""
+	}
+
+	if config.SiteExists(dir) {
+		if siteConfig, err := config.LoadSiteProperties(dir); err == nil {
+			return siteConfig.Hostname
+		}
+	} else if config.WordPressExists(dir) {
+		if wpConfig, err := config.LoadWordPressProperties(dir); err == nil {
+			return wpConfig.Hostname
+		}
+	}
+	return ""
+}
+
+// applyHostname points siteurl and home at a custom hostname and prints the
+// /etc/hosts entry needed to resolve it, or adds the entry itself with
+// manageHosts. Returns the new site URL.
+func applyHostname(pluginSlug, hostname, port string, manageHosts bool) (string, error) {
+	wpURL := fmt.Sprintf("http://%s:%s", hostname, port)
+
+	for _, option := range []string{"siteurl", "home"} {
+		output, err := wpCLICommand(pluginSlug, "option", "update", option, wpURL).CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("failed to update %s: %s", option, strings.TrimSpace(string(output)))
+		}
+	}
+
+	if hostname != "localhost" {
+		if manageHosts {
+			err := writeHostsEntry(pluginSlug, hostname)
+			if err == nil {
+				return wpURL, nil
+			}
+			ui.PrintWarning("Failed to update %s: %v", hostsPath, err)
+		}
+		ui.PrintInfo("Add this line to %s to resolve %s:", hostsPath, hostname)
+		fmt.Printf("  127.0.0.1 %s\n", hostname)
+	}
+
+	return wpURL, nil
+}
+
+// hostsPath is the hosts file managed by --manage-hosts
+const hostsPath = "/etc/hosts"
+
+// hostsMarker tags the hosts file lines wordsmith manages for an environment,
+// so only those lines are ever replaced or removed
+func hostsMarker(pluginSlug string) string {
+	return "# wordsmith:" + pluginSlug
+}
+
+// updateHostsContent returns hosts file content with the environment's managed
+// line replaced by a 127.0.0.1 entry for hostname, or removed if hostname is ""
+func updateHostsContent(content, pluginSlug, hostname string) string {
+	marker := hostsMarker(pluginSlug)
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		if strings.HasSuffix(strings.TrimSpace(line), marker) {
+			continue
 		}
+		lines = append(lines, line)
 	}
+	if hostname != "" {
+		lines = append(lines, fmt.Sprintf("127.0.0.1 %s %s", hostname, marker))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// writeHostsEntry points hostname at 127.0.0.1 in the hosts file for an
+// environment, or removes the environment's entry if hostname is ""
+func writeHostsEntry(pluginSlug, hostname string) error {
+	if runtime.GOOS == "windows" {
+		return fmt.Errorf("--manage-hosts is not supported on Windows")
+	}
+
+	content, err := os.ReadFile(hostsPath)
+	if err != nil {
+		return err
+	}
+	updated := updateHostsContent(string(content), pluginSlug, hostname)
+	if updated == string(content) {
+		return nil
+	}
+
+	// Write with sudo unless already root; sudo prompts on the terminal
+	var writeCmd *exec.Cmd
+	if os.Geteuid() == 0 {
+		writeCmd = exec.Command("tee", hostsPath)
+	} else {
+		ui.PrintWarning("Updating %s requires administrator privileges; sudo may ask for your password", hostsPath)
+		writeCmd = exec.Command("sudo", "tee", hostsPath)
+	}
+	writeCmd.Stdin = strings.NewReader(updated)
+	writeCmd.Stderr = os.Stderr
+	if err := writeCmd.Run(); err != nil {
+		return err
+	}
+
+	if hostname != "" {
+		ui.PrintSuccess("Added 127.0.0.1 %s to %s", hostname, hostsPath)
+	} else {
+		ui.PrintSuccess("Removed the %s entry for [%s]", hostsPath, pluginSlug)
+	}
+	return nil
 }
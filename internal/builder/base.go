@@ -17,11 +17,20 @@ import (
 
 // BaseBuilder contains shared functionality for plugin and theme builders
 type BaseBuilder struct {
-	SourceDir string
-	BuildDir  string
-	WorkDir   string
-	Version   *version.Version
-	Quiet     bool
+	SourceDir  string
+	BuildDir   string
+	WorkDir    string
+	Version    *version.Version
+	Quiet      bool
+	Phase      string   // Current build phase, reported on failure
+	ZipPath    string   // Path of the created zip once packaged
+	StageOnly  bool     // Stop after staging, without creating the zip
+	OutputPath string   // Zip destination; defaults to <BuildDir>/<name>-<version>.zip
+	ZipFolder  string   // Top-level folder inside the zip; defaults to the slug
+	Checksums  []string // Checksum files to write next to the zip (sha256, md5)
+
+	Provenance  bool   // Write a BUILD_INFO file with the git commit, branch, and build time
+	ToolVersion string // wordsmith version recorded in BUILD_INFO
 }
 
 // NewBaseBuilder creates a new BaseBuilder
@@ -34,24 +43,67 @@ func NewBaseBuilder(sourceDir string) BaseBuilder {
 	}
 }
 
-// ParseVersion parses a version string into a Version struct
+// SetPhase records the current build phase and reports it to JSON event listeners
+func (b *BaseBuilder) SetPhase(phase string) {
+	b.Phase = phase
+	ui.SetPhase(phase)
+}
+
+// semverPattern matches major.minor.patch with an optional -prerelease and +build suffix
+var semverPattern = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+// looseVersionPattern matches major.minor followed by anything, such as the
+// four-part versions (1.2.3.4) common in WordPress plugins
+var looseVersionPattern = regexp.MustCompile(`^(\d+)\.(\d+)\.(.+)$`)
+
+// ParseVersion parses a version string into a Version struct. A major.minor
+// version that isn't semver, such as 1.2.3.4, keeps everything after major.minor
+// as written so it round-trips unchanged. Any other string is kept as the
+// pre-release of 0.0.0 with anything but letters, numbers, dots, and dashes
+// replaced by dashes.
 func ParseVersion(versionStr string) *version.Version {
-	ver := &version.Version{
-		Major:       0,
-		Minor:       0,
-		Maintenance: versionStr,
-	}
-	re := regexp.MustCompile(`^(\d+)\.(\d+)\.(.+)$`)
-	if matches := re.FindStringSubmatch(versionStr); matches != nil {
-		fmt.Sscanf(matches[1], "%d", &ver.Major)
-		fmt.Sscanf(matches[2], "%d", &ver.Minor)
-		ver.Maintenance = matches[3]
+	matches := semverPattern.FindStringSubmatch(versionStr)
+	if matches == nil {
+		if loose := looseVersionPattern.FindStringSubmatch(versionStr); loose != nil {
+			ver := &version.Version{Rest: loose[3]}
+			fmt.Sscanf(loose[1], "%d", &ver.Major)
+			fmt.Sscanf(loose[2], "%d", &ver.Minor)
+			fmt.Sscanf(loose[3], "%d", &ver.Patch)
+			return ver
+		}
+		return &version.Version{Prerelease: invalidPrereleaseChars.ReplaceAllString(versionStr, "-")}
 	}
+
+	ver := &version.Version{Prerelease: matches[4], Build: matches[5]}
+	fmt.Sscanf(matches[1], "%d", &ver.Major)
+	fmt.Sscanf(matches[2], "%d", &ver.Minor)
+	fmt.Sscanf(matches[3], "%d", &ver.Patch)
 	return ver
 }
 
+// invalidPrereleaseChars matches characters not allowed in a pre-release
+var invalidPrereleaseChars = regexp.MustCompile(`[^0-9A-Za-z.-]+`)
+
+// parseHeaderVersion parses a Version header, which may have only two parts (2.3 is 2.3.0)
+func parseHeaderVersion(value string) *version.Version {
+	if regexp.MustCompile(`^\d+\.\d+$`).MatchString(value) {
+		value += ".0"
+	}
+	return ParseVersion(value)
+}
+
 // GetVersionFromGit gets version from git tags
 func (b *BaseBuilder) GetVersionFromGit() (*version.Version, error) {
+	return b.GetVersionFromGitOrHeader("")
+}
+
+// GetVersionFromGitOrHeader gets version from git tags. Without a version tag, the
+// Version header of headerPath (a plugin's main file or a theme's style.css) is
+// used if it has one, so plugins versioned only by their header build as that version.
+// With neither, the version is 0.1.0 with a warning rather than an error: a project
+// fresh from wordsmith init has no tag, version property, or header yet, and its
+// first build should still work.
+func (b *BaseBuilder) GetVersionFromGitOrHeader(headerPath string) (*version.Version, error) {
 	if !b.Quiet {
 		ui.PrintInfo("Reading version from git tags...")
 	}
@@ -59,6 +111,17 @@ func (b *BaseBuilder) GetVersionFromGit() (*version.Version, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get version from git: %w", err)
 	}
+	if !ver.Tagged && headerPath != "" {
+		if header, err := config.ReadFileHeader(headerPath, []string{"Version"}); err == nil && header["Version"] != "" {
+			if !b.Quiet {
+				ui.PrintInfo("No version tag, using the Version header of %s", filepath.Base(headerPath))
+			}
+			return parseHeaderVersion(header["Version"]), nil
+		}
+		if !b.Quiet {
+			ui.PrintWarning("No version tag, version property, or Version header in %s; using %s", filepath.Base(headerPath), ver.String())
+		}
+	}
 	if ver.IsDirty && !b.Quiet {
 		ui.PrintWarning("Detected uncommitted changes, appending timestamp")
 	}
@@ -97,6 +160,11 @@ func (b *BaseBuilder) CreateStageDir() (string, error) {
 	return stageDir, nil
 }
 
+// GetStagePath returns the path to the stage directory
+func (b *BaseBuilder) GetStagePath() string {
+	return filepath.Join(b.WorkDir, "stage")
+}
+
 // SanitizeName converts a name to a slug
 func SanitizeName(name string) string {
 	result := strings.ToLower(name)
@@ -163,6 +231,15 @@ func CopyDirWithExcludes(src, dst string, excludes []string) error {
 	})
 }
 
+// ShouldMinify reports whether a CSS/JS file should be minified. Files matching
+// minify-exclude, or under a directory that matches it, are copied verbatim.
+func ShouldMinify(relPath string, minifyExclude []string) bool {
+	if !strings.HasSuffix(relPath, ".css") && !strings.HasSuffix(relPath, ".js") {
+		return false
+	}
+	return !IsExcluded(relPath, minifyExclude) && !hasExcludedParent(relPath, minifyExclude)
+}
+
 // CopyAndMinify copies a file and minifies it if it's CSS or JS
 func CopyAndMinify(src, dst string, minify bool) error {
 	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
@@ -189,6 +266,63 @@ func CopyAndMinify(src, dst string, minify bool) error {
 	return os.WriteFile(dst, content, 0644)
 }
 
+// Package prepares the stage directory and zips it as <name>-<version>.zip, or
+// to OutputPath when set. With StageOnly the zip is skipped and the stage
+// directory is left for other tools.
+func (b *BaseBuilder) Package(stageDir, name string) error {
+	CleanDevFiles(stageDir)
+
+	if b.Provenance {
+		if err := WriteBuildInfo(filepath.Join(stageDir, BuildInfoFile), b.SourceDir, name, b.Version.String(), b.ToolVersion); err != nil {
+			return fmt.Errorf("failed to write %s: %w", BuildInfoFile, err)
+		}
+	}
+
+	// Set permissions on all files before zipping
+	if err := ChmodAll(stageDir, 0777); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+
+	if b.StageOnly {
+		return nil
+	}
+
+	if !b.Quiet {
+		ui.PrintInfo("Creating ZIP archive...")
+	}
+	zipPath := filepath.Join(b.BuildDir, fmt.Sprintf("%s-%s.zip", name, b.Version.String()))
+	if b.OutputPath != "" {
+		zipPath = b.OutputPath
+		if err := os.MkdirAll(filepath.Dir(zipPath), 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	if err := CreateZip(stageDir, zipPath, b.InstallName(name)); err != nil {
+		return fmt.Errorf("failed to create ZIP: %w", err)
+	}
+	b.ZipPath = zipPath
+
+	if !b.Quiet {
+		fmt.Println()
+		if b.OutputPath != "" {
+			ui.PrintSuccess("Created: %s", zipPath)
+		} else {
+			ui.PrintSuccess("Created: %s", filepath.Base(zipPath))
+		}
+	}
+
+	for _, algorithm := range b.Checksums {
+		checksumPath, err := WriteChecksumFile(zipPath, algorithm)
+		if err != nil {
+			return fmt.Errorf("failed to write %s checksum: %w", algorithm, err)
+		}
+		if !b.Quiet {
+			ui.PrintSuccess("Created: %s", filepath.Base(checksumPath))
+		}
+	}
+	return nil
+}
+
 // CleanDevFiles removes development files from a directory
 func CleanDevFiles(dir string) {
 	patterns := []string{".DS_Store", "*.swp", "*.swo", "*~", ".git", ".gitignore"}
@@ -209,6 +343,15 @@ func CleanDevFiles(dir string) {
 	})
 }
 
+// InstallName returns the directory the zip installs to in WordPress: ZipFolder
+// if set, otherwise the slug
+func (b *BaseBuilder) InstallName(slug string) string {
+	if b.ZipFolder != "" {
+		return b.ZipFolder
+	}
+	return slug
+}
+
 // CreateZip creates a zip archive from a directory
 func CreateZip(sourceDir, zipPath, baseName string) error {
 	zipFile, err := os.Create(zipPath)
@@ -229,7 +372,8 @@ func CreateZip(sourceDir, zipPath, baseName string) error {
 		if err != nil {
 			return err
 		}
-		archivePath := filepath.Join(baseName, relPath)
+		// Zip entries always use forward slashes; Walk visits them in lexical order
+		archivePath := filepath.ToSlash(filepath.Join(baseName, relPath))
 
 		if info.IsDir() {
 			if relPath != "." {
@@ -287,8 +431,10 @@ func CopyLibraries(libraries []config.LibrarySpec, stageDir string, quiet bool)
 			return fmt.Errorf("failed to resolve library %s: %w", lib.Name, err)
 		}
 
-		// Copy to stage directory
-		if err := config.CopyLibraryToDir(libPath, stageDir, lib.Name); err != nil {
+		// Copy to stage directory, then drop the extracted copy of a local archive
+		err = config.CopyLibraryToDir(libPath, stageDir, lib.Name)
+		config.RemoveTempDir(libPath)
+		if err != nil {
 			return fmt.Errorf("failed to copy library %s: %w", lib.Name, err)
 		}
 	}
@@ -320,15 +466,20 @@ func buildLibraryIfNeeded(path string, quiet bool) error {
 	return b.Build()
 }
 
-// WriteVersionProperties writes version.properties file
+// WriteVersionProperties writes version.properties file. maintenance (the patch
+// with its suffixes) is still written for PHP code generated before patch,
+// prerelease, and build were split out.
 func WriteVersionProperties(path, name string, ver *version.Version) error {
 	content := fmt.Sprintf(`# %s Version Information
 # Generated by wordsmith
 
 major=%d
 minor=%d
-maintenance=%s
-`, name, ver.Major, ver.Minor, ver.Maintenance)
+patch=%d
+prerelease="%s"
+build="%s"
+maintenance="%s"
+`, name, ver.Major, ver.Minor, ver.Patch, ver.Prerelease, ver.Build, ver.Maintenance())
 
 	return os.WriteFile(path, []byte(content), 0644)
 }
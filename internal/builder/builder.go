@@ -3,8 +3,10 @@ package builder
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"wordsmith/internal/config"
@@ -18,6 +20,7 @@ type PluginDependency struct {
 	Path    string // Local path to built/resolved plugin (empty for WP.org plugins)
 	IsWPOrg bool   // True if WordPress.org plugin
 	Version string // Version if specified
+	Order   int    // Activation order; lower numbers activate first
 }
 
 // Builder builds WordPress plugins
@@ -36,6 +39,7 @@ func New(sourceDir string) *Builder {
 
 // Build builds the plugin
 func (b *Builder) Build() error {
+	b.SetPhase(PhaseConfig)
 	if !b.Quiet {
 		ui.PrintInfo("Loading plugin.properties...")
 	}
@@ -44,12 +48,19 @@ func (b *Builder) Build() error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 	b.Config = cfg
+	b.ZipFolder = cfg.ZipFolder
+
+	// .wordsmithignore patterns apply on top of exclude
+	if b.Config.Exclude, err = EffectiveExcludes(b.SourceDir, cfg.Exclude); err != nil {
+		return err
+	}
 
 	// Parse version
+	b.SetPhase(PhaseVersion)
 	if cfg.Version != "" {
 		b.Version = ParseVersion(cfg.Version)
 	} else {
-		ver, err := b.GetVersionFromGit()
+		ver, err := b.GetVersionFromGitOrHeader(filepath.Join(b.SourceDir, cfg.Main))
 		if err != nil {
 			return err
 		}
@@ -74,6 +85,7 @@ func (b *Builder) Build() error {
 		return fmt.Errorf("failed to create source directory: %w", err)
 	}
 
+	b.SetPhase(PhaseCopy)
 	if !b.Quiet {
 		ui.PrintInfo("Copying plugin files...")
 	}
@@ -110,7 +122,7 @@ func (b *Builder) Build() error {
 				}
 			} else {
 				dst := filepath.Join(stageDir, include)
-				if b.Config.Minify && (strings.HasSuffix(include, ".css") || strings.HasSuffix(include, ".js")) {
+				if b.Config.Minify && ShouldMinify(include, b.Config.MinifyExclude) {
 					if err := CopyAndMinify(src, dst, true); err != nil {
 						return fmt.Errorf("failed to minify file %s: %w", include, err)
 					}
@@ -135,10 +147,16 @@ func (b *Builder) Build() error {
 		}
 	}
 
+	b.SetPhase(PhaseProcess)
 	if !b.Quiet {
 		ui.PrintInfo("Processing PHP files...")
 	}
 
+	// Lint obfuscated files when PHP is available, since the obfuscator can
+	// produce invalid code on edge cases
+	_, phpErr := exec.LookPath("php")
+	lintObfuscated := b.Config.Obfuscate && phpErr == nil
+
 	err = filepath.Walk(sourceWorkDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return err
@@ -160,24 +178,37 @@ func (b *Builder) Build() error {
 		}
 
 		output := string(content)
+		obfuscated := false
 
 		if strings.HasSuffix(info.Name(), ".php") {
 			output = b.replaceVersionConstants(output)
 
-			if b.Config.Obfuscate {
+			if b.Config.Obfuscate && b.shouldObfuscate(relPath) {
 				output, err = obfuscator.Obfuscate(output)
 				if err != nil {
 					return fmt.Errorf("failed to obfuscate %s: %w", relPath, err)
 				}
+				obfuscated = true
 			}
 		}
 
-		return os.WriteFile(dstPath, []byte(output), info.Mode())
+		if err := os.WriteFile(dstPath, []byte(output), info.Mode()); err != nil {
+			return err
+		}
+
+		if obfuscated && lintObfuscated {
+			if err := lintPHP(dstPath); err != nil {
+				return fmt.Errorf("obfuscated %s is not valid PHP (add it to obfuscate-exclude to skip): %w", relPath, err)
+			}
+		}
+
+		return nil
 	})
 	if err != nil {
 		return fmt.Errorf("failed to process PHP files: %w", err)
 	}
 
+	b.SetPhase(PhaseMetadata)
 	if err := b.generatePluginHeader(filepath.Join(stageDir, mainFile)); err != nil {
 		return fmt.Errorf("failed to generate plugin header: %w", err)
 	}
@@ -193,6 +224,7 @@ func (b *Builder) Build() error {
 	}
 
 	// Copy libraries to stage directory
+	b.SetPhase(PhaseLibraries)
 	if len(b.Config.Libraries) > 0 {
 		if !b.Quiet {
 			ui.PrintInfo("Copying libraries...")
@@ -203,6 +235,7 @@ func (b *Builder) Build() error {
 	}
 
 	// Build/resolve plugin dependencies
+	b.SetPhase(PhaseDependencies)
 	if len(b.Config.Plugins) > 0 {
 		if !b.Quiet {
 			ui.PrintInfo("Resolving plugin dependencies...")
@@ -212,29 +245,31 @@ func (b *Builder) Build() error {
 		}
 	}
 
-	CleanDevFiles(stageDir)
-
-	// Set permissions on all files before zipping
-	if err := ChmodAll(stageDir, 0777); err != nil {
-		return fmt.Errorf("failed to set permissions: %w", err)
+	b.SetPhase(PhasePackage)
+	if err := b.Package(stageDir, pluginName); err != nil {
+		return err
 	}
 
-	if !b.Quiet {
-		ui.PrintInfo("Creating ZIP archive...")
-	}
-	zipPath := filepath.Join(b.BuildDir, fmt.Sprintf("%s-%s.zip", pluginName, b.Version.String()))
-	if err := CreateZip(stageDir, zipPath, pluginName); err != nil {
-		return fmt.Errorf("failed to create ZIP: %w", err)
-	}
+	b.SetPhase(PhaseComplete)
+	return nil
+}
 
-	if !b.Quiet {
-		fmt.Println()
-		ui.PrintSuccess("Created: %s", filepath.Base(zipPath))
+// lintPHP runs php -l against a file and returns the linter output on failure
+func lintPHP(path string) error {
+	output, err := exec.Command("php", "-l", path).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
 	}
-
 	return nil
 }
 
+// shouldObfuscate reports whether a PHP file should be obfuscated. Files matching
+// obfuscate-exclude are left untouched; libraries are merged into the stage after
+// obfuscation, so they never reach this check.
+func (b *Builder) shouldObfuscate(relPath string) bool {
+	return !IsExcluded(relPath, b.Config.ObfuscateExclude)
+}
+
 // GetPluginSlug returns the WordPress plugin slug (directory name) for this plugin.
 func (b *Builder) GetPluginSlug() string {
 	if b.Config == nil {
@@ -281,7 +316,7 @@ func (b *Builder) copyDirSplitWithExcludes(src, relBase, phpDst, otherDst string
 		}
 
 		dst := filepath.Join(otherDst, fullRel)
-		if b.Config.Minify && (strings.HasSuffix(info.Name(), ".css") || strings.HasSuffix(info.Name(), ".js")) {
+		if b.Config.Minify && ShouldMinify(fullRel, b.Config.MinifyExclude) {
 			return CopyAndMinify(path, dst, true)
 		}
 		return CopyFile(path, dst)
@@ -289,11 +324,17 @@ func (b *Builder) copyDirSplitWithExcludes(src, relBase, phpDst, otherDst string
 }
 
 func (b *Builder) replaceVersionConstants(content string) string {
-	pluginName := strings.ToUpper(SanitizeName(b.Config.Name))
-	pluginName = strings.ReplaceAll(pluginName, "-", "_")
+	return replaceVersionConstant(content, b.Config.Name, b.Version.String())
+}
+
+// replaceVersionConstant sets the value of a define('<NAME>_VERSION', '...')
+// constant, where NAME is the upper-cased slug of name, to version
+func replaceVersionConstant(content, name, version string) string {
+	constName := strings.ToUpper(SanitizeName(name))
+	constName = strings.ReplaceAll(constName, "-", "_")
 
-	re := regexp.MustCompile(`define\s*\(\s*['"]` + pluginName + `_VERSION['"]\s*,\s*['"][^'"]*['"]\s*\)`)
-	replacement := fmt.Sprintf("define('%s_VERSION', '%s')", pluginName, b.Version.String())
+	re := regexp.MustCompile(`define\s*\(\s*['"]` + constName + `_VERSION['"]\s*,\s*['"][^'"]*['"]\s*\)`)
+	replacement := fmt.Sprintf("define('%s_VERSION', '%s')", constName, version)
 
 	return re.ReplaceAllString(content, replacement)
 }
@@ -342,6 +383,12 @@ func (b *Builder) generatePluginHeader(path string) error {
 	if requiresPlugins := b.getRequiresPluginsFromConfig(); requiresPlugins != "" {
 		header += fmt.Sprintf(" * Requires Plugins: %s\n", requiresPlugins)
 	}
+	if b.Config.UpdateURI != "" {
+		header += fmt.Sprintf(" * Update URI: %s\n", b.Config.UpdateURI)
+	}
+	if b.Config.Network {
+		header += " * Network: true\n"
+	}
 	header += " */\n"
 
 	contentStr := string(content)
@@ -391,12 +438,32 @@ func (b *Builder) writePluginProperties(path string) error {
 	if b.Config.RequiresPHP != "" {
 		lines = append(lines, fmt.Sprintf("requires-php=%s", b.Config.RequiresPHP))
 	}
+	if b.Config.UpdateURI != "" {
+		lines = append(lines, fmt.Sprintf("update-uri=%s", b.Config.UpdateURI))
+	}
+	if b.Config.Network {
+		lines = append(lines, "network=true")
+	}
 
 	content := strings.Join(lines, "\n") + "\n"
 	return os.WriteFile(path, []byte(content), 0644)
 }
 
+// generateReadme writes a readme.txt for plugins that don't have one, from the
+// fields in plugin.properties
 func (b *Builder) generateReadme(path string) error {
+	contributors := strings.Join(b.Config.Contributors, ", ")
+	if contributors == "" {
+		contributors = b.Config.Author
+	}
+	tags := strings.Join(b.Config.Tags, ", ")
+	if tags == "" {
+		tags = "wordpress"
+	}
+	testedUpTo := b.Config.TestedUpTo
+	if testedUpTo == "" {
+		testedUpTo = "6.4"
+	}
 	requires := b.Config.Requires
 	if requires == "" {
 		requires = "5.0"
@@ -412,9 +479,9 @@ func (b *Builder) generateReadme(path string) error {
 
 	content := fmt.Sprintf(`=== %s ===
 Contributors: %s
-Tags: wordpress
+Tags: %s
 Requires at least: %s
-Tested up to: 6.4
+Tested up to: %s
 Stable tag: %s
 Requires PHP: %s
 License: %s
@@ -428,7 +495,7 @@ License URI: %s
 
 1. Upload the plugin files to the /wp-content/plugins/ directory
 2. Activate the plugin through the 'Plugins' screen in WordPress
-`, b.Config.Name, b.Config.Author, requires, b.Version.String(), requiresPHP, license, b.Config.LicenseURI, b.Config.Description)
+`, b.Config.Name, contributors, tags, requires, testedUpTo, b.Version.String(), requiresPHP, license, b.Config.LicenseURI, b.Config.Description)
 
 	return os.WriteFile(path, []byte(content), 0644)
 }
@@ -444,16 +511,64 @@ func (b *Builder) buildPluginDependencies() error {
 	}
 
 	for _, spec := range b.Config.Plugins {
+		// A plugin's own dependencies are added ahead of it and share its order
+		start := len(b.Dependencies)
 		dep, err := b.resolvePluginDependency(spec, pluginsDir)
 		if err != nil {
 			return fmt.Errorf("failed to resolve plugin '%s': %w", spec.Name, err)
 		}
 		b.Dependencies = append(b.Dependencies, dep)
+		for i := start; i < len(b.Dependencies); i++ {
+			b.Dependencies[i].Order = spec.Order
+		}
 	}
+	b.Dependencies = SortDependencies(DedupeDependencies(b.Dependencies))
 
 	return nil
 }
 
+// DedupeDependencies removes duplicate dependencies by slug, keeping the position of
+// the first occurrence. A built or downloaded plugin wins over a WordPress.org slug,
+// and otherwise the highest version wins (any version beats an unpinned one).
+func DedupeDependencies(deps []PluginDependency) []PluginDependency {
+	var result []PluginDependency
+	index := make(map[string]int)
+
+	for _, dep := range deps {
+		i, seen := index[dep.Slug]
+		if !seen {
+			index[dep.Slug] = len(result)
+			result = append(result, dep)
+			continue
+		}
+		if preferDependency(dep, result[i]) {
+			result[i] = dep
+		}
+	}
+
+	return result
+}
+
+// SortDependencies sorts dependencies by activation order, lowest first, keeping
+// the resolved order for dependencies with the same order
+func SortDependencies(deps []PluginDependency) []PluginDependency {
+	sort.SliceStable(deps, func(i, j int) bool {
+		return deps[i].Order < deps[j].Order
+	})
+	return deps
+}
+
+// preferDependency reports whether candidate should replace current for the same slug
+func preferDependency(candidate, current PluginDependency) bool {
+	if candidate.IsWPOrg != current.IsWPOrg {
+		return !candidate.IsWPOrg
+	}
+	if current.Version == "" {
+		return candidate.Version != ""
+	}
+	return candidate.Version != "" && config.CompareVersions(candidate.Version, current.Version) > 0
+}
+
 // resolvePluginDependency resolves a single plugin dependency
 func (b *Builder) resolvePluginDependency(spec config.LibrarySpec, pluginsDir string) (PluginDependency, error) {
 	// Check if this is a WordPress.org slug
@@ -484,12 +599,14 @@ func (b *Builder) resolvePluginDependency(spec config.LibrarySpec, pluginsDir st
 		Name:    spec.Name,
 		URL:     url,
 		Version: spec.Version,
+		Sha256:  spec.Sha256,
 	}
 
 	libPath, err := config.ResolveLibrary(resolvedSpec)
 	if err != nil {
 		return PluginDependency{}, err
 	}
+	defer config.RemoveTempDir(libPath)
 
 	// Copy to plugins directory
 	targetDir := filepath.Join(pluginsDir, spec.Name)
@@ -526,6 +643,13 @@ func (b *Builder) buildPluginFromSource(srcDir string, pluginsDir string) (Plugi
 			return PluginDependency{}, fmt.Errorf("failed to copy built plugin: %w", err)
 		}
 
+		// Its WordPress.org dependencies are needed too, ahead of it
+		for _, spec := range cfg.Plugins {
+			if config.IsWordPressOrgSlug(spec) {
+				b.Dependencies = append(b.Dependencies, PluginDependency{Slug: spec.Name, IsWPOrg: true, Version: spec.Version})
+			}
+		}
+
 		return PluginDependency{
 			Slug:    slug,
 			Path:    targetDir,
@@ -540,6 +664,9 @@ func (b *Builder) buildPluginFromSource(srcDir string, pluginsDir string) (Plugi
 		return PluginDependency{}, fmt.Errorf("failed to build plugin: %w", err)
 	}
 
+	// Its own dependencies are needed too, ahead of it
+	b.Dependencies = append(b.Dependencies, depBuilder.Dependencies...)
+
 	slug := depBuilder.GetPluginSlug()
 	builtStageDir := filepath.Join(srcDir, "build", "work", "stage")
 	targetDir := filepath.Join(pluginsDir, slug)
@@ -576,8 +703,10 @@ func (b *Builder) GetRequiresPlugins() string {
 // Used during header generation before dependencies are fully resolved
 func (b *Builder) getRequiresPluginsFromConfig() string {
 	var slugs []string
+	seen := make(map[string]bool)
 	for _, spec := range b.Config.Plugins {
-		if config.IsWordPressOrgSlug(spec) {
+		if config.IsWordPressOrgSlug(spec) && !seen[spec.Name] {
+			seen[spec.Name] = true
 			slugs = append(slugs, spec.Name)
 		}
 	}
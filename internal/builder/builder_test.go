@@ -0,0 +1,878 @@
+package builder
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wordsmith/internal/config"
+	"wordsmith/internal/version"
+)
+
+func TestDedupeDependencies(t *testing.T) {
+	deps := []PluginDependency{
+		{Slug: "woocommerce", IsWPOrg: true},
+		{Slug: "akismet", IsWPOrg: true, Version: "5.0"},
+		{Slug: "woocommerce", IsWPOrg: true, Version: "8.0"},
+		{Slug: "akismet", IsWPOrg: true, Version: "4.2"},
+		{Slug: "woocommerce", IsWPOrg: true, Version: "7.9"},
+		{Slug: "my-helper", IsWPOrg: true},
+		{Slug: "my-helper", Path: "/tmp/plugins/my-helper"},
+	}
+
+	got := DedupeDependencies(deps)
+
+	want := []PluginDependency{
+		{Slug: "woocommerce", IsWPOrg: true, Version: "8.0"},
+		{Slug: "akismet", IsWPOrg: true, Version: "5.0"},
+		{Slug: "my-helper", Path: "/tmp/plugins/my-helper"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("DedupeDependencies() returned %d dependencies, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("DedupeDependencies()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSortDependencies(t *testing.T) {
+	deps := []PluginDependency{
+		{Slug: "main-helper"},
+		{Slug: "late", Order: 10},
+		{Slug: "woocommerce", IsWPOrg: true, Order: -5},
+		{Slug: "other-helper"},
+	}
+
+	got := SortDependencies(deps)
+
+	want := []string{"woocommerce", "main-helper", "other-helper", "late"}
+	for i, slug := range want {
+		if got[i].Slug != slug {
+			t.Errorf("SortDependencies()[%d] = %q, want %q", i, got[i].Slug, slug)
+		}
+	}
+}
+
+func TestBuildDedupesOverlappingDependencies(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	write := func(path, content string) {
+		t.Helper()
+		full := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// helper and main both require woocommerce; main lists it twice
+	write("helper/plugin.properties", "name: Helper\nversion: 1.0.0\nmain: helper.php\nplugins:\n  - woocommerce:8.0\n")
+	write("helper/helper.php", "<?php\n")
+	write("main/plugin.properties", "name: Main\nversion: 1.0.0\nmain: main.php\nplugins:\n  - woocommerce\n  - url: ../helper\n  - woocommerce\n")
+	write("main/main.php", "<?php\n")
+
+	b := New(filepath.Join(tmpDir, "main"))
+	b.Quiet = true
+	if err := b.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	deps := b.GetPluginDependencies()
+	if len(deps) != 2 {
+		t.Fatalf("GetPluginDependencies() = %+v, want woocommerce and helper", deps)
+	}
+	if deps[0].Slug != "woocommerce" || deps[0].Version != "8.0" {
+		t.Errorf("deps[0] = %+v, want woocommerce 8.0", deps[0])
+	}
+	if deps[1].Slug != "helper" || deps[1].Path == "" {
+		t.Errorf("deps[1] = %+v, want built helper plugin", deps[1])
+	}
+
+	if got := b.getRequiresPluginsFromConfig(); got != "woocommerce" {
+		t.Errorf("getRequiresPluginsFromConfig() = %q, want %q", got, "woocommerce")
+	}
+}
+
+func TestFileEntries(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	b := NewBaseBuilder(tmpDir)
+	stageDir := filepath.Join(b.WorkDir, "stage")
+	for path, content := range map[string]string{"z.php": "z", "a/b.php": "hello", "a-b.txt": ""} {
+		full := filepath.Join(stageDir, path)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := b.FileEntries()
+	if err != nil {
+		t.Fatalf("FileEntries() error = %v", err)
+	}
+
+	want := []FileEntry{
+		{Path: "a-b.txt", Size: 0, SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{Path: "a/b.php", Size: 5, SHA256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+		{Path: "z.php", Size: 1, SHA256: "594e519ae499312b29433b7dd8a97ff068defcba9755b6d5d00e84c524d67b06"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("FileEntries() = %+v, want %+v", entries, want)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("FileEntries()[%d] = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+}
+
+func TestWriteAfterInstall(t *testing.T) {
+	var script strings.Builder
+	writeAfterInstall(&script, []string{
+		"wp option update blogdescription 'Demo, live'",
+		"echo done",
+		"wp plugin list --field=name | grep akismet",
+		"wp cache flush && wp rewrite flush",
+	}, false)
+	got := script.String()
+
+	for _, want := range []string{
+		"if [ ! -f " + afterInstallMarker + " ]; then",
+		"    export WP_CLI_ALLOW_ROOT=1\n",
+		"    { wp option update blogdescription 'Demo, live'; } || true\n",
+		"    { echo done; } || true\n",
+		"    { wp plugin list --field=name | grep akismet; } || true\n",
+		"    { wp cache flush && wp rewrite flush; } || true\n",
+		"    touch " + afterInstallMarker + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("script missing %q:\n%s", want, got)
+		}
+	}
+
+	script.Reset()
+	writeAfterInstall(&script, []string{"wp plugin list --allow-root"}, true)
+	if got := script.String(); !strings.Contains(got, "    wp plugin list --allow-root\n") || strings.Contains(got, "|| true") {
+		t.Errorf("strict script should run commands as-is:\n%s", got)
+	}
+
+	script.Reset()
+	writeAfterInstall(&script, nil, false)
+	if script.Len() != 0 {
+		t.Errorf("no commands should write nothing, got:\n%s", script.String())
+	}
+}
+
+func TestWriteUpdateManifest(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	props := "name: My Plugin\nversion: 1.2.3\nmain: my-plugin.php\nrequires: \"6.0\"\nrequires-php: \"8.0\"\ndescription: Does things\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "plugin.properties"), []byte(props), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "my-plugin.php"), []byte("<?php\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := New(tmpDir)
+	b.Quiet = true
+	if err := b.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	path, err := b.WriteUpdateManifest("https://example.com/releases/")
+	if err != nil {
+		t.Fatalf("WriteUpdateManifest() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest UpdateManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.Slug != "my-plugin" || manifest.Version != "1.2.3" {
+		t.Errorf("manifest = %+v, want my-plugin 1.2.3", manifest)
+	}
+	if manifest.DownloadURL != "https://example.com/releases/my-plugin-1.2.3.zip" {
+		t.Errorf("DownloadURL = %q", manifest.DownloadURL)
+	}
+	if manifest.Requires != "6.0" || manifest.RequiresPHP != "8.0" || manifest.Sections["description"] != "Does things" {
+		t.Errorf("manifest = %+v, want requirements and description", manifest)
+	}
+}
+
+func TestImageName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"My Plugin", "my-plugin", false},
+		{"-leading dash", "leading-dash", false},
+		{"trailing -", "trailing", false},
+		{"Double  Space", "double-space", false},
+		{"a -- b", "a-b", false},
+		{"Café Menu", "caf-menu", false},
+		{"", "", true},
+		{"---", "", true},
+		{"!!!", "", true},
+		{"日本語", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ImageName(tt.name)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ImageName(%q) = %q, want error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ImageName(%q) error = %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ImageName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestImageReference(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{"1.2.3", "my-plugin:v1.2.3", false},
+		{"1.2.3-20240101.120000", "my-plugin:v1.2.3-20240101.120000", false},
+		{"1.0.0+build.5", "my-plugin:v1.0.0-build.5", false},
+		{strings.Repeat("9", 200), "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ImageReference("my-plugin", tt.version)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ImageReference(%q) = %q, want error", tt.version, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ImageReference(%q) error = %v", tt.version, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ImageReference(%q) = %q, want %q", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRegistry(t *testing.T) {
+	tests := []struct {
+		push     bool
+		registry string
+		wantErr  bool
+	}{
+		{false, "", false},
+		{true, "ghcr.io/owner", false},
+		{true, "registry.example.com:5000/team", false},
+		{true, "", true},
+		{true, "https://ghcr.io/owner", true},
+		{true, "ghcr.io/owner/", true},
+	}
+
+	for _, tt := range tests {
+		err := checkRegistry(tt.push, tt.registry)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkRegistry(%v, %q) error = %v, wantErr %v", tt.push, tt.registry, err, tt.wantErr)
+		}
+	}
+
+	if got := RegistryReference("ghcr.io/owner", "my-plugin:v1.2.3"); got != "ghcr.io/owner/my-plugin:v1.2.3" {
+		t.Errorf("RegistryReference() = %q, want %q", got, "ghcr.io/owner/my-plugin:v1.2.3")
+	}
+}
+
+func TestGeneratePluginHeaderNetwork(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for _, network := range []bool{false, true} {
+		path := filepath.Join(tmpDir, "plugin.php")
+		if err := os.WriteFile(path, []byte("<?php\n/**\n * Plugin Name: Old\n */\necho 1;\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		b := &Builder{BaseBuilder: BaseBuilder{Version: &version.Version{Major: 1, Minor: 0}}, Config: &config.PluginConfig{Name: "Network Plugin", Network: network}}
+		if err := b.generatePluginHeader(path); err != nil {
+			t.Fatalf("generatePluginHeader() error = %v", err)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(string(content), " * Network: true\n"); got != network {
+			t.Errorf("Network = %v: header contains Network: true = %v\n%s", network, got, content)
+		}
+		if !strings.Contains(string(content), "echo 1;") {
+			t.Errorf("plugin code was not preserved:\n%s", content)
+		}
+	}
+}
+
+func TestSiteBundleSummary(t *testing.T) {
+	s := &SiteDockerBuilder{
+		Quiet: true,
+		SiteConfig: &config.SiteConfig{
+			Plugins: []config.WordPressPlugin{{Slug: "woocommerce"}, {URI: "https://github.com/owner/repo", Slug: "repo"}},
+			Themes:  []config.WordPressTheme{{Slug: "astra"}},
+		},
+	}
+	if got := s.downloadCount(); got != 3 {
+		t.Errorf("downloadCount() = %d, want 3", got)
+	}
+
+	if err := s.bundleSummary(nil); err != nil {
+		t.Errorf("bundleSummary() with no failures = %v, want nil", err)
+	}
+	if err := s.bundleSummary([]string{"plugin repo"}); err != nil {
+		t.Errorf("bundleSummary() without Strict = %v, want nil", err)
+	}
+
+	s.Strict = true
+	err := s.bundleSummary([]string{"plugin repo"})
+	if err == nil || !strings.Contains(err.Error(), "1 of 3") || !strings.Contains(err.Error(), "plugin repo") {
+		t.Errorf("bundleSummary() with Strict = %v, want an error naming plugin repo", err)
+	}
+}
+
+func TestBuildOutputPath(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	srcDir := filepath.Join(tmpDir, "plugin")
+	if err := os.MkdirAll(srcDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "plugin.properties"), []byte("name: Output\nversion: 1.0.0\nmain: output.php\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "output.php"), []byte("<?php\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputPath := filepath.Join(tmpDir, "dist", "nested", "plugin.zip")
+	b := New(srcDir)
+	b.Quiet = true
+	b.OutputPath = outputPath
+	if err := b.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if b.ZipPath != outputPath {
+		t.Errorf("ZipPath = %q, want %q", b.ZipPath, outputPath)
+	}
+	if !config.FileExists(outputPath) {
+		t.Error("zip should be written to OutputPath, creating parent directories")
+	}
+	if config.FileExists(filepath.Join(srcDir, "build", "output-1.0.0.zip")) {
+		t.Error("zip should not also be written to the default location")
+	}
+}
+
+func TestBuildChecksums(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "plugin.properties"), []byte("name: Checksum\nversion: 1.0.0\nmain: checksum.php\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "checksum.php"), []byte("<?php\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := New(tmpDir)
+	b.Quiet = true
+	b.Checksums = []string{"sha256", "md5"}
+	if err := b.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	sum, err := fileSHA256(b.ZipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(b.ZipPath + ".sha256")
+	if err != nil {
+		t.Fatalf("sha256 file not written: %v", err)
+	}
+	if want := sum + "  checksum-1.0.0.zip\n"; string(content) != want {
+		t.Errorf("sha256 file = %q, want %q", content, want)
+	}
+
+	content, err = os.ReadFile(b.ZipPath + ".md5")
+	if err != nil {
+		t.Fatalf("md5 file not written: %v", err)
+	}
+	if fields := strings.Fields(string(content)); len(fields) != 2 || len(fields[0]) != 32 || fields[1] != "checksum-1.0.0.zip" {
+		t.Errorf("md5 file = %q, want <md5>  checksum-1.0.0.zip", content)
+	}
+
+	if _, err := WriteChecksumFile(b.ZipPath, "sha1"); err == nil {
+		t.Error("WriteChecksumFile() should reject an unsupported algorithm")
+	}
+}
+
+func TestBuildGeneratedReadme(t *testing.T) {
+	tests := []struct {
+		name  string
+		props string
+		want  []string
+	}{
+		{
+			"defaults",
+			"name: Readme\nversion: 1.0.0\nmain: readme.php\nauthor: Jane Doe\n",
+			[]string{"Contributors: Jane Doe\n", "Tags: wordpress\n", "Tested up to: 6.4\n"},
+		},
+		{
+			"from properties",
+			"name: Readme\nversion: 1.0.0\nmain: readme.php\nauthor: Jane Doe\ntags: forms, email\ncontributors:\n  - janedoe\n  - acme\ntested-up-to: 6.6\n",
+			[]string{"Contributors: janedoe, acme\n", "Tags: forms, email\n", "Tested up to: 6.6\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir, err := os.MkdirTemp("", "builder_test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(tmpDir)
+
+			if err := os.WriteFile(filepath.Join(tmpDir, "plugin.properties"), []byte(tt.props), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(tmpDir, "readme.php"), []byte("<?php\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			b := New(tmpDir)
+			b.Quiet = true
+			b.StageOnly = true
+			if err := b.Build(); err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(b.WorkDir, "stage", "readme.txt"))
+			if err != nil {
+				t.Fatalf("readme.txt not generated: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("readme.txt missing %q:\n%s", want, content)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteBuildInfo(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	path := filepath.Join(tmpDir, BuildInfoFile)
+	if err := WriteBuildInfo(path, tmpDir, "my-plugin", "1.2.3", "0.9.0"); err != nil {
+		t.Fatalf("WriteBuildInfo() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{"name=my-plugin\n", "version=1.2.3\n", "built=2023-11-14T22:13:20Z\n", "wordsmith=0.9.0\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("BUILD_INFO missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "commit=") {
+		t.Errorf("BUILD_INFO outside a git repository should not have a commit:\n%s", content)
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	if err := WriteBuildInfo(path, tmpDir, "my-plugin", "1.2.3", "0.9.0"); err == nil {
+		t.Error("WriteBuildInfo() should reject an invalid SOURCE_DATE_EPOCH")
+	}
+}
+
+func TestBuildZipFolder(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "plugin.properties"), []byte("name: Folder\nversion: 1.0.0\nmain: folder.php\nzip-folder: folder-pro\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "folder.php"), []byte("<?php\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := New(tmpDir)
+	b.Quiet = true
+	if err := b.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if filepath.Base(b.ZipPath) != "folder-1.0.0.zip" {
+		t.Errorf("zip name = %q, want it named after the slug", filepath.Base(b.ZipPath))
+	}
+	reader, err := zip.OpenReader(b.ZipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	for _, file := range reader.File {
+		if !strings.HasPrefix(file.Name, "folder-pro/") {
+			t.Errorf("zip entry %q should be inside folder-pro/", file.Name)
+		}
+	}
+}
+
+func TestBundledPlugins(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "bundled_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// A zip is named by its root directory, which can differ from the file name
+	writeZip := func(name string, entries ...string) {
+		f, err := os.Create(filepath.Join(tmpDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		w := zip.NewWriter(f)
+		for _, entry := range entries {
+			if _, err := w.Create(entry); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeZip("woocommerce.8.0.0.zip", "woocommerce/", "woocommerce/woocommerce.php")
+	writeZip("hello.zip", "hello.php")
+	if err := os.WriteFile(filepath.Join(tmpDir, "notes.txt"), []byte("not a plugin"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins, err := bundledPlugins(tmpDir)
+	if err != nil {
+		t.Fatalf("bundledPlugins failed: %v", err)
+	}
+	want := []string{"hello", "woocommerce"}
+	if strings.Join(plugins, ",") != strings.Join(want, ",") {
+		t.Errorf("bundledPlugins = %v, want %v", plugins, want)
+	}
+}
+
+func TestBuildVersionFromHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		props  string
+		header string
+		want   string
+	}{
+		{"header version", "", " * Version: 2.3.1\n", "2.3.1"},
+		{"two-part header version", "", " * Version: 2.3\n", "2.3.0"},
+		{"four-part header version", "", " * Version: 2.3.1.4\n", "2.3.1.4"},
+		{"property wins", "version: 1.0.0\n", " * Version: 2.3.1\n", "1.0.0"},
+		{"no header version", "", "", "0.1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Temp directories are outside any git repository, so there's no version tag
+			tmpDir, err := os.MkdirTemp("", "builder_test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(tmpDir)
+
+			props := "name: Header\nmain: header.php\n" + tt.props
+			if err := os.WriteFile(filepath.Join(tmpDir, "plugin.properties"), []byte(props), 0644); err != nil {
+				t.Fatal(err)
+			}
+			main := "<?php\n/**\n * Plugin Name: Header\n" + tt.header + " */\n"
+			if err := os.WriteFile(filepath.Join(tmpDir, "header.php"), []byte(main), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			b := New(tmpDir)
+			b.Quiet = true
+			if err := b.Build(); err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if b.Version.String() != tt.want {
+				t.Errorf("version = %q, want %q", b.Version.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildObfuscateExclude(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	source := "<?php\n// Secret sauce\n$greeting = 'hello';\necho $greeting;\n"
+	writeFiles(t, tmpDir, map[string]string{
+		"plugin.properties":           "name: Hidden\nversion: 1.0.0\nmain: hidden.php\ninclude:\n  - includes\nobfuscate: true\nobfuscate-exclude:\n  - includes/templates/*.php\n",
+		"hidden.php":                  "<?php\n/**\n * Plugin Name: Hidden\n */\n",
+		"includes/logic.php":          source,
+		"includes/templates/view.php": source,
+	})
+
+	b := New(tmpDir)
+	b.Quiet = true
+	b.StageOnly = true
+	if err := b.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	logic, err := os.ReadFile(filepath.Join(b.GetStagePath(), "includes", "logic.php"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(logic), "Secret sauce") {
+		t.Errorf("includes/logic.php should be obfuscated:\n%s", logic)
+	}
+
+	view, err := os.ReadFile(filepath.Join(b.GetStagePath(), "includes", "templates", "view.php"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(view) != source {
+		t.Errorf("includes/templates/view.php matches obfuscate-exclude and should be copied as-is:\n%s", view)
+	}
+}
+
+func TestNewBuildReport(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// A stage directory left over from an earlier build
+	writeFiles(t, tmpDir, map[string]string{
+		"build/work/stage/stale.php": "<?php\n",
+	})
+
+	// Without plugin.properties the build fails before the stage is cleared
+	b := New(tmpDir)
+	b.Quiet = true
+	err = b.Build()
+	if err == nil {
+		t.Fatal("Build() should fail without plugin.properties")
+	}
+	report := b.NewBuildReport("plugin", "", err)
+	if report.Success || report.Phase != PhaseConfig {
+		t.Errorf("report = success %v, phase %q, want a failure in %q", report.Success, report.Phase, PhaseConfig)
+	}
+	if len(report.Files) != 0 {
+		t.Errorf("report.Files = %v, want none from the previous build", report.Files)
+	}
+
+	// A successful build lists what it staged
+	writeFiles(t, tmpDir, map[string]string{
+		"plugin.properties": "name: Report\nversion: 1.0.0\nmain: report.php\n",
+		"report.php":        "<?php\n/**\n * Plugin Name: Report\n */\n",
+	})
+	b = New(tmpDir)
+	b.Quiet = true
+	err = b.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	report = b.NewBuildReport("plugin", "Report", err)
+	if !report.Success || report.Phase != PhaseComplete {
+		t.Errorf("report = success %v, phase %q, want a success in %q", report.Success, report.Phase, PhaseComplete)
+	}
+	files := strings.Join(report.Files, ",")
+	if !strings.Contains(files, "report.php") || strings.Contains(files, "stale.php") {
+		t.Errorf("report.Files = %v, want report.php and not stale.php", report.Files)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version    string
+		patch      int
+		prerelease string
+		build      string
+		want       string
+	}{
+		{"1.2.3", 3, "", "", "1.2.3"},
+		{"1.2.3-beta", 3, "beta", "", "1.2.3-beta"},
+		{"1.2.3-rc.1+build.5", 3, "rc.1", "build.5", "1.2.3-rc.1+build.5"},
+		{"1.2.3+20240101", 3, "", "20240101", "1.2.3+20240101"},
+		{"1.2.3.4", 3, "", "", "1.2.3.4"},
+		{"2.0.1.10-beta", 1, "", "", "2.0.1.10-beta"},
+		{"nightly build", 0, "nightly-build", "", "0.0.0-nightly-build"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			ver := ParseVersion(tt.version)
+			if ver.Patch != tt.patch || ver.Prerelease != tt.prerelease || ver.Build != tt.build {
+				t.Errorf("ParseVersion(%q) = patch %d, prerelease %q, build %q, want %d, %q, %q", tt.version, ver.Patch, ver.Prerelease, ver.Build, tt.patch, tt.prerelease, tt.build)
+			}
+			if ver.String() != tt.want {
+				t.Errorf("String() = %q, want %q", ver.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteVersionProperties(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "version.properties")
+	if err := WriteVersionProperties(path, "Versioned", ParseVersion("1.2.3-beta.1+exp")); err != nil {
+		t.Fatalf("WriteVersionProperties() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"major=1", "minor=2", "patch=3", `prerelease="beta.1"`, `build="exp"`, `maintenance="3-beta.1+exp"`} {
+		if !strings.Contains(string(content), line+"\n") {
+			t.Errorf("version.properties missing %s:\n%s", line, content)
+		}
+	}
+}
+
+func TestCacheArgs(t *testing.T) {
+	inline := "--build-arg BUILDKIT_INLINE_CACHE=1"
+	tests := []struct {
+		name      string
+		cacheFrom []string
+		want      string
+	}{
+		{"no cache images", nil, inline},
+		{"one image", []string{"ghcr.io/owner/my-site:latest"}, inline + " --cache-from ghcr.io/owner/my-site:latest"},
+		{"several images, blanks skipped", []string{"a:latest", " ", "b:v1"}, inline + " --cache-from a:latest --cache-from b:v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(cacheArgs(tt.cacheFrom), " "); got != tt.want {
+				t.Errorf("cacheArgs(%v) = %q, want %q", tt.cacheFrom, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildIgnoreFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{
+		"plugin.properties":         "name: Ignore\nversion: 1.0.0\nmain: ignore.php\ninclude: includes,assets\nexclude: tests\n",
+		".wordsmithignore":          "/assets/src\n*.log\n!keep.log\n",
+		"ignore.php":                "<?php\n",
+		"includes/class-a.php":      "<?php\n",
+		"includes/tests/test-a.php": "<?php\n",
+		"includes/debug.log":        "debug\n",
+		"includes/keep.log":         "keep\n",
+		"assets/app.js":             "console.log(1);\n",
+		"assets/src/app.ts":         "let a = 1;\n",
+		"assets/vendor/src/lib.js":  "console.log(2);\n",
+	}
+	writeFiles(t, tmpDir, files)
+
+	b := New(tmpDir)
+	b.Quiet = true
+	b.StageOnly = true
+	if err := b.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	stageDir := filepath.Join(b.WorkDir, "stage")
+	for _, name := range []string{"includes/class-a.php", "includes/keep.log", "assets/app.js", "assets/vendor/src/lib.js"} {
+		if _, err := os.Stat(filepath.Join(stageDir, name)); err != nil {
+			t.Errorf("%s should be staged: %v", name, err)
+		}
+	}
+	for _, name := range []string{"includes/tests", "includes/debug.log", "assets/src"} {
+		if _, err := os.Stat(filepath.Join(stageDir, name)); err == nil {
+			t.Errorf("%s should not be staged", name)
+		}
+	}
+}
+
+// writeFiles writes files, keyed by path relative to dir, creating any parent
+// directories
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package builder
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ChecksumAlgorithms are the checksum files a build can write next to its zip
+var ChecksumAlgorithms = []string{"sha256", "md5"}
+
+// newChecksumHash returns a hash for a checksum algorithm, or nil if it isn't supported
+func newChecksumHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case "sha256":
+		return sha256.New()
+	case "md5":
+		return md5.New()
+	}
+	return nil
+}
+
+// WriteChecksumFile writes <path>.<algorithm> in the format of sha256sum and
+// md5sum, so the file can be checked with `sha256sum -c`. Returns the path of
+// the checksum file.
+func WriteChecksumFile(path, algorithm string) (string, error) {
+	h := newChecksumHash(algorithm)
+	if h == nil {
+		return "", fmt.Errorf("unsupported checksum %q (use sha256 or md5)", algorithm)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	checksumPath := path + "." + algorithm
+	line := fmt.Sprintf("%s  %s\n", hex.EncodeToString(h.Sum(nil)), filepath.Base(path))
+	if err := os.WriteFile(checksumPath, []byte(line), 0644); err != nil {
+		return "", err
+	}
+	return checksumPath, nil
+}
@@ -1,12 +1,16 @@
 package builder
 
 import (
+	"bufio"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
-// ExpandGlob expands a glob pattern relative to baseDir, supporting ** for recursive matching
+// ExpandGlob expands a glob pattern relative to baseDir, supporting ** for recursive matching.
+// Matches are sorted by slash-separated path so the order is the same on every OS.
 func ExpandGlob(baseDir, pattern string) ([]string, error) {
 	var results []string
 
@@ -119,30 +123,55 @@ func ExpandGlob(baseDir, pattern string) ([]string, error) {
 		}
 	}
 
+	sortPaths(results)
 	return results, nil
 }
 
+// sortPaths sorts relative paths by their slash-separated form, so directory
+// separators sort the same on every OS
+func sortPaths(paths []string) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		return filepath.ToSlash(paths[i]) < filepath.ToSlash(paths[j])
+	})
+}
+
 // containsGlobChars checks if a pattern contains glob special characters
 func containsGlobChars(pattern string) bool {
 	return strings.ContainsAny(pattern, "*?[")
 }
 
-// IsExcluded checks if a path matches any of the exclude patterns
+// IsExcluded checks if a path matches the exclude patterns. Patterns are checked
+// in order and the last match wins, so a pattern starting with "!" re-includes
+// a path excluded by an earlier pattern.
 func IsExcluded(path string, excludes []string) bool {
+	excluded := false
 	for _, pattern := range excludes {
-		if matchPattern(path, pattern) {
-			return true
+		if negated := strings.HasPrefix(pattern, "!"); negated {
+			if excluded && matchPattern(path, pattern[1:]) {
+				excluded = false
+			}
+		} else if !excluded && matchPattern(path, pattern) {
+			excluded = true
 		}
 	}
-	return false
+	return excluded
 }
 
-// matchPattern checks if a path matches a pattern (supports * and **)
+// matchPattern checks if a path matches a pattern (supports * and **). A pattern
+// starting with "/" is anchored to the project root and only matches the full path.
 func matchPattern(path, pattern string) bool {
 	// Normalize path separators
 	path = filepath.ToSlash(path)
 	pattern = filepath.ToSlash(pattern)
 
+	if strings.HasPrefix(pattern, "/") {
+		pattern = strings.TrimPrefix(pattern, "/")
+		if !strings.Contains(pattern, "**") {
+			matched, _ := filepath.Match(pattern, path)
+			return matched
+		}
+	}
+
 	// Handle ** recursive matching
 	if strings.Contains(pattern, "**") {
 		parts := strings.Split(pattern, "**")
@@ -193,7 +222,9 @@ func matchPattern(path, pattern string) bool {
 	return matched
 }
 
-// ExpandIncludes expands all include patterns and returns unique file paths
+// ExpandIncludes expands all include patterns and returns unique file paths.
+// Patterns are expanded in the order they're listed, so the include list sets
+// the overall order; each pattern's matches are sorted.
 func ExpandIncludes(baseDir string, includes []string, excludes []string) ([]string, error) {
 	seen := make(map[string]bool)
 	var results []string
@@ -205,8 +236,8 @@ func ExpandIncludes(baseDir string, includes []string, excludes []string) ([]str
 		}
 
 		for _, path := range expanded {
-			// Skip if excluded
-			if IsExcluded(path, excludes) {
+			// Skip if excluded, or inside an excluded directory
+			if IsExcludedPath(path, excludes) {
 				continue
 			}
 
@@ -222,3 +253,112 @@ func ExpandIncludes(baseDir string, includes []string, excludes []string) ([]str
 
 	return results, nil
 }
+
+// ListFiles returns the files that would be packaged for a project: the main
+// file (if any) followed by every regular file matched by the include patterns.
+func ListFiles(baseDir, main string, includes []string, excludes []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var results []string
+
+	if main != "" {
+		main = filepath.Clean(main)
+		seen[main] = true
+		results = append(results, main)
+	}
+
+	expanded, err := ExpandIncludes(baseDir, includes, excludes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range expanded {
+		if seen[path] {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(baseDir, path))
+		if err != nil || info.IsDir() {
+			continue
+		}
+		seen[path] = true
+		results = append(results, path)
+	}
+
+	return results, nil
+}
+
+// IsExcludedPath checks if a path, or any directory it is in, matches an exclude pattern
+func IsExcludedPath(path string, excludes []string) bool {
+	return IsExcluded(path, excludes) || hasExcludedParent(path, excludes)
+}
+
+// IsIncludedPath checks if a path, or any directory it is in, matches an include
+// pattern, i.e. whether a build with these includes could pick it up
+func IsIncludedPath(path string, includes []string) bool {
+	for dir := filepath.Clean(path); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		for _, pattern := range includes {
+			if matchPattern(dir, filepath.Clean(pattern)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasExcludedParent checks if any parent directory of path matches an exclude pattern
+func hasExcludedParent(path string, excludes []string) bool {
+	for dir := filepath.Dir(path); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		if IsExcluded(dir, excludes) {
+			return true
+		}
+	}
+	return false
+}
+
+// IgnoreFile is the name of the gitignore-style file in a project's source
+// directory whose patterns are added to the configured excludes
+const IgnoreFile = ".wordsmithignore"
+
+// ReadIgnoreFile reads the patterns in dir's .wordsmithignore, one per line.
+// Blank lines and lines starting with # are skipped, a leading \ escapes a
+// literal # or !, and a trailing / is dropped. Returns nil if there is no file.
+func ReadIgnoreFile(dir string) ([]string, error) {
+	f, err := os.Open(filepath.Join(dir, IgnoreFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+			line = line[1:]
+		}
+		if line = strings.TrimRight(line, "/"); line == "" {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
+
+// EffectiveExcludes returns the configured exclude patterns followed by those in
+// dir's .wordsmithignore, so ignore file patterns (including "!" negations) take
+// precedence over exclude
+func EffectiveExcludes(dir string, excludes []string) ([]string, error) {
+	patterns, err := ReadIgnoreFile(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFile, err)
+	}
+	if len(patterns) == 0 {
+		return excludes, nil
+	}
+	return append(append([]string{}, excludes...), patterns...), nil
+}
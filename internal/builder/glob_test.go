@@ -101,6 +101,10 @@ func TestIsExcluded(t *testing.T) {
 		{"recursive exclude", "src/lib/file.php", []string{"**/*.php"}, true},
 		{"multiple excludes match", "file.php", []string{"*.js", "*.php"}, true},
 		{"multiple excludes no match", "file.txt", []string{"*.js", "*.php"}, false},
+		{"negated", "readme.md", []string{"*.md", "!readme.md"}, false},
+		{"negation doesn't match", "changelog.md", []string{"*.md", "!readme.md"}, true},
+		{"excluded again after negation", "readme.md", []string{"*.md", "!readme.md", "readme.*"}, true},
+		{"negation without exclude", "file.php", []string{"!file.php"}, false},
 	}
 
 	for _, tt := range tests {
@@ -113,6 +117,33 @@ func TestIsExcluded(t *testing.T) {
 	}
 }
 
+func TestIsExcludedIncludedPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		patterns []string
+		excluded bool
+		included bool
+	}{
+		{"file in directory", "includes/admin/page.php", []string{"includes"}, true, true},
+		{"top-level glob", "functions.php", []string{"*.php"}, true, true},
+		{"nested file matches by name", "assets/app.js", []string{"*.js"}, true, true},
+		{"directory glob", "assets/css/app.css", []string{"assets/*"}, true, true},
+		{"outside patterns", "tests/test.php", []string{"includes", "*.js"}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsExcludedPath(tt.path, tt.patterns); got != tt.excluded {
+				t.Errorf("IsExcludedPath(%q, %v) = %v, want %v", tt.path, tt.patterns, got, tt.excluded)
+			}
+			if got := IsIncludedPath(tt.path, tt.patterns); got != tt.included {
+				t.Errorf("IsIncludedPath(%q, %v) = %v, want %v", tt.path, tt.patterns, got, tt.included)
+			}
+		})
+	}
+}
+
 func TestMatchPattern(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -127,6 +158,9 @@ func TestMatchPattern(t *testing.T) {
 		{"recursive pattern", "src/lib/file.php", "**/*.php", true},
 		{"path with directory", "src/file.php", "src/*.php", true},
 		{"directory prefix", "build/output.zip", "build/*", true},
+		{"anchored", "vendor", "/vendor", true},
+		{"anchored nested", "lib/vendor", "/vendor", false},
+		{"anchored path", "assets/src", "/assets/src", true},
 	}
 
 	for _, tt := range tests {
@@ -186,3 +220,141 @@ func TestExpandIncludes(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandIncludesOrder(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "expand_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for _, f := range []string{"zeta.php", "beta.php", "alpha.php", "inc/b.php", "inc/a.php"} {
+		path := filepath.Join(tmpDir, f)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("test"), 0644)
+	}
+
+	// zeta.php is listed first, so it stays first; each pattern's matches are sorted
+	results, err := ExpandIncludes(tmpDir, []string{"zeta.php", "**/*.php"}, nil)
+	if err != nil {
+		t.Fatalf("ExpandIncludes() error = %v", err)
+	}
+	expected := []string{"zeta.php", "alpha.php", "beta.php", "inc/a.php", "inc/b.php"}
+	if len(results) != len(expected) {
+		t.Fatalf("ExpandIncludes() = %v, want %v", results, expected)
+	}
+	for i := range expected {
+		if filepath.ToSlash(results[i]) != expected[i] {
+			t.Errorf("ExpandIncludes() = %v, want %v", results, expected)
+			break
+		}
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "list_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := []string{
+		"plugin.php",
+		"includes/class-a.php",
+		"includes/class-b.php",
+		"includes/tests/test-a.php",
+		"assets/app.js",
+	}
+
+	for _, f := range files {
+		path := filepath.Join(tmpDir, f)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("test"), 0644)
+	}
+
+	results, err := ListFiles(tmpDir, "plugin.php", []string{"includes", "assets", "*.php"}, []string{"tests"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"plugin.php",
+		filepath.Join("includes", "class-a.php"),
+		filepath.Join("includes", "class-b.php"),
+		filepath.Join("assets", "app.js"),
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("ListFiles() = %v, want %v", results, expected)
+	}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Errorf("ListFiles()[%d] = %q, want %q", i, results[i], expected[i])
+		}
+	}
+}
+
+func TestExpandIncludesNestedExcludes(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "expand_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for _, f := range []string{"assets/app.js", "assets/src/app.ts", "assets/src/lib/util.ts", "assets/vendor/readme.md"} {
+		path := filepath.Join(tmpDir, f)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("test"), 0644)
+	}
+
+	// Everything under an excluded directory is left out, even if re-included by name
+	results, err := ExpandIncludes(tmpDir, []string{"assets"}, []string{"src", "*.md", "!util.ts"})
+	if err != nil {
+		t.Fatalf("ExpandIncludes() error = %v", err)
+	}
+	expected := []string{"assets", "assets/app.js", "assets/vendor"}
+	if len(results) != len(expected) {
+		t.Fatalf("ExpandIncludes() = %v, want %v", results, expected)
+	}
+	for i := range expected {
+		if filepath.ToSlash(results[i]) != expected[i] {
+			t.Errorf("ExpandIncludes() = %v, want %v", results, expected)
+			break
+		}
+	}
+}
+
+func TestEffectiveExcludes(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "ignore_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	excludes, err := EffectiveExcludes(tmpDir, []string{"tests"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(excludes) != 1 || excludes[0] != "tests" {
+		t.Errorf("EffectiveExcludes() without an ignore file = %v, want [tests]", excludes)
+	}
+
+	ignore := "# Development files\n\nnode_modules/\n*.md\n!readme.md\n\\#notes.txt\n  /docs/  \n"
+	if err := os.WriteFile(filepath.Join(tmpDir, IgnoreFile), []byte(ignore), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	excludes, err = EffectiveExcludes(tmpDir, []string{"tests"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"tests", "node_modules", "*.md", "!readme.md", "#notes.txt", "/docs"}
+	if len(excludes) != len(expected) {
+		t.Fatalf("EffectiveExcludes() = %q, want %q", excludes, expected)
+	}
+	for i := range expected {
+		if excludes[i] != expected[i] {
+			t.Errorf("EffectiveExcludes()[%d] = %q, want %q", i, excludes[i], expected[i])
+		}
+	}
+}
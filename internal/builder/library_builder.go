@@ -24,6 +24,7 @@ func NewLibraryBuilder(sourceDir string) *LibraryBuilder {
 
 // Build builds the library
 func (b *LibraryBuilder) Build() error {
+	b.SetPhase(PhaseConfig)
 	if !b.Quiet {
 		ui.PrintInfo("Loading library.properties...")
 	}
@@ -33,7 +34,13 @@ func (b *LibraryBuilder) Build() error {
 	}
 	b.Config = cfg
 
+	// .wordsmithignore patterns apply on top of exclude
+	if b.Config.Exclude, err = EffectiveExcludes(b.SourceDir, cfg.Exclude); err != nil {
+		return err
+	}
+
 	// Parse version
+	b.SetPhase(PhaseVersion)
 	if cfg.Version != "" {
 		b.Version = ParseVersion(cfg.Version)
 	} else {
@@ -57,6 +64,7 @@ func (b *LibraryBuilder) Build() error {
 
 	slug := b.GetLibrarySlug()
 
+	b.SetPhase(PhaseCopy)
 	if !b.Quiet {
 		ui.PrintInfo("Copying library files...")
 	}
@@ -88,6 +96,7 @@ func (b *LibraryBuilder) Build() error {
 	}
 
 	// Copy libraries to stage directory
+	b.SetPhase(PhaseLibraries)
 	if len(b.Config.Libraries) > 0 {
 		if !b.Quiet {
 			ui.PrintInfo("Copying libraries...")
@@ -98,27 +107,12 @@ func (b *LibraryBuilder) Build() error {
 	}
 
 	// Clean dev files
-	CleanDevFiles(stageDir)
-
-	// Set permissions
-	if err := ChmodAll(stageDir, 0777); err != nil {
-		return fmt.Errorf("failed to set permissions: %w", err)
-	}
-
-	// Create ZIP
-	if !b.Quiet {
-		ui.PrintInfo("Creating ZIP archive...")
-	}
-	zipPath := filepath.Join(b.BuildDir, fmt.Sprintf("%s-%s.zip", slug, b.Version.String()))
-	if err := CreateZip(stageDir, zipPath, slug); err != nil {
-		return fmt.Errorf("failed to create ZIP: %w", err)
-	}
-
-	if !b.Quiet {
-		fmt.Println()
-		ui.PrintSuccess("Created: %s", filepath.Base(zipPath))
+	b.SetPhase(PhasePackage)
+	if err := b.Package(stageDir, slug); err != nil {
+		return err
 	}
 
+	b.SetPhase(PhaseComplete)
 	return nil
 }
 
@@ -0,0 +1,59 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"wordsmith/internal/config"
+)
+
+// PatternsDir is where block themes keep their block patterns
+const PatternsDir = "patterns"
+
+// patternRequiredFields are the header fields WordPress needs to register a pattern
+var patternRequiredFields = []string{"Title", "Slug"}
+
+// IsBlockTheme checks if the theme in dir is a block theme, the way WordPress
+// decides: it has a templates/index.html
+func IsBlockTheme(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "templates", "index.html"))
+	return err == nil && !info.IsDir()
+}
+
+// PatternProblem is a block pattern file missing a required header field
+type PatternProblem struct {
+	File  string // Path relative to the theme, e.g. patterns/hero.php
+	Field string
+}
+
+func (p PatternProblem) String() string {
+	return fmt.Sprintf("%s: missing required %q header", p.File, p.Field)
+}
+
+// CheckPatterns checks that every PHP file in dir's patterns/ directory has
+// the Title and Slug header WordPress needs to register it. WordPress skips
+// patterns without them, so they'd be missing from the editor without an error.
+func CheckPatterns(dir string) ([]PatternProblem, error) {
+	files, err := filepath.Glob(filepath.Join(dir, PatternsDir, "*.php"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var problems []PatternProblem
+	for _, file := range files {
+		header, err := config.ReadFileHeader(file, patternRequiredFields)
+		if err != nil {
+			return nil, err
+		}
+		rel := filepath.ToSlash(filepath.Join(PatternsDir, filepath.Base(file)))
+		for _, field := range patternRequiredFields {
+			if header[field] == "" {
+				problems = append(problems, PatternProblem{rel, field})
+			}
+		}
+	}
+	return problems, nil
+}
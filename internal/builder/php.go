@@ -0,0 +1,149 @@
+package builder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// phpExtension describes how to install a PHP extension in the official WordPress image
+type phpExtension struct {
+	pecl      bool     // Installed with pecl instead of docker-php-ext-install
+	configure string   // Arguments for docker-php-ext-configure, if needed
+	packages  []string // Build dependencies installed with apt-get
+}
+
+// phpExtensions lists the extensions wordsmith knows how to install
+var phpExtensions = map[string]phpExtension{
+	"bcmath":    {},
+	"bz2":       {packages: []string{"libbz2-dev"}},
+	"calendar":  {},
+	"exif":      {},
+	"gd":        {configure: "--with-freetype --with-jpeg --with-webp", packages: []string{"libfreetype6-dev", "libjpeg62-turbo-dev", "libpng-dev", "libwebp-dev"}},
+	"gettext":   {},
+	"gmp":       {packages: []string{"libgmp-dev"}},
+	"intl":      {packages: []string{"libicu-dev"}},
+	"mysqli":    {},
+	"opcache":   {},
+	"pcntl":     {},
+	"pdo_mysql": {},
+	"soap":      {packages: []string{"libxml2-dev"}},
+	"sockets":   {},
+	"xsl":       {packages: []string{"libxslt1-dev"}},
+	"zip":       {packages: []string{"libzip-dev"}},
+	"apcu":      {pecl: true},
+	"imagick":   {pecl: true, packages: []string{"libmagickwand-dev"}},
+	"memcached": {pecl: true, packages: []string{"libmemcached-dev", "zlib1g-dev", "libssl-dev"}},
+	"mongodb":   {pecl: true, packages: []string{"libssl-dev"}},
+	"redis":     {pecl: true},
+	"xdebug":    {pecl: true},
+}
+
+// KnownPHPExtensions returns the names of the extensions that can be installed, sorted
+func KnownPHPExtensions() []string {
+	names := make([]string, 0, len(phpExtensions))
+	for name := range phpExtensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PHPExtensionCommands returns the shell commands that install the named PHP
+// extensions, along with any names it doesn't know how to install. Extensions
+// already loaded in the image are skipped.
+func PHPExtensionCommands(names []string) ([]string, []string) {
+	var commands, packages, unknown []string
+	seenPackage := make(map[string]bool)
+	var install []string
+
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		ext, ok := phpExtensions[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+
+		for _, pkg := range ext.packages {
+			if !seenPackage[pkg] {
+				seenPackage[pkg] = true
+				packages = append(packages, pkg)
+			}
+		}
+
+		step := fmt.Sprintf("docker-php-ext-install %s", name)
+		if ext.pecl {
+			step = fmt.Sprintf("printf '\\n' | pecl install %s && docker-php-ext-enable %s", name, name)
+		} else if ext.configure != "" {
+			step = fmt.Sprintf("docker-php-ext-configure %s %s && %s", name, ext.configure, step)
+		}
+		install = append(install, fmt.Sprintf("(php -m | grep -qiw %s || (%s))", name, step))
+	}
+
+	if len(install) == 0 {
+		return nil, unknown
+	}
+
+	if len(packages) > 0 {
+		commands = append(commands, "apt-get update")
+		commands = append(commands, "apt-get install -y --no-install-recommends "+strings.Join(packages, " "))
+	}
+	commands = append(commands, install...)
+	if len(packages) > 0 {
+		commands = append(commands, "rm -rf /var/lib/apt/lists/*")
+	}
+
+	return commands, unknown
+}
+
+// writePHPExtensions adds a RUN step installing the given PHP extensions to a Dockerfile
+func writePHPExtensions(dockerfile *strings.Builder, names []string) []string {
+	commands, unknown := PHPExtensionCommands(names)
+	if len(commands) > 0 {
+		dockerfile.WriteString("# Install PHP extensions\n")
+		dockerfile.WriteString("RUN " + strings.Join(commands, " \\\n    && ") + "\n\n")
+	}
+	return unknown
+}
+
+// PHPLimitsFile is where the upload and memory limit settings are written in the container
+const PHPLimitsFile = "/usr/local/etc/php/conf.d/wordsmith-limits.ini"
+
+// PHPLimitsINI returns the php.ini settings for the upload-limit and memory-limit
+// options, or "" if neither is set. post_max_size follows upload_max_filesize, and
+// max_execution_time is raised so large uploads and imports don't time out.
+func PHPLimitsINI(uploadLimit, memoryLimit string) string {
+	if uploadLimit == "" && memoryLimit == "" {
+		return ""
+	}
+
+	var ini strings.Builder
+	if uploadLimit != "" {
+		ini.WriteString("upload_max_filesize = " + uploadLimit + "\n")
+		ini.WriteString("post_max_size = " + uploadLimit + "\n")
+	}
+	if memoryLimit != "" {
+		ini.WriteString("memory_limit = " + memoryLimit + "\n")
+	}
+	ini.WriteString("max_execution_time = 300\n")
+	return ini.String()
+}
+
+// writePHPLimits adds a RUN step writing the upload and memory limit settings to a Dockerfile
+func writePHPLimits(dockerfile *strings.Builder, uploadLimit, memoryLimit string) {
+	ini := PHPLimitsINI(uploadLimit, memoryLimit)
+	if ini == "" {
+		return
+	}
+
+	var echoes []string
+	for _, line := range strings.Split(strings.TrimSuffix(ini, "\n"), "\n") {
+		echoes = append(echoes, "echo '"+line+"'")
+	}
+	dockerfile.WriteString("# Raise PHP upload and memory limits\n")
+	dockerfile.WriteString("RUN { " + strings.Join(echoes, "; ") + "; } > " + PHPLimitsFile + "\n\n")
+}
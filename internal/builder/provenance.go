@@ -0,0 +1,70 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BuildInfoFile is the provenance file written to the root of the package
+const BuildInfoFile = "BUILD_INFO"
+
+// WriteBuildInfo writes the provenance of a build: the git commit and branch of
+// srcDir, the build time, and the wordsmith version. The build time comes from
+// SOURCE_DATE_EPOCH when set, so reproducible builds stay byte-for-byte identical.
+func WriteBuildInfo(path, srcDir, name, version, toolVersion string) error {
+	built, err := buildTime()
+	if err != nil {
+		return err
+	}
+
+	lines := []string{
+		"# Build provenance",
+		"# Generated by wordsmith",
+		"",
+		"name=" + name,
+		"version=" + version,
+	}
+	if commit := gitOutput(srcDir, "rev-parse", "HEAD"); commit != "" {
+		lines = append(lines, "commit="+commit)
+		if branch := gitOutput(srcDir, "rev-parse", "--abbrev-ref", "HEAD"); branch != "" && branch != "HEAD" {
+			lines = append(lines, "branch="+branch)
+		}
+		if status := gitOutput(srcDir, "status", "--porcelain"); status != "" {
+			lines = append(lines, "dirty=true")
+		}
+	}
+	lines = append(lines, "built="+built.UTC().Format(time.RFC3339))
+	if toolVersion != "" {
+		lines = append(lines, "wordsmith="+toolVersion)
+	}
+
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// buildTime returns SOURCE_DATE_EPOCH if set, otherwise the current time
+func buildTime() (time.Time, error) {
+	epoch := strings.TrimSpace(os.Getenv("SOURCE_DATE_EPOCH"))
+	if epoch == "" {
+		return time.Now(), nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: expected Unix seconds", epoch)
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// gitOutput runs a git command in dir and returns its trimmed output, or "" on error
+func gitOutput(dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,165 @@
+package builder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Build phases recorded in build reports
+const (
+	PhaseConfig       = "config"
+	PhaseVersion      = "version"
+	PhaseCopy         = "copy"
+	PhaseProcess      = "process"
+	PhaseMetadata     = "metadata"
+	PhaseLibraries    = "libraries"
+	PhaseDependencies = "dependencies"
+	PhasePackage      = "package"
+	PhaseComplete     = "complete"
+)
+
+// phaseOrder lists the build phases in the order a build runs them
+var phaseOrder = []string{PhaseConfig, PhaseVersion, PhaseCopy, PhaseProcess, PhaseMetadata, PhaseLibraries, PhaseDependencies, PhasePackage, PhaseComplete}
+
+// phaseReached reports whether phase is target or a later phase
+func phaseReached(phase, target string) bool {
+	for _, p := range phaseOrder {
+		if p == target {
+			return true
+		}
+		if p == phase {
+			return false
+		}
+	}
+	return false
+}
+
+// BuildReport is a machine-readable summary of a build, written on success and failure
+type BuildReport struct {
+	Success bool     `json:"success"`
+	Type    string   `json:"type"`
+	Name    string   `json:"name,omitempty"`
+	Version string   `json:"version,omitempty"`
+	Phase   string   `json:"phase"`
+	Error   string   `json:"error,omitempty"`
+	Output  string   `json:"output,omitempty"`
+	Files   []string `json:"files"`
+
+	// Per-file sizes and checksums, included with --filelist-hashes
+	FileHashes []FileEntry `json:"fileHashes,omitempty"`
+}
+
+// FileEntry records a staged file's size and SHA-256 checksum
+type FileEntry struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// NewBuildReport creates a report from the builder state after Build returns
+func (b *BaseBuilder) NewBuildReport(projectType, name string, buildErr error) *BuildReport {
+	report := &BuildReport{
+		Success: buildErr == nil,
+		Type:    projectType,
+		Name:    name,
+		Phase:   b.Phase,
+		Output:  b.ZipPath,
+		Files:   []string{},
+	}
+	if b.Version != nil {
+		report.Version = b.Version.String()
+	}
+	if buildErr != nil {
+		report.Error = buildErr.Error()
+	}
+
+	// The stage directory is only cleared once the copy phase starts; before that
+	// it holds the previous build's files
+	if !phaseReached(b.Phase, PhaseCopy) {
+		return report
+	}
+
+	// Record every file that made it into the stage directory
+	stageDir := filepath.Join(b.WorkDir, "stage")
+	filepath.Walk(stageDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		if rel, err := filepath.Rel(stageDir, path); err == nil {
+			report.Files = append(report.Files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+
+	return report
+}
+
+// FileEntries returns the path, size, and SHA-256 of every file in the stage
+// directory, sorted by path
+func (b *BaseBuilder) FileEntries() ([]FileEntry, error) {
+	stageDir := filepath.Join(b.WorkDir, "stage")
+	entries := []FileEntry{}
+
+	err := filepath.Walk(stageDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(stageDir, path)
+		if err != nil {
+			return err
+		}
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, FileEntry{Path: filepath.ToSlash(rel), Size: info.Size(), SHA256: sum})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash staged files: %w", err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+// fileSHA256 returns the hex SHA-256 checksum of a file
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Write writes the report as indented JSON
+func (r *BuildReport) Write(path string) error {
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode build report: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write build report: %w", err)
+	}
+	return nil
+}
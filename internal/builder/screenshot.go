@@ -0,0 +1,130 @@
+package builder
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// screenshotNames are the theme screenshot files WordPress recognizes
+var screenshotNames = []string{"screenshot.png", "screenshot.jpg", "screenshot.jpeg", "screenshot.gif", "screenshot.webp"}
+
+// Placeholder screenshot size, the 4:3 size recommended by WordPress
+const (
+	screenshotWidth  = 1200
+	screenshotHeight = 900
+)
+
+// glyphs is a 5x7 bitmap font for placeholder screenshots. Lowercase letters are
+// drawn as uppercase and characters without a glyph are drawn as spaces.
+var glyphs = map[rune][7]string{
+	'A': {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'B': {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+	'C': {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+	'D': {"####.", "#...#", "#...#", "#...#", "#...#", "#...#", "####."},
+	'E': {"#####", "#....", "#....", "####.", "#....", "#....", "#####"},
+	'F': {"#####", "#....", "#....", "####.", "#....", "#....", "#...."},
+	'G': {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".####"},
+	'H': {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'I': {".###.", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'J': {"..###", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+	'K': {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+	'L': {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+	'M': {"#...#", "##.##", "#.#.#", "#.#.#", "#...#", "#...#", "#...#"},
+	'N': {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+	'O': {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'P': {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+	'Q': {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'R': {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+	'S': {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+	'T': {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'U': {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'V': {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W': {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+	'X': {"#...#", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#"},
+	'Y': {"#...#", "#...#", ".#.#.", "..#..", "..#..", "..#..", "..#.."},
+	'Z': {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+	'0': {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9': {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'.': {".....", ".....", ".....", ".....", ".....", ".##..", ".##.."},
+	'-': {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+}
+
+// HasScreenshot checks if a theme directory contains a screenshot WordPress can display
+func HasScreenshot(dir string) bool {
+	for _, name := range screenshotNames {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateScreenshot writes a placeholder screenshot.png showing the theme name and version
+func GenerateScreenshot(path, name, version string) error {
+	img := image.NewRGBA(image.Rect(0, 0, screenshotWidth, screenshotHeight))
+	background := color.RGBA{0x1E, 0x29, 0x3B, 0xFF}
+	for y := 0; y < screenshotHeight; y++ {
+		for x := 0; x < screenshotWidth; x++ {
+			img.Set(x, y, background)
+		}
+	}
+
+	drawText(img, name, screenshotHeight/2-60, 16, color.RGBA{0xFF, 0xFF, 0xFF, 0xFF})
+	drawText(img, "v"+version, screenshotHeight/2+80, 8, color.RGBA{0x94, 0xA3, 0xB8, 0xFF})
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, img)
+}
+
+// drawText draws a line of text centered horizontally with its middle at centerY,
+// shrinking the scale until it fits the image width
+func drawText(img *image.RGBA, text string, centerY, scale int, c color.Color) {
+	text = strings.ToUpper(text)
+	chars := []rune(text)
+	if len(chars) == 0 {
+		return
+	}
+
+	// Each glyph is 5 pixels wide plus 1 pixel of spacing
+	margin := screenshotWidth / 12
+	for scale > 1 && len(chars)*6*scale > screenshotWidth-2*margin {
+		scale--
+	}
+
+	x := (screenshotWidth - (len(chars)*6-1)*scale) / 2
+	y := centerY - 7*scale/2
+	for _, ch := range chars {
+		glyph, ok := glyphs[ch]
+		if ok {
+			for row, line := range glyph {
+				for col, pixel := range line {
+					if pixel != '#' {
+						continue
+					}
+					for dy := 0; dy < scale; dy++ {
+						for dx := 0; dx < scale; dx++ {
+							img.Set(x+col*scale+dx, y+row*scale+dy, c)
+						}
+					}
+				}
+			}
+		}
+		x += 6 * scale
+	}
+}
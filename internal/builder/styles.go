@@ -0,0 +1,66 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StylesDir is where block themes keep their style variations
+const StylesDir = "styles"
+
+// StyleVariationProblem is a style variation that WordPress can't load
+type StyleVariationProblem struct {
+	File    string // Path relative to the theme, e.g. styles/dark.json
+	Message string
+}
+
+func (p StyleVariationProblem) String() string {
+	return fmt.Sprintf("%s: %s", p.File, p.Message)
+}
+
+// CheckStyleVariations checks that every JSON file under dir's styles/
+// directory is a JSON object. WordPress silently drops variations it can't
+// parse, so they'd be missing from the Site Editor without an error.
+func CheckStyleVariations(dir string) ([]StyleVariationProblem, error) {
+	var problems []StyleVariationProblem
+	root := filepath.Join(dir, StylesDir)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(path), ".json") {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		var variation map[string]interface{}
+		if err := json.Unmarshal(content, &variation); err != nil {
+			problems = append(problems, StyleVariationProblem{filepath.ToSlash(rel), jsonErrorMessage(content, err)})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+// jsonErrorMessage describes a JSON decoding error, with the line of a syntax error
+func jsonErrorMessage(content []byte, err error) string {
+	if syntaxErr, ok := err.(*json.SyntaxError); ok {
+		line := 1 + strings.Count(string(content[:syntaxErr.Offset]), "\n")
+		return fmt.Sprintf("invalid JSON on line %d: %v", line, err)
+	}
+	return fmt.Sprintf("invalid JSON: %v", err)
+}
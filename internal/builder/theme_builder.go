@@ -2,7 +2,6 @@ package builder
 
 import (
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -16,7 +15,8 @@ import (
 // ThemeBuilder builds WordPress themes
 type ThemeBuilder struct {
 	BaseBuilder
-	Config *config.ThemeConfig
+	Config             *config.ThemeConfig
+	GenerateScreenshot bool // Create a placeholder screenshot.png if the theme has none
 }
 
 // NewThemeBuilder creates a new theme Builder
@@ -28,6 +28,7 @@ func NewThemeBuilder(sourceDir string) *ThemeBuilder {
 
 // Build builds the theme
 func (b *ThemeBuilder) Build() error {
+	b.SetPhase(PhaseConfig)
 	if !b.Quiet {
 		ui.PrintInfo("Loading theme.properties...")
 	}
@@ -36,12 +37,47 @@ func (b *ThemeBuilder) Build() error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 	b.Config = cfg
+	b.ZipFolder = cfg.ZipFolder
+
+	// .wordsmithignore patterns apply on top of exclude
+	if b.Config.Exclude, err = EffectiveExcludes(b.SourceDir, cfg.Exclude); err != nil {
+		return err
+	}
+
+	// Block themes ship their patterns/ and styles/ directories whether or not
+	// they're listed in include
+	if IsBlockTheme(b.SourceDir) {
+		for _, name := range []string{PatternsDir, StylesDir} {
+			if info, err := os.Stat(filepath.Join(b.SourceDir, name)); err == nil && info.IsDir() && !IsIncludedPath(name, b.Config.Include) {
+				b.Config.Include = append(b.Config.Include, name)
+			}
+		}
+	}
+	if b.ships(PatternsDir) {
+		problems, err := CheckPatterns(b.SourceDir)
+		if err != nil {
+			return fmt.Errorf("failed to read block patterns: %w", err)
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("invalid block pattern %s", problems[0])
+		}
+	}
+	if b.ships(StylesDir) {
+		problems, err := CheckStyleVariations(b.SourceDir)
+		if err != nil {
+			return fmt.Errorf("failed to read style variations: %w", err)
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("invalid style variation %s", problems[0])
+		}
+	}
 
 	// Parse version
+	b.SetPhase(PhaseVersion)
 	if cfg.Version != "" {
 		b.Version = ParseVersion(cfg.Version)
 	} else {
-		ver, err := b.GetVersionFromGit()
+		ver, err := b.GetVersionFromGitOrHeader(filepath.Join(b.SourceDir, cfg.Main))
 		if err != nil {
 			return err
 		}
@@ -61,6 +97,7 @@ func (b *ThemeBuilder) Build() error {
 
 	themeName := b.GetThemeSlug()
 
+	b.SetPhase(PhaseCopy)
 	if !b.Quiet {
 		ui.PrintInfo("Copying theme files...")
 	}
@@ -95,7 +132,7 @@ func (b *ThemeBuilder) Build() error {
 			}
 		} else {
 			dst := filepath.Join(stageDir, include)
-			if b.Config.Minify && (strings.HasSuffix(include, ".css") || strings.HasSuffix(include, ".js")) {
+			if b.Config.Minify && ShouldMinify(include, b.Config.MinifyExclude) {
 				if err := CopyAndMinify(src, dst, true); err != nil {
 					return fmt.Errorf("failed to minify file %s: %w", include, err)
 				}
@@ -107,7 +144,32 @@ func (b *ThemeBuilder) Build() error {
 		}
 	}
 
+	// Templates and functions.php get the same version constant replacement as plugins
+	if err := b.replaceVersionConstants(stageDir); err != nil {
+		return fmt.Errorf("failed to update version constants: %w", err)
+	}
+
+	// Declare the supports from theme.properties in functions.php
+	if err := addThemeSupports(filepath.Join(stageDir, "functions.php"), b.Config.Supports); err != nil {
+		return fmt.Errorf("failed to add theme supports: %w", err)
+	}
+
+	// WordPress shows the screenshot as the theme's thumbnail in wp-admin
+	if !HasScreenshot(stageDir) {
+		if b.GenerateScreenshot {
+			if err := GenerateScreenshot(filepath.Join(stageDir, "screenshot.png"), b.Config.Name, b.Version.String()); err != nil {
+				return fmt.Errorf("failed to generate screenshot: %w", err)
+			}
+			if !b.Quiet {
+				ui.PrintInfo("Generated placeholder screenshot.png")
+			}
+		} else {
+			ui.PrintWarning("No screenshot.png in the theme; wp-admin will show a blank thumbnail (use --generate-screenshot for a placeholder)")
+		}
+	}
+
 	// Generate theme header in style.css
+	b.SetPhase(PhaseMetadata)
 	if !b.Quiet {
 		ui.PrintInfo("Generating theme header...")
 	}
@@ -128,6 +190,7 @@ func (b *ThemeBuilder) Build() error {
 	}
 
 	// Copy libraries to stage directory
+	b.SetPhase(PhaseLibraries)
 	if len(b.Config.Libraries) > 0 {
 		if !b.Quiet {
 			ui.PrintInfo("Copying libraries...")
@@ -138,6 +201,7 @@ func (b *ThemeBuilder) Build() error {
 	}
 
 	// Fetch parent theme if template-uri is specified
+	b.SetPhase(PhaseDependencies)
 	if b.Config.TemplateURI != "" {
 		if !b.Quiet {
 			ui.PrintInfo("Fetching parent theme...")
@@ -153,28 +217,37 @@ func (b *ThemeBuilder) Build() error {
 	}
 
 	// Clean dev files
-	CleanDevFiles(stageDir)
-
-	// Set permissions on all files before zipping
-	if err := ChmodAll(stageDir, 0777); err != nil {
-		return fmt.Errorf("failed to set permissions: %w", err)
+	b.SetPhase(PhasePackage)
+	if err := b.Package(stageDir, themeName); err != nil {
+		return err
 	}
 
-	// Create ZIP
-	if !b.Quiet {
-		ui.PrintInfo("Creating ZIP archive...")
-	}
-	zipPath := filepath.Join(b.BuildDir, fmt.Sprintf("%s-%s.zip", themeName, b.Version.String()))
-	if err := CreateZip(stageDir, zipPath, themeName); err != nil {
-		return fmt.Errorf("failed to create ZIP: %w", err)
-	}
+	b.SetPhase(PhaseComplete)
+	return nil
+}
 
-	if !b.Quiet {
-		fmt.Println()
-		ui.PrintSuccess("Created: %s", filepath.Base(zipPath))
-	}
+// replaceVersionConstants updates define('<THEME>_VERSION', ...) in the staged PHP files
+func (b *ThemeBuilder) replaceVersionConstants(stageDir string) error {
+	return filepath.Walk(stageDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(info.Name(), ".php") {
+			return err
+		}
 
-	return nil
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		updated := replaceVersionConstant(string(content), b.Config.Name, b.Version.String())
+		if updated == string(content) {
+			return nil
+		}
+		return os.WriteFile(path, []byte(updated), info.Mode())
+	})
+}
+
+// ships checks if the theme's include and exclude patterns package a directory
+func (b *ThemeBuilder) ships(dir string) bool {
+	return IsIncludedPath(dir, b.Config.Include) && !IsExcludedPath(dir, b.Config.Exclude)
 }
 
 // GetThemeSlug returns the WordPress theme slug (directory name) for this theme.
@@ -198,9 +271,13 @@ func (b *ThemeBuilder) copyDirWithExcludes(src, dst string, excludes []string) e
 		if err != nil {
 			return err
 		}
+		projectRel, err := filepath.Rel(b.SourceDir, path)
+		if err != nil {
+			return err
+		}
 
-		// Check if excluded
-		if IsExcluded(relPath, excludes) {
+		// Check if excluded, relative to the project like the include patterns
+		if IsExcluded(projectRel, excludes) {
 			if info.IsDir() {
 				return filepath.SkipDir
 			}
@@ -213,7 +290,7 @@ func (b *ThemeBuilder) copyDirWithExcludes(src, dst string, excludes []string) e
 			return os.MkdirAll(targetPath, info.Mode())
 		}
 
-		if b.Config.Minify && (strings.HasSuffix(info.Name(), ".css") || strings.HasSuffix(info.Name(), ".js")) {
+		if b.Config.Minify && ShouldMinify(projectRel, b.Config.MinifyExclude) {
 			return CopyAndMinify(path, targetPath, true)
 		}
 		return CopyFile(path, targetPath)
@@ -328,11 +405,6 @@ func (b *ThemeBuilder) writeThemeProperties(path string) error {
 	return os.WriteFile(path, []byte(content), 0644)
 }
 
-// GetStagePath returns the path to the staged theme directory
-func (b *ThemeBuilder) GetStagePath() string {
-	return filepath.Join(b.WorkDir, "stage")
-}
-
 // GetThemeName returns the sanitized theme name
 func (b *ThemeBuilder) GetThemeName() string {
 	return SanitizeName(b.Config.Name)
@@ -495,9 +567,9 @@ func (b *ThemeBuilder) fetchParentTheme() error {
 		srcPath = filepath.Join(b.SourceDir, uri)
 	}
 
-	// Check if it's a zip file
-	if strings.HasSuffix(strings.ToLower(srcPath), ".zip") {
-		return ExtractZip(srcPath, parentDir)
+	// Check if it's a tar.gz or zip file
+	if config.IsArchiveName(srcPath) {
+		return extractThemeArchive(srcPath, parentDir)
 	}
 
 	// It's a directory - check if it has theme.properties (needs to be built)
@@ -550,18 +622,19 @@ func (b *ThemeBuilder) fetchParentTheme() error {
 
 func (b *ThemeBuilder) downloadAndExtractTheme(url, destDir string) error {
 	// Download to temp file
-	resp, err := http.Get(url)
+	ui.EmitEvent("info", "Downloading %s", url)
+	resp, err := config.HTTPGet(url)
 	if err != nil {
 		return fmt.Errorf("failed to download: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status: %s", resp.Status)
+		return fmt.Errorf("download failed with status: %s%s", resp.Status, config.GitHubAuthHint(url, resp.StatusCode))
 	}
 
-	// Create temp file for zip
-	tmpFile, err := os.CreateTemp("", "theme-*.zip")
+	// Create temp file for the archive
+	tmpFile, err := os.CreateTemp("", config.TempPrefix+"theme-*")
 	if err != nil {
 		return fmt.Errorf("failed to create temp file: %w", err)
 	}
@@ -569,11 +642,19 @@ func (b *ThemeBuilder) downloadAndExtractTheme(url, destDir string) error {
 	defer tmpFile.Close()
 
 	// Copy response to temp file
-	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
+	if _, err := config.CopyArchiveDownload(tmpFile, resp); err != nil {
 		return fmt.Errorf("failed to save download: %w", err)
 	}
 	tmpFile.Close()
 
-	// Extract the zip
-	return ExtractZip(tmpFile.Name(), destDir)
+	// Extract the zip or tarball
+	return extractThemeArchive(tmpFile.Name(), destDir)
+}
+
+// extractThemeArchive extracts a theme zip or tar.gz to a directory
+func extractThemeArchive(path, destDir string) error {
+	if config.IsTarGz(path) {
+		return config.ExtractTarGz(path, destDir)
+	}
+	return ExtractZip(path, destDir)
 }
@@ -0,0 +1,240 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestThemeBuildRootTemplates(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "theme_builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{
+		"theme.properties": "name: My Theme\nversion: 2.1.0\ninclude:\n  - functions.php\n  - single.php\n  - archive.php\n  - parts\n",
+		"style.css":        "body {}\n",
+		"functions.php":    "<?php\ndefine('MY_THEME_VERSION', 'dev');\n",
+		"single.php":       "<?php get_header(); ?>\n",
+		"archive.php":      "<?php echo MY_THEME_VERSION; define( \"MY_THEME_VERSION\", \"0.0.0\" ); ?>\n",
+		"parts/header.php": "<?php // header\n",
+	}
+	writeFiles(t, tmpDir, files)
+
+	b := NewThemeBuilder(tmpDir)
+	b.Quiet = true
+	b.StageOnly = true
+	if err := b.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	stageDir := filepath.Join(b.WorkDir, "stage")
+	for _, name := range []string{"style.css", "functions.php", "single.php", "archive.php", "parts/header.php"} {
+		if _, err := os.Stat(filepath.Join(stageDir, name)); err != nil {
+			t.Errorf("%s should be staged: %v", name, err)
+		}
+	}
+
+	functions, err := os.ReadFile(filepath.Join(stageDir, "functions.php"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(functions), "define('MY_THEME_VERSION', '2.1.0')") {
+		t.Errorf("functions.php version constant not replaced:\n%s", functions)
+	}
+
+	archive, err := os.ReadFile(filepath.Join(stageDir, "archive.php"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(archive), "define('MY_THEME_VERSION', '2.1.0')") {
+		t.Errorf("archive.php version constant not replaced:\n%s", archive)
+	}
+}
+
+func TestThemeBuildIgnoreFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "theme_builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{
+		"theme.properties":           "name: Ignore Theme\nversion: 1.0.0\ninclude:\n  - assets\n  - docs\nexclude:\n  - \"*.map\"\n",
+		".wordsmithignore":           "# Sources\nassets/scss/\n*.md\n!docs/guide.md\n!app.js.map\n",
+		"style.css":                  "body {}\n",
+		"assets/app.js":              "console.log(1);\n",
+		"assets/app.js.map":          "{}\n",
+		"assets/other.js.map":        "{}\n",
+		"assets/scss/main.scss":      "body {}\n",
+		"assets/scss/partials/_a.md": "notes\n",
+		"docs/guide.md":              "guide\n",
+		"docs/notes.md":              "notes\n",
+	}
+	writeFiles(t, tmpDir, files)
+
+	b := NewThemeBuilder(tmpDir)
+	b.Quiet = true
+	b.StageOnly = true
+	if err := b.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	stageDir := filepath.Join(b.WorkDir, "stage")
+	for _, name := range []string{"assets/app.js", "assets/app.js.map", "docs/guide.md"} {
+		if _, err := os.Stat(filepath.Join(stageDir, name)); err != nil {
+			t.Errorf("%s should be staged: %v", name, err)
+		}
+	}
+	for _, name := range []string{"assets/other.js.map", "assets/scss", "docs/notes.md", ".wordsmithignore"} {
+		if _, err := os.Stat(filepath.Join(stageDir, name)); err == nil {
+			t.Errorf("%s should not be staged", name)
+		}
+	}
+}
+
+func TestThemeBuildBlockPatterns(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "theme_builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{
+		"theme.properties":     "name: Block Theme\nversion: 1.0.0\ninclude: theme.json,templates\n",
+		"style.css":            "body {}\n",
+		"theme.json":           "{}\n",
+		"templates/index.html": "<!-- wp:post-content /-->\n",
+		"patterns/hero.php":    "<?php\n/**\n * Title: Hero\n * Slug: block-theme/hero\n */\n?>\n<!-- wp:heading /-->\n",
+	}
+	writeFiles(t, tmpDir, files)
+
+	// patterns/ is staged even though include doesn't list it
+	b := NewThemeBuilder(tmpDir)
+	b.Quiet = true
+	b.StageOnly = true
+	if err := b.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(b.WorkDir, "stage", "patterns", "hero.php")); err != nil {
+		t.Errorf("patterns/hero.php should be staged: %v", err)
+	}
+
+	// A pattern without a Slug header fails the build
+	if err := os.WriteFile(filepath.Join(tmpDir, "patterns", "cta.php"), []byte("<?php\n/**\n * Title: Call to Action\n */\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b = NewThemeBuilder(tmpDir)
+	b.Quiet = true
+	b.StageOnly = true
+	err = b.Build()
+	if err == nil || !strings.Contains(err.Error(), `patterns/cta.php: missing required "Slug" header`) {
+		t.Errorf("Build() error = %v, want a missing Slug error for patterns/cta.php", err)
+	}
+}
+
+func TestThemeBuildStyleVariations(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "theme_builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{
+		"theme.properties":          "name: Block Theme\nversion: 1.0.0\ninclude: theme.json,templates\n",
+		"style.css":                 "body {}\n",
+		"theme.json":                "{}\n",
+		"templates/index.html":      "<!-- wp:post-content /-->\n",
+		"styles/dark.json":          "{\n\t\"version\": 2,\n\t\"title\": \"Dark\"\n}\n",
+		"styles/blocks/button.json": "{\"version\": 3, \"title\": \"Outline\"}\n",
+	}
+	writeFiles(t, tmpDir, files)
+
+	// styles/ is staged even though include doesn't list it
+	b := NewThemeBuilder(tmpDir)
+	b.Quiet = true
+	b.StageOnly = true
+	if err := b.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	for _, name := range []string{"styles/dark.json", "styles/blocks/button.json"} {
+		if _, err := os.Stat(filepath.Join(b.WorkDir, "stage", name)); err != nil {
+			t.Errorf("%s should be staged: %v", name, err)
+		}
+	}
+
+	// A variation that isn't valid JSON fails the build
+	if err := os.WriteFile(filepath.Join(tmpDir, "styles", "light.json"), []byte("{\n\t\"title\": \"Light\",\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b = NewThemeBuilder(tmpDir)
+	b.Quiet = true
+	b.StageOnly = true
+	err = b.Build()
+	if err == nil || !strings.Contains(err.Error(), "styles/light.json: invalid JSON on line 3") {
+		t.Errorf("Build() error = %v, want an invalid JSON error for styles/light.json", err)
+	}
+}
+
+func TestAddThemeSupports(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "theme_builder_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	supports := []string{"post-thumbnails", "custom-logo", "align-wide"}
+
+	t.Run("setup function", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "setup", "functions.php")
+		content := "<?php\nfunction my_theme_setup() {\n    add_theme_support('title-tag');\n    add_theme_support('custom-logo', array('height' => 100));\n}\nadd_action('after_setup_theme', 'my_theme_setup');\n"
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := addThemeSupports(path, supports); err != nil {
+			t.Fatalf("addThemeSupports() error = %v", err)
+		}
+		got, _ := os.ReadFile(path)
+		want := "<?php\nfunction my_theme_setup() {\n    // Theme supports from theme.properties\n    add_theme_support('post-thumbnails');\n    add_theme_support('align-wide');\n\n    add_theme_support('title-tag');\n    add_theme_support('custom-logo', array('height' => 100));\n}\nadd_action('after_setup_theme', 'my_theme_setup');\n"
+		if string(got) != want {
+			t.Errorf("functions.php =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("no setup function", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "closure", "functions.php")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("<?php\ndefine('X', 1);\n?>\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := addThemeSupports(path, []string{"post-thumbnails"}); err != nil {
+			t.Fatalf("addThemeSupports() error = %v", err)
+		}
+		got, _ := os.ReadFile(path)
+		want := "<?php\ndefine('X', 1);\n?>\n<?php\n\nadd_action('after_setup_theme', function () {\n    // Theme supports from theme.properties\n    add_theme_support('post-thumbnails');\n});\n"
+		if string(got) != want {
+			t.Errorf("functions.php =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("missing functions.php", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "missing", "functions.php")
+		if err := addThemeSupports(path, []string{"align-wide"}); err != nil {
+			t.Fatalf("addThemeSupports() error = %v", err)
+		}
+		got, _ := os.ReadFile(path)
+		if !strings.HasPrefix(string(got), "<?php\n") || !strings.Contains(string(got), "add_theme_support('align-wide');") {
+			t.Errorf("functions.php should be created with the supports:\n%s", got)
+		}
+	})
+}
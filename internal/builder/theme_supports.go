@@ -0,0 +1,92 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// setupHookPattern finds the function hooked to after_setup_theme by name
+var setupHookPattern = regexp.MustCompile(`add_action\s*\(\s*['"]after_setup_theme['"]\s*,\s*['"]([A-Za-z0-9_]+)['"]`)
+
+// addThemeSupports adds add_theme_support() calls for the supports list to the
+// staged functions.php. Features the file already declares are left as they are,
+// and supports added by hand that aren't in the list are kept. The calls go at the
+// top of the after_setup_theme function, or in a new hooked closure if there is none.
+func addThemeSupports(functionsPath string, supports []string) error {
+	if len(supports) == 0 {
+		return nil
+	}
+
+	content, err := os.ReadFile(functionsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	php := string(content)
+	if php == "" {
+		php = "<?php\n"
+	}
+
+	var missing []string
+	for _, feature := range supports {
+		declared := regexp.MustCompile(`add_theme_support\s*\(\s*['"]` + regexp.QuoteMeta(feature) + `['"]`)
+		if !declared.MatchString(php) {
+			missing = append(missing, feature)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	updated, ok := insertIntoSetupFunction(php, missing)
+	if !ok {
+		updated = appendSetupClosure(php, missing)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(functionsPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(functionsPath, []byte(updated), 0644)
+}
+
+// themeSupportCalls returns the add_theme_support() lines for features at the given indent
+func themeSupportCalls(features []string, indent string) string {
+	var calls strings.Builder
+	calls.WriteString(indent + "// Theme supports from theme.properties\n")
+	for _, feature := range features {
+		calls.WriteString(fmt.Sprintf("%sadd_theme_support('%s');\n", indent, feature))
+	}
+	return calls.String()
+}
+
+// insertIntoSetupFunction adds the calls at the top of the named function hooked
+// to after_setup_theme. Returns false if there is no such function.
+func insertIntoSetupFunction(php string, features []string) (string, bool) {
+	match := setupHookPattern.FindStringSubmatch(php)
+	if match == nil {
+		return "", false
+	}
+
+	function := regexp.MustCompile(`function\s+` + regexp.QuoteMeta(match[1]) + `\s*\([^)]*\)\s*\{[ \t]*\n?`)
+	loc := function.FindStringIndex(php)
+	if loc == nil {
+		return "", false
+	}
+
+	body := php[:loc[1]]
+	if !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return body + themeSupportCalls(features, "    ") + "\n" + php[loc[1]:], true
+}
+
+// appendSetupClosure appends a new after_setup_theme closure with the calls
+func appendSetupClosure(php string, features []string) string {
+	trimmed := strings.TrimRight(php, " \t\n")
+	if strings.HasSuffix(trimmed, "?>") {
+		trimmed += "\n<?php"
+	}
+	return trimmed + "\n\nadd_action('after_setup_theme', function () {\n" + themeSupportCalls(features, "    ") + "});\n"
+}
@@ -0,0 +1,82 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// UpdateManifest describes the latest release for self-hosted update checkers.
+// The fields follow the metadata format read by plugin-update-checker.
+type UpdateManifest struct {
+	Name           string            `json:"name"`
+	Slug           string            `json:"slug"`
+	Version        string            `json:"version"`
+	DownloadURL    string            `json:"download_url"`
+	Homepage       string            `json:"homepage,omitempty"`
+	DetailsURL     string            `json:"details_url,omitempty"`
+	Author         string            `json:"author,omitempty"`
+	AuthorHomepage string            `json:"author_homepage,omitempty"`
+	Requires       string            `json:"requires,omitempty"`
+	RequiresPHP    string            `json:"requires_php,omitempty"`
+	LastUpdated    string            `json:"last_updated"`
+	Sections       map[string]string `json:"sections,omitempty"`
+}
+
+// WriteUpdateManifest writes build/update.json for the packaged plugin, with the
+// download URL pointing at the zip under baseURL
+func (b *Builder) WriteUpdateManifest(baseURL string) (string, error) {
+	manifest := &UpdateManifest{
+		Name:           b.Config.Name,
+		Slug:           b.GetPluginSlug(),
+		Homepage:       b.Config.PluginURI,
+		Author:         b.Config.Author,
+		AuthorHomepage: b.Config.AuthorURI,
+		Requires:       b.Config.Requires,
+		RequiresPHP:    b.Config.RequiresPHP,
+	}
+	if b.Config.Description != "" {
+		manifest.Sections = map[string]string{"description": b.Config.Description}
+	}
+	return b.writeUpdateManifest(manifest, baseURL)
+}
+
+// WriteUpdateManifest writes build/update.json for the packaged theme, with the
+// download URL pointing at the zip under baseURL
+func (b *ThemeBuilder) WriteUpdateManifest(baseURL string) (string, error) {
+	manifest := &UpdateManifest{
+		Name:           b.Config.Name,
+		Slug:           b.GetThemeSlug(),
+		DetailsURL:     b.Config.ThemeURI,
+		Author:         b.Config.Author,
+		AuthorHomepage: b.Config.AuthorURI,
+		Requires:       b.Config.Requires,
+		RequiresPHP:    b.Config.RequiresPHP,
+	}
+	return b.writeUpdateManifest(manifest, baseURL)
+}
+
+// writeUpdateManifest fills in the version and download URL and writes the manifest
+func (b *BaseBuilder) writeUpdateManifest(manifest *UpdateManifest, baseURL string) (string, error) {
+	if b.ZipPath == "" {
+		return "", fmt.Errorf("no zip was created to describe in the update manifest")
+	}
+
+	manifest.Version = b.Version.String()
+	manifest.DownloadURL = strings.TrimSuffix(baseURL, "/") + "/" + filepath.Base(b.ZipPath)
+	manifest.LastUpdated = time.Now().UTC().Format("2006-01-02 15:04:05")
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode update manifest: %w", err)
+	}
+
+	path := filepath.Join(b.BuildDir, "update.json")
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write update manifest: %w", err)
+	}
+	return path, nil
+}
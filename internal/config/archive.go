@@ -0,0 +1,201 @@
+package config
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IsArchiveName checks if a file name has a supported archive extension (.zip, .tar.gz, .tgz)
+func IsArchiveName(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".zip") || isTarGzName(lower)
+}
+
+// isTarGzName checks if a file name has a .tar.gz or .tgz extension
+func isTarGzName(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// IsTarGz checks if a file is a gzipped tarball, by extension or by the gzip magic bytes
+func IsTarGz(path string) bool {
+	if isTarGzName(path) {
+		return true
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return false
+	}
+	return magic[0] == 0x1f && magic[1] == 0x8b
+}
+
+// ExtractArchive extracts a zip or tar.gz file to a directory, stripping a
+// single root directory if every entry is under it
+func ExtractArchive(path, destDir string) error {
+	if IsTarGz(path) {
+		return ExtractTarGz(path, destDir)
+	}
+	return extractZipToDir(path, destDir)
+}
+
+// ExtractTarGz extracts a tar.gz file to a directory, stripping a single root
+// directory if every entry is under it. Only directories and regular files are
+// extracted; links and other special entries are skipped.
+func ExtractTarGz(path, destDir string) error {
+	names, err := tarGzEntryNames(path)
+	if err != nil {
+		return err
+	}
+	rootDir := archiveRootDir(names)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimPrefix(header.Name, "./")
+		if rootDir != "" {
+			name = strings.TrimPrefix(strings.TrimPrefix(name, rootDir), "/")
+		}
+		if name == "" {
+			continue
+		}
+
+		fpath := filepath.Join(destDir, name)
+
+		// Security check - prevent path traversal
+		if !strings.HasPrefix(fpath, filepath.Clean(destDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid file path: %s", fpath)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			os.MkdirAll(fpath, os.ModePerm)
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+				return err
+			}
+
+			outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+			if err != nil {
+				return err
+			}
+
+			_, err = io.Copy(outFile, tr)
+			outFile.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// tarGzEntryNames returns the entry names in a tar.gz file
+func tarGzEntryNames(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, strings.TrimPrefix(header.Name, "./"))
+	}
+
+	return names, nil
+}
+
+// ZipRootDir returns the single root directory of a zip file, which is the
+// directory a plugin or theme zip installs to, or "" if there isn't one
+func ZipRootDir(zipPath string) (string, error) {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	names := make([]string, len(r.File))
+	for i, f := range r.File {
+		names[i] = f.Name
+	}
+	return archiveRootDir(names), nil
+}
+
+// archiveRootDir returns the single root directory shared by all entry names,
+// or "" if the entries aren't all under one directory.
+// Many GitHub releases have a single root directory.
+func archiveRootDir(names []string) string {
+	var rootDir string
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		parts := strings.Split(name, "/")
+		if len(parts) > 1 {
+			rootDir = parts[0]
+		}
+		break
+	}
+
+	if rootDir == "" || rootDir == "." || rootDir == ".." {
+		return ""
+	}
+
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if !strings.HasPrefix(name, rootDir+"/") && name != rootDir {
+			return ""
+		}
+	}
+
+	return rootDir
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTarGz creates a tar.gz at path with the given entries; names ending in / are directories
+func writeTarGz(t *testing.T, path string, entries map[string]string, order []string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	for _, name := range order {
+		content := entries[name]
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if name[len(name)-1] == '/' {
+			header = &tar.Header{Name: name, Mode: 0755, Typeflag: tar.TypeDir}
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractTarGzSingleRoot(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "archive_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	archive := filepath.Join(tmpDir, "lib-1.0.0.tar.gz")
+
+	writeTarGz(t, archive, map[string]string{
+		"lib-1.0.0/":             "",
+		"lib-1.0.0/lib.php":      "<?php // lib",
+		"lib-1.0.0/src/util.php": "<?php // util",
+	}, []string{"lib-1.0.0/", "lib-1.0.0/lib.php", "lib-1.0.0/src/util.php"})
+
+	destDir := filepath.Join(tmpDir, "out")
+	if err := ExtractArchive(archive, destDir); err != nil {
+		t.Fatalf("ExtractArchive error: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(destDir, "lib.php"))
+	if err != nil {
+		t.Fatalf("root directory was not stripped: %v", err)
+	}
+	if string(content) != "<?php // lib" {
+		t.Errorf("lib.php = %q, want %q", content, "<?php // lib")
+	}
+	if !FileExists(filepath.Join(destDir, "src", "util.php")) {
+		t.Error("src/util.php should be extracted")
+	}
+	if FileExists(filepath.Join(destDir, "lib-1.0.0")) {
+		t.Error("root directory should not be extracted")
+	}
+}
+
+func TestExtractTarGzMultipleRoots(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "archive_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	archive := filepath.Join(tmpDir, "lib.tgz")
+
+	writeTarGz(t, archive, map[string]string{
+		"a/one.php": "1",
+		"b/two.php": "2",
+	}, []string{"a/one.php", "b/two.php"})
+
+	destDir := filepath.Join(tmpDir, "out")
+	if err := ExtractTarGz(archive, destDir); err != nil {
+		t.Fatalf("ExtractTarGz error: %v", err)
+	}
+
+	if !FileExists(filepath.Join(destDir, "a", "one.php")) || !FileExists(filepath.Join(destDir, "b", "two.php")) {
+		t.Error("entries without a common root should be extracted as-is")
+	}
+}
+
+func TestExtractTarGzPathTraversal(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "archive_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	archive := filepath.Join(tmpDir, "evil.tar.gz")
+
+	writeTarGz(t, archive, map[string]string{
+		"../evil.php": "<?php",
+	}, []string{"../evil.php"})
+
+	if err := ExtractTarGz(archive, filepath.Join(tmpDir, "out")); err == nil {
+		t.Error("expected error for entry outside the destination directory")
+	}
+}
+
+func TestIsTarGz(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "archive_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Detected by content even without a tar.gz extension
+	sniffed := filepath.Join(tmpDir, "download")
+	writeTarGz(t, sniffed, map[string]string{"a.php": "1"}, []string{"a.php"})
+	if !IsTarGz(sniffed) {
+		t.Error("IsTarGz should detect gzip content")
+	}
+
+	plain := filepath.Join(tmpDir, "plain.zip")
+	if err := os.WriteFile(plain, []byte("PK\x03\x04"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if IsTarGz(plain) {
+		t.Error("IsTarGz should be false for a zip file")
+	}
+
+	if !IsTarGz("missing.tgz") {
+		t.Error("IsTarGz should detect the .tgz extension")
+	}
+}
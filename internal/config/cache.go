@@ -0,0 +1,19 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// NoCacheEnv is the environment variable that disables download caches. The
+// --no-cache flag sets it so child wordsmith processes inherit the setting.
+const NoCacheEnv = "WORDSMITH_NO_CACHE"
+
+// NoCache reports whether download caches are bypassed for this run
+func NoCache() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(NoCacheEnv))) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"wordsmith/internal/ui"
 )
 
 const (
@@ -17,9 +19,13 @@ const (
 
 // LibrarySpec represents a library specification from properties file
 type LibrarySpec struct {
-	Name    string // Directory name to use in the build
-	URL     string // URL to download from (can be zip URL or GitHub repo URL)
-	Version string // Version to download (for GitHub repos)
+	Name         string // Directory name to use in the build
+	URL          string // URL to download from (can be zip URL or GitHub repo URL)
+	Version      string // Version to download (for GitHub repos)
+	Release      string // GitHub release selection: latest, latest-including-prerelease, or a tag
+	AssetPattern string // Glob or regex selecting the GitHub release asset
+	Sha256       string // Expected SHA-256 of the downloaded archive (optional)
+	Order        int    // Plugin activation order; lower numbers activate first
 }
 
 // ParseLibraries parses the libraries property from a properties file.
@@ -137,6 +143,15 @@ func parseLibraryProperties(p Properties) *LibrarySpec {
 	if version, ok := p["version"].(string); ok {
 		spec.Version = version
 	}
+	if release, ok := p["release"].(string); ok {
+		spec.Release = release
+	}
+	if pattern, ok := p["asset-pattern"].(string); ok {
+		spec.AssetPattern = pattern
+	}
+	if sum, ok := p["sha256"].(string); ok {
+		spec.Sha256 = sum
+	}
 
 	// If no name specified, derive from URL
 	if spec.Name == "" && spec.URL != "" {
@@ -164,6 +179,15 @@ func parseLibraryMap(m map[string]interface{}) *LibrarySpec {
 	if version, ok := m["version"].(string); ok {
 		spec.Version = version
 	}
+	if release, ok := m["release"].(string); ok {
+		spec.Release = release
+	}
+	if pattern, ok := m["asset-pattern"].(string); ok {
+		spec.AssetPattern = pattern
+	}
+	if sum, ok := m["sha256"].(string); ok {
+		spec.Sha256 = sum
+	}
 
 	// If no name specified, derive from URL
 	if spec.Name == "" && spec.URL != "" {
@@ -204,8 +228,10 @@ func deriveLibraryName(url string) string {
 		name = name[:idx]
 	}
 
-	// Remove .zip extension
+	// Remove archive extension
 	name = strings.TrimSuffix(name, ".zip")
+	name = strings.TrimSuffix(name, ".tar.gz")
+	name = strings.TrimSuffix(name, ".tgz")
 
 	// If name is empty or just an extension, generate a fallback
 	if name == "" || name == "." {
@@ -248,8 +274,8 @@ func resolveLocalLibrary(spec LibrarySpec) (string, error) {
 		return "", fmt.Errorf("library not found: %s", path)
 	}
 
-	// If it's a zip file, extract to temp directory
-	if strings.HasSuffix(strings.ToLower(path), ".zip") {
+	// If it's a zip or tar.gz file, extract to temp directory
+	if !info.IsDir() && (IsArchiveName(path) || IsTarGz(path)) {
 		return extractLocalZip(path)
 	}
 
@@ -288,25 +314,26 @@ func resolveLocalLibrary(spec LibrarySpec) (string, error) {
 		return extractLocalZip(zipPath)
 	}
 
-	return "", fmt.Errorf("library path is neither a zip file nor a directory: %s", path)
+	return "", fmt.Errorf("library path is neither an archive (.zip, .tar.gz) nor a directory: %s", path)
 }
 
-// extractLocalZip extracts a local zip to a temp directory (no caching)
+// extractLocalZip extracts a local zip or tar.gz to a temp directory (no caching).
+// Callers release it with RemoveTempDir once the library has been copied.
 func extractLocalZip(zipPath string) (string, error) {
-	tempDir, err := os.MkdirTemp("", "wordsmith-lib-*")
+	tempDir, err := NewTempDir("lib")
 	if err != nil {
 		return "", fmt.Errorf("failed to create temp directory: %w", err)
 	}
 
-	if err := extractZipToDir(zipPath, tempDir); err != nil {
-		os.RemoveAll(tempDir)
+	if err := ExtractArchive(zipPath, tempDir); err != nil {
+		RemoveTempDir(tempDir)
 		return "", fmt.Errorf("failed to extract library: %w", err)
 	}
 
 	return tempDir, nil
 }
 
-// findLatestZipInDir finds the most recently modified zip file in a directory
+// findLatestZipInDir finds the most recently modified zip or tar.gz file in a directory
 func findLatestZipInDir(dir string) (string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -320,7 +347,7 @@ func findLatestZipInDir(dir string) (string, error) {
 		if entry.IsDir() {
 			continue
 		}
-		if !strings.HasSuffix(strings.ToLower(entry.Name()), ".zip") {
+		if !IsArchiveName(entry.Name()) {
 			continue
 		}
 
@@ -355,7 +382,7 @@ func resolveRemoteLibrary(spec LibrarySpec) (string, error) {
 
 	// For GitHub URLs without a version, resolve the latest version
 	if spec.Version == "" && strings.Contains(spec.URL, "github.com") && isGitHubRepoURL(spec.URL) {
-		resolvedVersion, downloadURL, err := resolveGitHubLatestVersion(spec.URL, spec.Name)
+		resolvedVersion, downloadURL, err := resolveGitHubLatestVersion(spec.URL, spec.Name, spec.Release, spec.AssetPattern)
 		if err != nil {
 			// GitHub API failed, try to use locally cached version
 			cachedVersion := findLatestCachedVersion(spec.Name)
@@ -375,7 +402,7 @@ func resolveRemoteLibrary(spec LibrarySpec) (string, error) {
 		}
 
 		// Download and extract with the resolved version
-		return downloadAndExtractLibrary(downloadURL, spec.Name, resolvedVersion)
+		return downloadAndExtractLibrary(downloadURL, spec.Name, resolvedVersion, spec.Sha256)
 	}
 
 	// For non-GitHub URLs without version, we still need a version for caching
@@ -397,13 +424,13 @@ func resolveRemoteLibrary(spec LibrarySpec) (string, error) {
 	}
 
 	// Download and extract
-	return downloadAndExtractLibrary(downloadURL, spec.Name, spec.Version)
+	return downloadAndExtractLibrary(downloadURL, spec.Name, spec.Version, spec.Sha256)
 }
 
 // resolveDownloadURL resolves a library spec to a download URL
 func resolveDownloadURL(spec LibrarySpec) (string, error) {
-	// If it's already a direct zip URL, use it
-	if strings.HasSuffix(strings.ToLower(spec.URL), ".zip") {
+	// If it's already a direct archive URL, use it
+	if IsArchiveName(spec.URL) {
 		return spec.URL, nil
 	}
 
@@ -414,21 +441,22 @@ func resolveDownloadURL(spec LibrarySpec) (string, error) {
 
 	// If it's a GitHub repo URL, resolve to release asset
 	if strings.Contains(spec.URL, "github.com") {
-		return ResolveGitHubURL(spec.URL, spec.Name, spec.Version)
+		return ResolveGitHubURL(spec.URL, spec.Name, spec.Version, spec.Release, spec.AssetPattern)
 	}
 
 	// Otherwise, assume it's a direct download URL
 	return spec.URL, nil
 }
 
-// getLibraryCacheDir returns the cache directory for a library
+// getLibraryCacheDir returns the cache directory for a library, or "" with
+// caching disabled
 func getLibraryCacheDir(name, version string) string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
+	if version == "" || NoCache() {
 		return ""
 	}
 
-	if version == "" {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
 		return ""
 	}
 
@@ -437,6 +465,10 @@ func getLibraryCacheDir(name, version string) string {
 
 // findLatestCachedVersion finds the latest cached version of a library
 func findLatestCachedVersion(name string) string {
+	if NoCache() {
+		return ""
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return ""
@@ -452,7 +484,7 @@ func findLatestCachedVersion(name string) string {
 	for _, entry := range entries {
 		if entry.IsDir() && strings.HasPrefix(entry.Name(), "v") {
 			version := strings.TrimPrefix(entry.Name(), "v")
-			if latestVersion == "" || compareVersions(version, latestVersion) > 0 {
+			if latestVersion == "" || CompareVersions(version, latestVersion) > 0 {
 				latestVersion = version
 			}
 		}
@@ -461,8 +493,8 @@ func findLatestCachedVersion(name string) string {
 	return latestVersion
 }
 
-// compareVersions compares two version strings (simple comparison)
-func compareVersions(v1, v2 string) int {
+// CompareVersions compares two version strings (simple comparison)
+func CompareVersions(v1, v2 string) int {
 	// Split versions into parts
 	parts1 := strings.Split(v1, ".")
 	parts2 := strings.Split(v2, ".")
@@ -503,16 +535,16 @@ func hashURL(url string) string {
 	return fmt.Sprintf("%08x", sum)
 }
 
-// resolveGitHubLatestVersion resolves the latest version and download URL for a GitHub repo
-func resolveGitHubLatestVersion(url, name string) (version string, downloadURL string, err error) {
+// resolveGitHubLatestVersion resolves the version and download URL of the release
+// selected by policy (latest by default) for a GitHub repo
+func resolveGitHubLatestVersion(url, name, policy, assetPattern string) (version string, downloadURL string, err error) {
 	owner, repo, err := parseGitHubRepoURL(url)
 	if err != nil {
 		return "", "", err
 	}
 
-	// Fetch latest release
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest", owner, repo)
-	release, err := fetchGitHubRelease(apiURL)
+	// Fetch the selected release
+	release, err := selectGitHubRelease(owner, repo, policy)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to fetch latest release: %w", err)
 	}
@@ -521,7 +553,7 @@ func resolveGitHubLatestVersion(url, name string) (version string, downloadURL s
 	version = strings.TrimPrefix(release.TagName, "v")
 
 	// Find matching asset
-	assetURL := findReleaseAsset(release, name, version)
+	assetURL := findReleaseAsset(release, name, version, assetPattern)
 	if assetURL == "" {
 		return "", "", fmt.Errorf("no matching asset found in release %s", release.TagName)
 	}
@@ -543,8 +575,23 @@ func isLibraryCached(cacheDir string) bool {
 	return err == nil && len(files) > 0
 }
 
-// downloadAndExtractLibrary downloads a library zip and extracts it to the cache
-func downloadAndExtractLibrary(url, name, version string) (string, error) {
+// downloadAndExtractLibrary downloads a library zip or tar.gz and extracts it to the
+// cache, first checking the archive against checksum when one is given
+func downloadAndExtractLibrary(url, name, version, checksum string) (string, error) {
+	// With caching disabled the library is extracted to a temp directory, which
+	// callers release with RemoveTempDir once it has been copied
+	if NoCache() {
+		tempDir, err := NewTempDir("nocache")
+		if err != nil {
+			return "", fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		if err := downloadLibraryTo(url, checksum, tempDir); err != nil {
+			RemoveTempDir(tempDir)
+			return "", err
+		}
+		return tempDir, nil
+	}
+
 	cacheDir := getLibraryCacheDir(name, version)
 	if cacheDir == "" {
 		return "", fmt.Errorf("could not determine cache directory")
@@ -554,39 +601,52 @@ func downloadAndExtractLibrary(url, name, version string) (string, error) {
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create cache directory: %w", err)
 	}
+	if err := downloadLibraryTo(url, checksum, cacheDir); err != nil {
+		return "", err
+	}
+	return cacheDir, nil
+}
 
+// downloadLibraryTo downloads a library archive, checks its checksum, and
+// extracts it into dir, removing dir on a failed check or extraction
+func downloadLibraryTo(url, checksum, dir string) error {
 	// Download to temp file
-	tmpFile, err := os.CreateTemp("", "wordsmith-library-*.zip")
+	tmpFile, err := os.CreateTemp("", TempPrefix+"library-*")
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %w", err)
+		return fmt.Errorf("failed to create temp file: %w", err)
 	}
 	tmpPath := tmpFile.Name()
 	defer os.Remove(tmpPath)
 
 	// Download
-	resp, err := http.Get(url)
+	ui.EmitEvent("info", "Downloading %s", url)
+	resp, err := HTTPGet(url)
 	if err != nil {
-		return "", fmt.Errorf("failed to download library: %w", err)
+		return fmt.Errorf("failed to download library: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to download library: HTTP %d", resp.StatusCode)
+		return fmt.Errorf("failed to download library: HTTP %d%s", resp.StatusCode, GitHubAuthHint(url, resp.StatusCode))
 	}
 
-	_, err = io.Copy(tmpFile, resp.Body)
+	_, err = CopyArchiveDownload(tmpFile, resp)
 	tmpFile.Close()
 	if err != nil {
-		return "", fmt.Errorf("failed to save library: %w", err)
+		return fmt.Errorf("failed to save library: %w", err)
+	}
+	if err := VerifyChecksum(tmpPath, checksum); err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("failed to verify library %s: %w", url, err)
 	}
 
-	// Extract
-	if err := extractZipToDir(tmpPath, cacheDir); err != nil {
-		os.RemoveAll(cacheDir)
-		return "", fmt.Errorf("failed to extract library: %w", err)
+	// Extract (zip or tar.gz, detected from the content)
+	if err := ExtractArchive(tmpPath, dir); err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("failed to extract library: %w", err)
 	}
 
-	return cacheDir, nil
+	return nil
 }
 
 // extractZipToDir extracts a zip file to a directory
@@ -597,33 +657,18 @@ func extractZipToDir(zipPath, destDir string) error {
 	}
 	defer r.Close()
 
-	// Find the root directory in the zip (if any)
-	// Many GitHub releases have a single root directory
-	var rootDir string
-	for _, f := range r.File {
-		parts := strings.Split(f.Name, "/")
-		if len(parts) > 1 && rootDir == "" {
-			rootDir = parts[0]
-		}
-		break
-	}
-
-	// Check if all files are under the same root
-	allUnderRoot := rootDir != ""
-	if allUnderRoot {
-		for _, f := range r.File {
-			if !strings.HasPrefix(f.Name, rootDir+"/") && f.Name != rootDir+"/" {
-				allUnderRoot = false
-				break
-			}
-		}
+	// Find the root directory in the zip (if all files are under one)
+	names := make([]string, len(r.File))
+	for i, f := range r.File {
+		names[i] = f.Name
 	}
+	rootDir := archiveRootDir(names)
 
 	for _, f := range r.File {
 		name := f.Name
 
 		// Strip root directory if all files are under it
-		if allUnderRoot && rootDir != "" {
+		if rootDir != "" {
 			if name == rootDir+"/" {
 				continue
 			}
@@ -718,6 +763,9 @@ func ParsePlugins(props Properties) []LibrarySpec {
 // parsePluginDependencyItem parses a single plugin dependency item which can be a string or a map
 // Supports both library format (name/url) and plugin format (slug/version)
 func parsePluginDependencyItem(item interface{}) *LibrarySpec {
+	if skipped(item) {
+		return nil
+	}
 	switch v := item.(type) {
 	case string:
 		return parseLibraryString(v)
@@ -753,6 +801,16 @@ func parsePluginProperties(p Properties) *LibrarySpec {
 	if version, ok := p["version"].(string); ok {
 		spec.Version = version
 	}
+	if release, ok := p["release"].(string); ok {
+		spec.Release = release
+	}
+	if pattern, ok := p["asset-pattern"].(string); ok {
+		spec.AssetPattern = pattern
+	}
+	if sum, ok := p["sha256"].(string); ok {
+		spec.Sha256 = sum
+	}
+	spec.Order = orderField(p)
 
 	// If no name specified, derive from URL
 	if spec.Name == "" && spec.URL != "" {
@@ -790,6 +848,16 @@ func parsePluginMap(m map[string]interface{}) *LibrarySpec {
 	if version, ok := m["version"].(string); ok {
 		spec.Version = version
 	}
+	if release, ok := m["release"].(string); ok {
+		spec.Release = release
+	}
+	if pattern, ok := m["asset-pattern"].(string); ok {
+		spec.AssetPattern = pattern
+	}
+	if sum, ok := m["sha256"].(string); ok {
+		spec.Sha256 = sum
+	}
+	spec.Order = orderField(m)
 
 	// If no name specified, derive from URL
 	if spec.Name == "" && spec.URL != "" {
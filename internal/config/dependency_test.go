@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -82,6 +83,7 @@ func TestParseLibraryMap(t *testing.T) {
 		wantName    string
 		wantURL     string
 		wantVersion string
+		wantSha256  string
 		wantNil     bool
 	}{
 		{
@@ -90,10 +92,12 @@ func TestParseLibraryMap(t *testing.T) {
 				"name":    "mylib",
 				"url":     "https://example.com/lib.zip",
 				"version": "1.0.0",
+				"sha256":  "abc123",
 			},
 			wantName:    "mylib",
 			wantURL:     "https://example.com/lib.zip",
 			wantVersion: "1.0.0",
+			wantSha256:  "abc123",
 		},
 		{
 			name: "url only - name derived",
@@ -142,6 +146,9 @@ func TestParseLibraryMap(t *testing.T) {
 			if spec.Version != tt.wantVersion {
 				t.Errorf("Version = %q, want %q", spec.Version, tt.wantVersion)
 			}
+			if spec.Sha256 != tt.wantSha256 {
+				t.Errorf("Sha256 = %q, want %q", spec.Sha256, tt.wantSha256)
+			}
 		})
 	}
 }
@@ -158,6 +165,7 @@ func TestDeriveLibraryName(t *testing.T) {
 		{"https://example.com/download?file=lib.zip", "download"}, // query params stripped from filename
 		{"./vendor/mylib.zip", "mylib"},
 		{"/absolute/path/to/lib.zip", "lib"},
+		{"./vendor/mylib.tar.gz", "mylib"},
 	}
 
 	for _, tt := range tests {
@@ -236,6 +244,55 @@ func TestParseLibraries(t *testing.T) {
 	}
 }
 
+func TestParsePluginsSkip(t *testing.T) {
+	props := Properties{
+		"plugins": []interface{}{
+			"woocommerce",
+			map[string]interface{}{"slug": "akismet", "skip": true},
+			Properties{"slug": "jetpack", "skip": "false"},
+		},
+	}
+
+	specs := ParsePlugins(props)
+	if len(specs) != 2 || specs[0].Name != "woocommerce" || specs[1].Name != "jetpack" {
+		t.Errorf("ParsePlugins() = %+v, want woocommerce and jetpack", specs)
+	}
+}
+
+func TestParsePluginsOrder(t *testing.T) {
+	props := Properties{
+		"plugins": []interface{}{
+			"woocommerce",
+			map[string]interface{}{"slug": "akismet", "order": 2},
+			Properties{"slug": "jetpack", "priority": "-1"},
+		},
+	}
+
+	specs := ParsePlugins(props)
+	if len(specs) != 3 {
+		t.Fatalf("expected 3 specs, got %d", len(specs))
+	}
+	for i, want := range []int{0, 2, -1} {
+		if specs[i].Order != want {
+			t.Errorf("specs[%d].Order = %d, want %d", i, specs[i].Order, want)
+		}
+	}
+
+	plugins := SortPlugins([]WordPressPlugin{
+		parsePluginItem("woocommerce"),
+		parsePluginItem(map[string]interface{}{"slug": "akismet", "order": 2}),
+		parsePluginItem(map[string]interface{}{"slug": "jetpack", "priority": -1}),
+		parsePluginItem("hello-dolly"),
+	})
+	var got []string
+	for _, plugin := range plugins {
+		got = append(got, plugin.Slug)
+	}
+	if strings.Join(got, ",") != "jetpack,woocommerce,hello-dolly,akismet" {
+		t.Errorf("SortPlugins() = %v, want jetpack, woocommerce, hello-dolly, akismet", got)
+	}
+}
+
 func TestParseLibrariesCommaSeparated(t *testing.T) {
 	props := Properties{
 		"libraries": "https://github.com/owner/repo1, https://example.com/lib.zip:1.0.0",
@@ -257,3 +314,23 @@ func TestParseLibrariesCommaSeparated(t *testing.T) {
 		t.Errorf("specs[1].Version = %q, want %q", specs[1].Version, "1.0.0")
 	}
 }
+
+func TestGetLibraryCacheDirNoCache(t *testing.T) {
+	t.Setenv(NoCacheEnv, "")
+	cached := getLibraryCacheDir("mylib", "1.0.0")
+	if !strings.Contains(cached, libraryBaseDir) {
+		t.Errorf("getLibraryCacheDir() = %q, want a path under %s", cached, libraryBaseDir)
+	}
+
+	t.Setenv(NoCacheEnv, "1")
+	if !NoCache() {
+		t.Fatal("NoCache() should be true when WORDSMITH_NO_CACHE=1")
+	}
+	uncached := getLibraryCacheDir("mylib", "1.0.0")
+	if strings.Contains(uncached, libraryBaseDir) {
+		t.Errorf("getLibraryCacheDir() = %q, should not use the library cache with caching disabled", uncached)
+	}
+	if findLatestCachedVersion("mylib") != "" {
+		t.Error("findLatestCachedVersion() should not find cached versions with caching disabled")
+	}
+}
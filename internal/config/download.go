@@ -0,0 +1,179 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MaxDownloadSizeEnv is the environment variable that overrides the download size
+// limit. The --max-download-size flag sets it so child wordsmith processes inherit it.
+const MaxDownloadSizeEnv = "WORDSMITH_MAX_DOWNLOAD_SIZE"
+
+// DefaultMaxDownloadSize is the largest plugin, theme, or library download accepted
+// unless the limit is changed: generous for real archives, but finite
+const DefaultMaxDownloadSize int64 = 1 << 30
+
+// sizeUnits maps size suffixes to their multipliers, longest suffixes first
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"gb", 1 << 30}, {"mb", 1 << 20}, {"kb", 1 << 10},
+	{"g", 1 << 30}, {"m", 1 << 20}, {"k", 1 << 10},
+	{"b", 1},
+}
+
+// ParseSize parses a size such as 500MB, 2G, or 1048576 (bytes). Units are
+// binary (1MB is 1024*1024 bytes); 0 means no limit.
+func ParseSize(s string) (int64, error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size '%s' (use e.g. 500MB, 2GB, or 0 for no limit)", s)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// MaxDownloadSize returns the download size limit in bytes for this run; 0 means no limit
+func MaxDownloadSize() int64 {
+	if value := os.Getenv(MaxDownloadSizeEnv); value != "" {
+		if size, err := ParseSize(value); err == nil {
+			return size
+		}
+	}
+	return DefaultMaxDownloadSize
+}
+
+// formatBytes formats a byte count for error messages
+func formatBytes(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+// CopyArchiveDownload writes a zip or tar.gz download's body to dst. The first
+// bytes are checked before anything is written, so an HTML error or login page
+// served with a 200 status fails clearly instead of as a corrupt archive.
+// MaxDownloadSize is enforced: a Content-Length over the limit is rejected before
+// reading, and the copy stops as soon as the limit is passed when the length is
+// missing or wrong.
+func CopyArchiveDownload(dst io.Writer, resp *http.Response) (int64, error) {
+	if err := checkDownloadSize(resp); err != nil {
+		return 0, err
+	}
+
+	body := bufio.NewReader(resp.Body)
+	magic, _ := body.Peek(4)
+	if !isArchiveMagic(magic) {
+		return 0, fmt.Errorf("%s: expected a zip but got %s (possibly an auth wall or wrong URL)",
+			downloadSource(resp), describeContent(resp.Header.Get("Content-Type"), magic))
+	}
+	return copyDownload(dst, body, resp)
+}
+
+// isArchiveMagic checks for the zip (PK) or gzip magic bytes
+func isArchiveMagic(magic []byte) bool {
+	return bytes.HasPrefix(magic, []byte("PK")) || bytes.HasPrefix(magic, []byte{0x1f, 0x8b})
+}
+
+// describeContent names what a download contains, from its Content-Type header
+// or, without one, from the first bytes
+func describeContent(contentType string, magic []byte) string {
+	if len(magic) == 0 {
+		return "an empty response"
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(magic)
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return contentType
+}
+
+// downloadSource describes a download in error messages
+func downloadSource(resp *http.Response) string {
+	if resp.Request != nil {
+		return "download from " + resp.Request.URL.Redacted()
+	}
+	return "download"
+}
+
+// checkDownloadSize rejects a download whose Content-Length is over MaxDownloadSize
+func checkDownloadSize(resp *http.Response) error {
+	limit := MaxDownloadSize()
+	if limit > 0 && resp.ContentLength > limit {
+		return fmt.Errorf("%s is %s, over the %s limit (raise it with --max-download-size)",
+			downloadSource(resp), formatBytes(resp.ContentLength), formatBytes(limit))
+	}
+	return nil
+}
+
+// copyDownload copies body to dst, stopping once it passes MaxDownloadSize
+func copyDownload(dst io.Writer, body io.Reader, resp *http.Response) (int64, error) {
+	limit := MaxDownloadSize()
+	if limit <= 0 {
+		return io.Copy(dst, body)
+	}
+
+	n, err := io.Copy(dst, io.LimitReader(body, limit+1))
+	if err != nil {
+		return n, err
+	}
+	if n > limit {
+		return n, fmt.Errorf("%s exceeded the %s limit (raise it with --max-download-size)", downloadSource(resp), formatBytes(limit))
+	}
+	return n, nil
+}
+
+// VerifyChecksum checks that the file at path has the expected SHA-256 checksum,
+// given as hex with an optional "sha256:" prefix. An empty checksum always passes.
+func VerifyChecksum(path, expected string) error {
+	want := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(expected), "sha256:"))
+	if want == "" {
+		return nil
+	}
+	if _, err := hex.DecodeString(want); err != nil || len(want) != sha256.Size*2 {
+		return fmt.Errorf("invalid sha256 '%s' (expected 64 hex characters)", expected)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return fmt.Errorf("checksum mismatch: expected sha256 %s but the download is %s", want, got)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"1048576", 1 << 20, false},
+		{"500MB", 500 << 20, false},
+		{"2G", 2 << 30, false},
+		{"1.5 kb", 1536, false},
+		{"0", 0, false},
+		{"lots", 0, true},
+		{"-1MB", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSize(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCopyArchiveDownloadLimit(t *testing.T) {
+	body := "PK" + strings.Repeat("x", 2046)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			// Without a Content-Length the limit is enforced while copying
+			w.(http.Flusher).Flush()
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	download := func(path string) (int64, error) {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var buf bytes.Buffer
+		return CopyArchiveDownload(&buf, resp)
+	}
+
+	t.Setenv(MaxDownloadSizeEnv, "1KB")
+	if _, err := download("/sized"); err == nil || !strings.Contains(err.Error(), "over the 1.0 KB limit") {
+		t.Errorf("CopyArchiveDownload() with Content-Length = %v, want over-limit error", err)
+	}
+	if n, err := download("/chunked"); err == nil || !strings.Contains(err.Error(), "exceeded the 1.0 KB limit") || n > 1025 {
+		t.Errorf("CopyArchiveDownload() without Content-Length = %d, %v, want to stop after the limit", n, err)
+	}
+
+	t.Setenv(MaxDownloadSizeEnv, "0")
+	if n, err := download("/sized"); err != nil || n != int64(len(body)) {
+		t.Errorf("CopyArchiveDownload() with no limit = %d, %v, want %d bytes", n, err, len(body))
+	}
+}
+
+func TestCopyArchiveDownloadContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html><body>Sign in</body></html>"))
+		case "/empty":
+		case "/tarball":
+			w.Write([]byte{0x1f, 0x8b, 0x08, 0x00})
+		default:
+			w.Write([]byte("PK\x03\x04"))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path    string
+		wantErr string
+	}{
+		{"/plugin.zip", ""},
+		{"/tarball", ""},
+		{"/login", "expected a zip but got text/html"},
+		{"/empty", "expected a zip but got an empty response"},
+	}
+
+	for _, tt := range tests {
+		resp, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		_, err = CopyArchiveDownload(&buf, resp)
+		resp.Body.Close()
+
+		if tt.wantErr == "" {
+			if err != nil || buf.Len() != 4 {
+				t.Errorf("%s: CopyArchiveDownload() = %v with %d bytes, want 4 bytes", tt.path, err, buf.Len())
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: CopyArchiveDownload() error = %v, want %q", tt.path, err, tt.wantErr)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%s: wrote %d bytes, want none", tt.path, buf.Len())
+		}
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "download_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "lib.zip")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// sha256("hello")
+	sum := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+	tests := []struct {
+		name     string
+		checksum string
+		wantErr  string
+	}{
+		{"empty", "", ""},
+		{"match", sum, ""},
+		{"prefix and case", "sha256:" + strings.ToUpper(sum), ""},
+		{"mismatch", strings.Repeat("0", 64), "checksum mismatch"},
+		{"invalid", "abc123", "invalid sha256"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyChecksum(path, tt.checksum)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("VerifyChecksum() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("VerifyChecksum() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
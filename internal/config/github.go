@@ -2,25 +2,59 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// githubAPIBase is the GitHub API root; overridden in tests
+var githubAPIBase = "https://api.github.com"
+
+// githubClient is the HTTP client for GitHub API requests
+var githubClient = &http.Client{Timeout: 30 * time.Second}
+
+// githubRetries is how many times a GitHub API request is attempted on network or server errors
+var githubRetries = 3
+
+// githubRetryDelay is the delay before the first retry; it doubles on each attempt
+var githubRetryDelay = time.Second
+
+// errReleaseNotFound is returned when the GitHub API has no matching release
+var errReleaseNotFound = errors.New("release not found")
+
+// errRateLimited is returned when the GitHub API rate limit has been exceeded
+var errRateLimited = errors.New("GitHub API rate limit exceeded; set GITHUB_TOKEN")
+
 // GitHubRelease represents a GitHub release
 type GitHubRelease struct {
-	TagName string `json:"tag_name"`
-	Assets  []struct {
+	TagName     string `json:"tag_name"`
+	Draft       bool   `json:"draft"`
+	Prerelease  bool   `json:"prerelease"`
+	PublishedAt string `json:"published_at"`
+	Assets      []struct {
 		Name               string `json:"name"`
 		BrowserDownloadURL string `json:"browser_download_url"`
 	} `json:"assets"`
 }
 
+// Release selection policies for GitHub sources without a version
+const (
+	ReleaseLatest           = "latest"                      // Newest stable release
+	ReleaseLatestPrerelease = "latest-including-prerelease" // Newest release, including pre-releases
+)
+
 // ResolveGitHubURL checks if a URL is a GitHub repo URL and resolves it to a release asset URL.
+// release selects the release when no version is given: latest (default),
+// latest-including-prerelease, or a specific tag.
+// assetPattern optionally selects the asset by name (glob or regex).
 // Returns the resolved URL and any error encountered.
 // If the URL is not a GitHub repo URL, returns the original URL unchanged.
-func ResolveGitHubURL(uri string, slug string, version string) (string, error) {
+func ResolveGitHubURL(uri string, slug string, version string, release string, assetPattern string) (string, error) {
 	// Check if this is a GitHub repo URL (not already a release/raw URL)
 	if !isGitHubRepoURL(uri) {
 		return uri, nil
@@ -34,11 +68,11 @@ func ResolveGitHubURL(uri string, slug string, version string) (string, error) {
 
 	// If version specified, get that specific release
 	if version != "" {
-		return getGitHubReleaseAsset(owner, repo, slug, version)
+		return getGitHubReleaseAsset(owner, repo, slug, version, assetPattern)
 	}
 
-	// Otherwise get latest release
-	return getGitHubLatestReleaseAsset(owner, repo, slug)
+	// Otherwise select a release by policy
+	return getGitHubLatestReleaseAsset(owner, repo, slug, release, assetPattern)
 }
 
 // isGitHubRepoURL checks if URL is a GitHub repository URL (not a raw/release download URL)
@@ -79,33 +113,34 @@ func parseGitHubRepoURL(uri string) (string, string, error) {
 }
 
 // getGitHubReleaseAsset gets the download URL for a specific release version
-func getGitHubReleaseAsset(owner, repo, slug, version string) (string, error) {
+func getGitHubReleaseAsset(owner, repo, slug, version, assetPattern string) (string, error) {
 	// Try with 'v' prefix first, then without
 	tags := []string{"v" + version, version}
 
 	for _, tag := range tags {
-		url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/tags/%s", owner, repo, tag)
+		url := fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s", githubAPIBase, owner, repo, tag)
 
 		release, err := fetchGitHubRelease(url)
-		if err != nil {
+		if errors.Is(err, errReleaseNotFound) {
 			continue
 		}
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch release %s of %s/%s: %w", tag, owner, repo, err)
+		}
 
 		// Look for matching asset
-		assetURL := findReleaseAsset(release, slug, version)
+		assetURL := findReleaseAsset(release, slug, version, assetPattern)
 		if assetURL != "" {
 			return assetURL, nil
 		}
 	}
 
-	return "", fmt.Errorf("no release found for version %s in %s/%s", version, owner, repo)
+	return "", fmt.Errorf("no release found for version %s in %s/%s%s", version, owner, repo, privateRepoHint())
 }
 
-// getGitHubLatestReleaseAsset gets the download URL for the latest release
-func getGitHubLatestReleaseAsset(owner, repo, slug string) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest", owner, repo)
-
-	release, err := fetchGitHubRelease(url)
+// getGitHubLatestReleaseAsset gets the download URL for the release selected by policy
+func getGitHubLatestReleaseAsset(owner, repo, slug, policy, assetPattern string) (string, error) {
+	release, err := selectGitHubRelease(owner, repo, policy)
 	if err != nil {
 		return "", fmt.Errorf("no releases found for %s/%s: %w", owner, repo, err)
 	}
@@ -114,44 +149,180 @@ func getGitHubLatestReleaseAsset(owner, repo, slug string) (string, error) {
 	version := strings.TrimPrefix(release.TagName, "v")
 
 	// Look for matching asset
-	assetURL := findReleaseAsset(release, slug, version)
+	assetURL := findReleaseAsset(release, slug, version, assetPattern)
 	if assetURL != "" {
 		return assetURL, nil
 	}
 
-	return "", fmt.Errorf("no matching asset found in latest release of %s/%s", owner, repo)
+	return "", fmt.Errorf("no matching asset found in release %s of %s/%s", release.TagName, owner, repo)
+}
+
+// selectGitHubRelease picks a release by policy: latest (the default) uses
+// /releases/latest, latest-including-prerelease picks the newest published
+// release, and anything else is fetched as a tag. When /releases/latest 404s,
+// the release list is searched instead.
+func selectGitHubRelease(owner, repo, policy string) (*GitHubRelease, error) {
+	switch policy {
+	case "", ReleaseLatest:
+		url := fmt.Sprintf("%s/repos/%s/%s/releases/latest", githubAPIBase, owner, repo)
+		release, err := fetchGitHubRelease(url)
+		if !errors.Is(err, errReleaseNotFound) {
+			return release, err
+		}
+		return newestGitHubRelease(owner, repo, false)
+	case ReleaseLatestPrerelease:
+		return newestGitHubRelease(owner, repo, true)
+	default:
+		url := fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s", githubAPIBase, owner, repo, policy)
+		release, err := fetchGitHubRelease(url)
+		if errors.Is(err, errReleaseNotFound) {
+			return nil, fmt.Errorf("release tag %s not found%s", policy, privateRepoHint())
+		}
+		return release, err
+	}
+}
+
+// newestGitHubRelease lists a repository's releases and returns the most recently
+// published one, skipping drafts and, unless prerelease is set, pre-releases
+func newestGitHubRelease(owner, repo string, prerelease bool) (*GitHubRelease, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=100", githubAPIBase, owner, repo)
+	var releases []GitHubRelease
+	if err := fetchGitHubJSON(url, &releases); err != nil {
+		return nil, err
+	}
+
+	var newest *GitHubRelease
+	skipped := 0
+	for i := range releases {
+		r := &releases[i]
+		if r.Draft {
+			continue
+		}
+		if r.Prerelease && !prerelease {
+			skipped++
+			continue
+		}
+		// RFC 3339 timestamps in UTC compare correctly as strings
+		if newest == nil || r.PublishedAt > newest.PublishedAt {
+			newest = r
+		}
+	}
+
+	if newest == nil {
+		if skipped > 0 {
+			return nil, fmt.Errorf("only pre-releases found; set release: %s to use them", ReleaseLatestPrerelease)
+		}
+		return nil, errReleaseNotFound
+	}
+	return newest, nil
 }
 
 // fetchGitHubRelease fetches release info from GitHub API
 func fetchGitHubRelease(url string) (*GitHubRelease, error) {
+	var release GitHubRelease
+	if err := fetchGitHubJSON(url, &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// fetchGitHubJSON fetches a GitHub API URL and decodes the JSON response into v,
+// retrying network and server errors with backoff
+func fetchGitHubJSON(url string, v interface{}) error {
+	var err error
+	delay := githubRetryDelay
+	for attempt := 1; attempt <= githubRetries; attempt++ {
+		var retry bool
+		retry, err = fetchGitHubJSONOnce(url, v)
+		if err == nil || !retry {
+			return err
+		}
+		if attempt < githubRetries {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return fmt.Errorf("%w (after %d attempts)", err, githubRetries)
+}
+
+// fetchGitHubJSONOnce makes a single GitHub API request. The bool result
+// reports whether the failure is transient and worth retrying.
+func fetchGitHubJSONOnce(url string, v interface{}) (bool, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("User-Agent", "wordsmith")
+	authorizeGitHub(req)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := githubClient.Do(req)
 	if err != nil {
-		return nil, err
+		return true, fmt.Errorf("GitHub API request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+		return resp.StatusCode >= 500, githubAPIError(resp)
 	}
 
-	var release GitHubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, err
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return false, fmt.Errorf("failed to parse GitHub API response: %w", err)
 	}
 
-	return &release, nil
+	return false, nil
+}
+
+// githubAPIError builds an error from a non-200 GitHub API response, using the
+// message GitHub returns in the body
+func githubAPIError(resp *http.Response) error {
+	var body struct {
+		Message string `json:"message"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+
+	rateLimited := resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden &&
+			(resp.Header.Get("X-RateLimit-Remaining") == "0" || strings.Contains(strings.ToLower(body.Message), "rate limit")))
+	if rateLimited {
+		if reset := rateLimitReset(resp.Header.Get("X-RateLimit-Reset")); reset != "" {
+			return fmt.Errorf("%w (resets at %s)", errRateLimited, reset)
+		}
+		return errRateLimited
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w%s", errReleaseNotFound, privateRepoHint())
+	}
+
+	hint := ""
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
+		hint = privateRepoHint()
+	}
+	if body.Message != "" {
+		return fmt.Errorf("GitHub API returned status %d: %s%s", resp.StatusCode, body.Message, hint)
+	}
+	return fmt.Errorf("GitHub API returned status %d%s", resp.StatusCode, hint)
 }
 
-// findReleaseAsset finds a matching zip asset in a release
-func findReleaseAsset(release *GitHubRelease, slug, version string) string {
+// rateLimitReset formats the X-RateLimit-Reset header (Unix seconds) as a local time
+func rateLimitReset(header string) string {
+	seconds, err := strconv.ParseInt(header, 10, 64)
+	if err != nil || seconds <= 0 {
+		return ""
+	}
+	return time.Unix(seconds, 0).Format("15:04:05 MST")
+}
+
+// findReleaseAsset finds a matching zip asset in a release. If assetPattern is set,
+// the first asset whose name matches it is used before the naming heuristics.
+func findReleaseAsset(release *GitHubRelease, slug, version, assetPattern string) string {
+	if assetPattern != "" {
+		if url := findReleaseAssetByPattern(release, assetPattern); url != "" {
+			return url
+		}
+	}
+
 	// Try common naming patterns
 	patterns := []string{
 		fmt.Sprintf("%s-%s.zip", slug, version),      // slug-version.zip
@@ -177,5 +348,34 @@ func findReleaseAsset(release *GitHubRelease, slug, version string) string {
 		}
 	}
 
+	// Last resort: a tarball asset
+	for _, asset := range release.Assets {
+		if isTarGzName(asset.Name) {
+			return asset.BrowserDownloadURL
+		}
+	}
+
+	return ""
+}
+
+// findReleaseAssetByPattern returns the first asset whose name matches pattern,
+// trying it as a glob (e.g. "*-dist.zip") first and then as a regular expression
+func findReleaseAssetByPattern(release *GitHubRelease, pattern string) string {
+	for _, asset := range release.Assets {
+		if matched, err := filepath.Match(pattern, asset.Name); err == nil && matched {
+			return asset.BrowserDownloadURL
+		}
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return ""
+	}
+	for _, asset := range release.Assets {
+		if re.MatchString(asset.Name) {
+			return asset.BrowserDownloadURL
+		}
+	}
+
 	return ""
 }
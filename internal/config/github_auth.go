@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// GitHub token environment variables, in order of precedence
+const (
+	WordsmithGitHubTokenEnv = "WORDSMITH_GITHUB_TOKEN"
+	GitHubTokenEnv          = "GITHUB_TOKEN"
+)
+
+// downloadClient fetches archives. The GitHub token is only ever sent to GitHub
+// hosts: asset downloads redirect to objects.githubusercontent.com, and the
+// Authorization header is dropped before following a redirect there.
+var downloadClient = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		if !isGitHubHost(req.URL.Hostname()) {
+			req.Header.Del("Authorization")
+		}
+		return nil
+	},
+}
+
+// releaseDownloadPattern matches a release asset URL: /owner/repo/releases/download/tag/name
+var releaseDownloadPattern = regexp.MustCompile(`^/([^/]+)/([^/]+)/releases/download/([^/]+)/([^/]+)$`)
+
+// GitHubToken returns the GitHub token from WORDSMITH_GITHUB_TOKEN or GITHUB_TOKEN
+func GitHubToken() string {
+	if token := strings.TrimSpace(os.Getenv(WordsmithGitHubTokenEnv)); token != "" {
+		return token
+	}
+	return strings.TrimSpace(os.Getenv(GitHubTokenEnv))
+}
+
+// isGitHubHost checks if a host name belongs to GitHub (or the API base used in tests)
+func isGitHubHost(host string) bool {
+	if host == "github.com" || host == "api.github.com" {
+		return true
+	}
+	if base, err := url.Parse(githubAPIBase); err == nil {
+		return host == base.Hostname()
+	}
+	return false
+}
+
+// authorizeGitHub adds the GitHub token to a request for a GitHub host
+func authorizeGitHub(req *http.Request) {
+	if token := GitHubToken(); token != "" && isGitHubHost(req.URL.Hostname()) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// HTTPGet downloads a URL, authenticating GitHub requests with GitHubToken. Release
+// assets of private repositories 404 at their browser download URL, so with a token
+// a 404 there is retried through the release asset API.
+func HTTPGet(rawURL string) (*http.Response, error) {
+	resp, err := getAuthorized(rawURL, "")
+	if err != nil || resp.StatusCode != http.StatusNotFound || GitHubToken() == "" {
+		return resp, err
+	}
+
+	apiURL, lookupErr := releaseAssetAPIURL(rawURL)
+	if lookupErr != nil || apiURL == "" {
+		return resp, err
+	}
+	resp.Body.Close()
+	return getAuthorized(apiURL, "application/octet-stream")
+}
+
+// getAuthorized makes a GET request with the GitHub token and an optional Accept header
+func getAuthorized(rawURL, accept string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "wordsmith")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	authorizeGitHub(req)
+	return downloadClient.Do(req)
+}
+
+// releaseAssetAPIURL looks up the API URL of a release asset from its browser
+// download URL. Returns "" if the URL isn't a GitHub release download.
+func releaseAssetAPIURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || !isGitHubHost(u.Hostname()) {
+		return "", err
+	}
+	match := releaseDownloadPattern.FindStringSubmatch(u.Path)
+	if match == nil {
+		return "", nil
+	}
+	owner, repo, tag, name := match[1], match[2], match[3], match[4]
+
+	var release struct {
+		Assets []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"assets"`
+	}
+	if err := fetchGitHubJSON(fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s", githubAPIBase, owner, repo, tag), &release); err != nil {
+		return "", err
+	}
+	for _, asset := range release.Assets {
+		if asset.Name == name {
+			return asset.URL, nil
+		}
+	}
+	return "", nil
+}
+
+// privateRepoHint explains a 404 or 403 from GitHub that may be a private repository
+func privateRepoHint() string {
+	if GitHubToken() == "" {
+		return " (if the repository is private, set GITHUB_TOKEN or WORDSMITH_GITHUB_TOKEN)"
+	}
+	return " (if the repository is private, check that the GitHub token can read it)"
+}
+
+// GitHubAuthHint returns privateRepoHint for a 404 or 403 download from GitHub, or ""
+func GitHubAuthHint(rawURL string, status int) string {
+	if status != http.StatusNotFound && status != http.StatusForbidden {
+		return ""
+	}
+	if u, err := url.Parse(rawURL); err != nil || !isGitHubHost(u.Hostname()) {
+		return ""
+	}
+	return privateRepoHint()
+}
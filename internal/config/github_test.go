@@ -1,7 +1,13 @@
 package config
 
 import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestIsGitHubRepoURL(t *testing.T) {
@@ -142,6 +148,7 @@ func TestFindReleaseAsset(t *testing.T) {
 		}{
 			{Name: "my-plugin-1.0.0.zip", BrowserDownloadURL: "https://github.com/owner/repo/releases/download/v1.0.0/my-plugin-1.0.0.zip"},
 			{Name: "source.tar.gz", BrowserDownloadURL: "https://github.com/owner/repo/releases/download/v1.0.0/source.tar.gz"},
+			{Name: "build.zip", BrowserDownloadURL: "https://github.com/owner/repo/releases/download/v1.0.0/build.zip"},
 		},
 	}
 
@@ -149,6 +156,7 @@ func TestFindReleaseAsset(t *testing.T) {
 		name        string
 		slug        string
 		version     string
+		pattern     string
 		expectedURL string
 	}{
 		{
@@ -163,11 +171,32 @@ func TestFindReleaseAsset(t *testing.T) {
 			version:     "2.0.0",
 			expectedURL: "https://github.com/owner/repo/releases/download/v1.0.0/my-plugin-1.0.0.zip",
 		},
+		{
+			name:        "Glob asset pattern",
+			slug:        "my-plugin",
+			version:     "1.0.0",
+			pattern:     "build*.zip",
+			expectedURL: "https://github.com/owner/repo/releases/download/v1.0.0/build.zip",
+		},
+		{
+			name:        "Regex asset pattern",
+			slug:        "my-plugin",
+			version:     "1.0.0",
+			pattern:     `^b\w+\.zip$`,
+			expectedURL: "https://github.com/owner/repo/releases/download/v1.0.0/build.zip",
+		},
+		{
+			name:        "Unmatched asset pattern falls back to heuristics",
+			slug:        "my-plugin",
+			version:     "1.0.0",
+			pattern:     "*-dist.zip",
+			expectedURL: "https://github.com/owner/repo/releases/download/v1.0.0/my-plugin-1.0.0.zip",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := findReleaseAsset(release, tt.slug, tt.version)
+			result := findReleaseAsset(release, tt.slug, tt.version, tt.pattern)
 			if result != tt.expectedURL {
 				t.Errorf("findReleaseAsset() = %q, want %q", result, tt.expectedURL)
 			}
@@ -178,7 +207,7 @@ func TestFindReleaseAsset(t *testing.T) {
 func TestResolveGitHubURL_NonGitHubURL(t *testing.T) {
 	// Non-GitHub URLs should be returned unchanged
 	uri := "https://example.com/plugin.zip"
-	result, err := ResolveGitHubURL(uri, "plugin", "1.0.0")
+	result, err := ResolveGitHubURL(uri, "plugin", "1.0.0", "", "")
 	if err != nil {
 		t.Errorf("ResolveGitHubURL() unexpected error: %v", err)
 	}
@@ -190,7 +219,7 @@ func TestResolveGitHubURL_NonGitHubURL(t *testing.T) {
 func TestResolveGitHubURL_AlreadyReleaseURL(t *testing.T) {
 	// Already a release download URL should be returned unchanged
 	uri := "https://github.com/owner/repo/releases/download/v1.0.0/plugin.zip"
-	result, err := ResolveGitHubURL(uri, "plugin", "1.0.0")
+	result, err := ResolveGitHubURL(uri, "plugin", "1.0.0", "", "")
 	if err != nil {
 		t.Errorf("ResolveGitHubURL() unexpected error: %v", err)
 	}
@@ -198,3 +227,180 @@ func TestResolveGitHubURL_AlreadyReleaseURL(t *testing.T) {
 		t.Errorf("ResolveGitHubURL() = %q, want %q", result, uri)
 	}
 }
+
+func TestFetchGitHubReleaseErrors(t *testing.T) {
+	savedDelay := githubRetryDelay
+	githubRetryDelay = time.Millisecond
+	defer func() { githubRetryDelay = savedDelay }()
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/limited":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "1700000000")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"API rate limit exceeded for 1.2.3.4."}`))
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not Found"}`))
+		case "/flaky":
+			attempts++
+			if attempts < 2 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.Write([]byte(`{"tag_name":"v1.2.3"}`))
+		case "/invalid":
+			w.Write([]byte(`<html>`))
+		}
+	}))
+	defer server.Close()
+
+	_, err := fetchGitHubRelease(server.URL + "/limited")
+	if !errors.Is(err, errRateLimited) {
+		t.Errorf("expected rate limit error, got %v", err)
+	} else if !strings.Contains(err.Error(), "resets at") {
+		t.Errorf("rate limit error should include the reset time: %v", err)
+	}
+
+	if _, err := fetchGitHubRelease(server.URL + "/missing"); !errors.Is(err, errReleaseNotFound) {
+		t.Errorf("expected release not found error, got %v", err)
+	}
+
+	release, err := fetchGitHubRelease(server.URL + "/flaky")
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if release.TagName != "v1.2.3" || attempts != 2 {
+		t.Errorf("TagName = %q after %d attempts, want v1.2.3 after 2", release.TagName, attempts)
+	}
+
+	if _, err := fetchGitHubRelease(server.URL + "/invalid"); err == nil || !strings.Contains(err.Error(), "failed to parse") {
+		t.Errorf("expected parse error, got %v", err)
+	}
+}
+
+func TestSelectGitHubRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/prerelease-only/releases/latest", "/repos/owner/prerelease-only/releases/tags/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not Found"}`))
+		case "/repos/owner/prerelease-only/releases":
+			w.Write([]byte(`[
+				{"tag_name":"v2.0.0-draft","draft":true,"published_at":""},
+				{"tag_name":"v1.1.0-beta","prerelease":true,"published_at":"2024-05-01T00:00:00Z"},
+				{"tag_name":"v1.0.0-beta","prerelease":true,"published_at":"2024-03-01T00:00:00Z"}
+			]`))
+		case "/repos/owner/mixed/releases/latest":
+			w.WriteHeader(http.StatusNotFound)
+		case "/repos/owner/mixed/releases":
+			w.Write([]byte(`[
+				{"tag_name":"v1.1.0-rc1","prerelease":true,"published_at":"2024-05-01T00:00:00Z"},
+				{"tag_name":"v1.0.0","published_at":"2024-03-01T00:00:00Z"}
+			]`))
+		case "/repos/owner/prerelease-only/releases/tags/v1.0.0-beta":
+			w.Write([]byte(`{"tag_name":"v1.0.0-beta","prerelease":true}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	savedBase := githubAPIBase
+	githubAPIBase = server.URL
+	defer func() { githubAPIBase = savedBase }()
+
+	tests := []struct {
+		repo    string
+		policy  string
+		wantTag string
+		wantErr bool
+	}{
+		{"mixed", "", "v1.0.0", false},
+		{"mixed", ReleaseLatest, "v1.0.0", false},
+		{"mixed", ReleaseLatestPrerelease, "v1.1.0-rc1", false},
+		{"prerelease-only", ReleaseLatest, "", true},
+		{"prerelease-only", ReleaseLatestPrerelease, "v1.1.0-beta", false},
+		{"prerelease-only", "v1.0.0-beta", "v1.0.0-beta", false},
+		{"prerelease-only", "missing", "", true},
+	}
+
+	for _, tt := range tests {
+		release, err := selectGitHubRelease("owner", tt.repo, tt.policy)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("selectGitHubRelease(%s, %q) expected error, got %s", tt.repo, tt.policy, release.TagName)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("selectGitHubRelease(%s, %q) error: %v", tt.repo, tt.policy, err)
+			continue
+		}
+		if release.TagName != tt.wantTag {
+			t.Errorf("selectGitHubRelease(%s, %q) = %s, want %s", tt.repo, tt.policy, release.TagName, tt.wantTag)
+		}
+	}
+}
+
+func TestHTTPGetPrivateReleaseAsset(t *testing.T) {
+	t.Setenv(WordsmithGitHubTokenEnv, "")
+	t.Setenv(GitHubTokenEnv, "secret")
+
+	// The signed asset storage is on another host and must not see the token
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Error("GitHub token was sent to a non-GitHub host")
+		}
+		w.Write([]byte("PK\x03\x04"))
+	}))
+	defer storage.Close()
+	storageURL := strings.Replace(storage.URL, "127.0.0.1", "localhost", 1)
+
+	var api *httptest.Server
+	api = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorized := r.Header.Get("Authorization") == "Bearer secret"
+		switch r.URL.Path {
+		case "/owner/private/releases/download/v1.0.0/lib.zip":
+			// Private assets 404 at the browser download URL even with a token
+			w.WriteHeader(http.StatusNotFound)
+		case "/repos/owner/private/releases/tags/v1.0.0":
+			if !authorized {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"tag_name":"v1.0.0","assets":[{"name":"lib.zip","url":"` + api.URL + `/repos/owner/private/releases/assets/1"}]}`))
+		case "/repos/owner/private/releases/assets/1":
+			if !authorized || r.Header.Get("Accept") != "application/octet-stream" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			http.Redirect(w, r, storageURL+"/lib.zip", http.StatusFound)
+		}
+	}))
+	defer api.Close()
+
+	saved := githubAPIBase
+	githubAPIBase = api.URL
+	defer func() { githubAPIBase = saved }()
+
+	resp, err := HTTPGet(api.URL + "/owner/private/releases/download/v1.0.0/lib.zip")
+	if err != nil {
+		t.Fatalf("HTTPGet() error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "PK\x03\x04" {
+		t.Errorf("HTTPGet() = %d %q, want the asset from storage", resp.StatusCode, body)
+	}
+
+	if hint := GitHubAuthHint(api.URL+"/owner/private/releases/download/v1.0.0/lib.zip", http.StatusNotFound); !strings.Contains(hint, "token") {
+		t.Errorf("GitHubAuthHint() = %q, want a hint about the token", hint)
+	}
+	if hint := GitHubAuthHint(storageURL+"/lib.zip", http.StatusNotFound); hint != "" {
+		t.Errorf("GitHubAuthHint() = %q for a non-GitHub host, want none", hint)
+	}
+}
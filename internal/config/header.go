@@ -0,0 +1,83 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// headerReadSize is how much of a file WordPress reads to find its header
+const headerReadSize = 8192
+
+// PluginHeaderFields are the header fields of a plugin's main PHP file
+var PluginHeaderFields = []string{
+	"Plugin Name", "Plugin URI", "Description", "Version", "Author", "Author URI",
+	"License", "License URI", "Text Domain", "Domain Path", "Requires at least",
+	"Requires PHP", "Requires Plugins", "Update URI", "Network",
+}
+
+// ThemeHeaderFields are the header fields of a theme's style.css
+var ThemeHeaderFields = []string{
+	"Theme Name", "Theme URI", "Description", "Version", "Author", "Author URI",
+	"License", "License URI", "Template", "Text Domain", "Domain Path",
+	"Requires at least", "Requires PHP", "Tags",
+}
+
+// headerCommentEnd matches the end of a comment or PHP block after a header value
+var headerCommentEnd = regexp.MustCompile(`\s*(?:\*/|\?>).*$`)
+
+// ReadFileHeader reads the given header fields ("Plugin Name: ...") from the start
+// of a PHP or CSS file, the way WordPress's get_file_data() does. Fields that
+// aren't present are left out of the result.
+func ReadFileHeader(path string, fields []string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, headerReadSize))
+	if err != nil {
+		return nil, err
+	}
+	content := strings.ReplaceAll(string(data), "\r", "\n")
+
+	header := make(map[string]string)
+	for _, field := range fields {
+		re := regexp.MustCompile(`(?mi)^(?:[ \t]*<\?php)?[ \t/*#@]*` + regexp.QuoteMeta(field) + `:(.*)$`)
+		match := re.FindStringSubmatch(content)
+		if match == nil {
+			continue
+		}
+		if value := strings.TrimSpace(headerCommentEnd.ReplaceAllString(match[1], "")); value != "" {
+			header[field] = value
+		}
+	}
+
+	return header, nil
+}
+
+// FindPluginMainFile finds the top-level PHP file in dir with a Plugin Name header,
+// returning its name and header. Returns "" if there is none.
+func FindPluginMainFile(dir string) (string, map[string]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.php"))
+	if err != nil {
+		return "", nil, err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		header, err := ReadFileHeader(file, PluginHeaderFields)
+		if err != nil {
+			return "", nil, err
+		}
+		if header["Plugin Name"] != "" {
+			return filepath.Base(file), header, nil
+		}
+	}
+
+	return "", nil, nil
+}
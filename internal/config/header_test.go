@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadFileHeader(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "header_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{
+		"helper.php": "<?php\n// Helpers, no header\nfunction acme() {}\n",
+		"acme.php": "<?php\n/**\n * Plugin Name: Acme Forms\n * Version:     2.3.1\n" +
+			" * Description: Forms for Acme */\n# Text Domain: acme\n * Requires at least: 6.0\n */\n",
+		"style.css": "/*\nTheme Name: Acme\nTemplate: twentytwentyfour\nTags: blog, one-column\n*/\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mainFile, header, err := FindPluginMainFile(tmpDir)
+	if err != nil {
+		t.Fatalf("FindPluginMainFile failed: %v", err)
+	}
+	if mainFile != "acme.php" {
+		t.Errorf("main file = %q, want acme.php", mainFile)
+	}
+	want := map[string]string{
+		"Plugin Name":       "Acme Forms",
+		"Version":           "2.3.1",
+		"Description":       "Forms for Acme",
+		"Text Domain":       "acme",
+		"Requires at least": "6.0",
+	}
+	for field, value := range want {
+		if header[field] != value {
+			t.Errorf("%s = %q, want %q", field, header[field], value)
+		}
+	}
+	if _, ok := header["Author"]; ok {
+		t.Errorf("missing Author should be left out, got %q", header["Author"])
+	}
+
+	theme, err := ReadFileHeader(filepath.Join(tmpDir, "style.css"), ThemeHeaderFields)
+	if err != nil {
+		t.Fatalf("ReadFileHeader failed: %v", err)
+	}
+	if theme["Theme Name"] != "Acme" || theme["Template"] != "twentytwentyfour" || theme["Tags"] != "blog, one-column" {
+		t.Errorf("theme header = %v", theme)
+	}
+
+	empty, err := os.MkdirTemp("", "header_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(empty)
+	if mainFile, _, err := FindPluginMainFile(empty); err != nil || mainFile != "" {
+		t.Errorf("FindPluginMainFile(empty) = %q, %v; want no main file", mainFile, err)
+	}
+}
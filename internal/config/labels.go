@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseLabels parses the labels property, which can be a map or a list of key=value entries.
+// Labels are applied to Docker containers and images.
+func ParseLabels(props Properties) (map[string]string, error) {
+	labels := make(map[string]string)
+
+	val, ok := props["labels"]
+	if !ok || val == nil {
+		return labels, nil
+	}
+
+	var m map[string]interface{}
+	switch v := val.(type) {
+	case Properties:
+		m = v
+	case map[string]interface{}:
+		m = v
+	default:
+		return ParseLabelList(props.GetList("labels"))
+	}
+
+	for key, value := range m {
+		if err := validateLabelKey(key); err != nil {
+			return nil, err
+		}
+		labels[key] = fmt.Sprintf("%v", value)
+	}
+	return labels, nil
+}
+
+// ParseLabelList parses a list of key=value label entries
+func ParseLabelList(items []string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, item := range items {
+		eqIdx := strings.Index(item, "=")
+		if eqIdx == -1 {
+			return nil, fmt.Errorf("invalid label %q (expected key=value)", item)
+		}
+
+		key := strings.TrimSpace(item[:eqIdx])
+		if err := validateLabelKey(key); err != nil {
+			return nil, err
+		}
+		labels[key] = strings.TrimSpace(item[eqIdx+1:])
+	}
+	return labels, nil
+}
+
+// MergeLabels returns a new map containing base overridden by extra
+func MergeLabels(base, extra map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(extra))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range extra {
+		merged[key] = value
+	}
+	return merged
+}
+
+// validateLabelKey checks that a label key is usable and doesn't clash with the
+// wordsmith.* labels used to track environments
+func validateLabelKey(key string) error {
+	if key == "" || strings.ContainsAny(key, " \t=") {
+		return fmt.Errorf("invalid label key %q", key)
+	}
+	if strings.HasPrefix(key, "wordsmith.") {
+		return fmt.Errorf("label key %q is reserved", key)
+	}
+	return nil
+}
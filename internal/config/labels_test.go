@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "not set",
+			content: "name: Test\n",
+			want:    map[string]string{},
+		},
+		{
+			name: "map",
+			content: `labels:
+  traefik.enable: true
+  team: web
+`,
+			want: map[string]string{"traefik.enable": "true", "team": "web"},
+		},
+		{
+			name: "list",
+			content: `labels:
+  - traefik.enable=true
+  - team=web
+`,
+			want: map[string]string{"traefik.enable": "true", "team": "web"},
+		},
+		{
+			name:    "comma separated",
+			content: "labels=team=web,owner=ops\n",
+			want:    map[string]string{"team": "web", "owner": "ops"},
+		},
+		{
+			name:    "missing value separator",
+			content: "labels=team\n",
+			wantErr: true,
+		},
+		{
+			name:    "reserved key",
+			content: "labels=wordsmith.project=other\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir, err := os.MkdirTemp("", "labels_test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(tmpDir)
+
+			path := filepath.Join(tmpDir, "wordpress.properties")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			props, err := ParseProperties(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ParseLabels(props)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseLabels() = %v, want %v", got, tt.want)
+			}
+			for key, value := range tt.want {
+				if got[key] != value {
+					t.Errorf("ParseLabels()[%q] = %q, want %q", key, got[key], value)
+				}
+			}
+		})
+	}
+}
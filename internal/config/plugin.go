@@ -2,13 +2,16 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"path/filepath"
+	"regexp"
 )
 
 // PluginConfig represents the plugin.properties configuration
 type PluginConfig struct {
 	Name        string
 	Slug        string
+	ZipFolder   string // Top-level folder inside the zip (defaults to the slug)
 	Version     string
 	Description string
 	Author      string
@@ -22,6 +25,17 @@ type PluginConfig struct {
 	Requires    string
 	RequiresPHP string
 
+	// Update URI header: a URL, or "false" to opt out of WordPress.org updates
+	UpdateURI string
+
+	// readme.txt fields, used when the build generates readme.txt
+	Tags         []string
+	Contributors []string // WordPress.org usernames (defaults to the author)
+	TestedUpTo   string   // Latest WordPress version tested with
+
+	// Network-only plugin (adds "Network: true" to the header); only meaningful on multisite
+	Network bool
+
 	// Additional files/directories to include (supports wildcards: *.php, **/*.php)
 	Include []string
 
@@ -37,11 +51,20 @@ type PluginConfig struct {
 	// Obfuscate PHP files
 	Obfuscate bool
 
+	// PHP files/directories to copy without obfuscation (supports wildcards)
+	ObfuscateExclude []string
+
 	// Minify CSS/JS files
 	Minify bool
 
+	// CSS/JS files/directories to copy without minification (supports wildcards)
+	MinifyExclude []string
+
 	// Settings to deploy to WordPress database
 	Settings map[string]interface{}
+
+	// Settings per deploy profile, merged over Settings when the profile is active
+	ProfileSettings map[string]map[string]interface{}
 }
 
 // LoadPluginProperties loads plugin configuration from plugin.properties file
@@ -53,27 +76,36 @@ func LoadPluginProperties(dir string) (*PluginConfig, error) {
 	}
 
 	config := &PluginConfig{
-		Name:        props.Get("name"),
-		Slug:        props.Get("slug"),
-		Version:     props.Get("version"),
-		Description: props.Get("description"),
-		Author:      props.Get("author"),
-		AuthorURI:   props.Get("author-uri"),
-		PluginURI:   props.Get("plugin-uri"),
-		License:     props.Get("license"),
-		LicenseURI:  props.Get("license-uri"),
-		Main:        props.Get("main"),
-		TextDomain:  props.Get("text-domain"),
-		DomainPath:  props.Get("domain-path"),
-		Requires:    props.Get("requires"),
-		RequiresPHP: props.Get("requires-php"),
-		Include:     props.GetList("include"),
-		Exclude:     props.GetList("exclude"),
-		Libraries:   ParseLibraries(props),
-		Plugins:     ParsePlugins(props),
-		Obfuscate:   props.GetBool("obfuscate"),
-		Minify:      props.GetBool("minify"),
-		Settings:    ParseSettings(props),
+		Name:             props.Get("name"),
+		Slug:             props.Get("slug"),
+		ZipFolder:        props.Get("zip-folder"),
+		Version:          props.Get("version"),
+		Description:      props.Get("description"),
+		Author:           props.Get("author"),
+		AuthorURI:        props.Get("author-uri"),
+		PluginURI:        props.Get("plugin-uri"),
+		License:          props.Get("license"),
+		LicenseURI:       props.Get("license-uri"),
+		Main:             props.Get("main"),
+		TextDomain:       props.Get("text-domain"),
+		DomainPath:       props.Get("domain-path"),
+		Requires:         props.Get("requires"),
+		RequiresPHP:      props.Get("requires-php"),
+		UpdateURI:        props.Get("update-uri"),
+		Tags:             props.GetList("tags"),
+		Contributors:     props.GetList("contributors"),
+		TestedUpTo:       props.Get("tested-up-to"),
+		Network:          props.GetBool("network"),
+		Include:          props.GetList("include"),
+		Exclude:          props.GetList("exclude"),
+		Libraries:        ParseLibraries(props),
+		Plugins:          ParsePlugins(props),
+		Obfuscate:        props.GetBool("obfuscate"),
+		ObfuscateExclude: props.GetList("obfuscate-exclude"),
+		Minify:           props.GetBool("minify"),
+		MinifyExclude:    props.GetList("minify-exclude"),
+		Settings:         ParseSettings(props),
+		ProfileSettings:  ParseProfileSettings(props),
 	}
 
 	// Validate required fields
@@ -83,10 +115,29 @@ func LoadPluginProperties(dir string) (*PluginConfig, error) {
 	if config.Main == "" {
 		return nil, fmt.Errorf("missing required field: main")
 	}
+	if err := validateZipFolder(config.ZipFolder); err != nil {
+		return nil, err
+	}
+	if config.UpdateURI != "" && config.UpdateURI != "false" {
+		if u, err := url.Parse(config.UpdateURI); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid update-uri %q: must be a URL or false", config.UpdateURI)
+		}
+	}
 
 	return config, nil
 }
 
+// zipFolderPattern matches a single safe directory name: no separators, and not . or ..
+var zipFolderPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// validateZipFolder checks the zip-folder option, which may be empty
+func validateZipFolder(folder string) error {
+	if folder != "" && !zipFolderPattern.MatchString(folder) {
+		return fmt.Errorf("invalid zip-folder %q (use a single folder name of letters, numbers, dots, dashes, and underscores)", folder)
+	}
+	return nil
+}
+
 // PluginExists checks if plugin.properties exists in the directory
 func PluginExists(dir string) bool {
 	return PropertiesFileExists(dir, "plugin.properties")
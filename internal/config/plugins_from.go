@@ -0,0 +1,196 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"wordsmith/internal/ui"
+)
+
+// PluginsFromTTL is how long a fetched plugins-from list is reused before it's
+// fetched again
+const PluginsFromTTL = 10 * time.Minute
+
+// pluginsFromBaseDir is where fetched plugins-from lists are cached, under the home directory
+const pluginsFromBaseDir = ".wordsmith/plugins-from"
+
+// maxPluginsFromSize is the largest plugins-from list accepted
+const maxPluginsFromSize = 1 << 20
+
+// pluginsFromList is a shared list of plugins and themes, in the same formats
+// as the plugins and themes options
+type pluginsFromList struct {
+	Plugins interface{} `json:"plugins"`
+	Themes  interface{} `json:"themes"`
+}
+
+// parsePluginsFromURL parses the plugins-from option. The list itself is only
+// fetched when plugins and themes are installed (see ApplyPluginsFrom), so
+// commands that just load the configuration work offline.
+func parsePluginsFromURL(props Properties) (string, error) {
+	from := props.Get("plugins-from")
+	if from != "" && !strings.HasPrefix(from, "http://") && !strings.HasPrefix(from, "https://") {
+		return "", fmt.Errorf("invalid plugins-from %q (use an http(s) URL of a JSON list)", from)
+	}
+	return from, nil
+}
+
+// ApplyPluginsFrom fetches the plugins-from list, if set, and merges it into the
+// plugins and themes: shared entries come first, and a configured entry replaces
+// the shared one with the same slug
+func (c *WordPressConfig) ApplyPluginsFrom() error {
+	plugins, themes, err := applyPluginsFrom(c.PluginsFrom, c.Plugins, c.Themes)
+	if err != nil {
+		return err
+	}
+	c.Plugins, c.Themes, c.PluginsFrom = plugins, themes, ""
+	return nil
+}
+
+// ApplyPluginsFrom fetches the plugins-from list, if set, and merges it into the
+// site's plugins and themes like WordPressConfig.ApplyPluginsFrom
+func (s *SiteConfig) ApplyPluginsFrom() error {
+	plugins, themes, err := applyPluginsFrom(s.PluginsFrom, s.Plugins, s.Themes)
+	if err != nil {
+		return err
+	}
+	s.Plugins, s.Themes, s.PluginsFrom = plugins, themes, ""
+	return nil
+}
+
+// applyPluginsFrom merges the plugins and themes of the list at from, if set,
+// into the configured ones
+func applyPluginsFrom(from string, plugins []WordPressPlugin, themes []WordPressTheme) ([]WordPressPlugin, []WordPressTheme, error) {
+	if from == "" {
+		return plugins, themes, nil
+	}
+	sharedPlugins, sharedThemes, err := FetchPluginsFrom(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mergePlugins(sharedPlugins, plugins), mergeThemes(sharedThemes, themes), nil
+}
+
+// FetchPluginsFrom returns the plugins and themes in a shared JSON list, such as
+// {"plugins": [{"slug": "akismet", "version": "5.3"}], "themes": ["astra"]}.
+// The list is cached for PluginsFromTTL; if it can't be fetched, an expired
+// cached copy is used with a warning.
+func FetchPluginsFrom(url string) ([]WordPressPlugin, []WordPressTheme, error) {
+	cachePath := pluginsFromCachePath(url)
+
+	var content []byte
+	if info, err := os.Stat(cachePath); err == nil && !NoCache() && time.Since(info.ModTime()) < PluginsFromTTL {
+		content, _ = os.ReadFile(cachePath)
+	}
+
+	if content == nil {
+		fetched, err := fetchPluginsFrom(url)
+		if err != nil {
+			stale, readErr := os.ReadFile(cachePath)
+			if readErr != nil || NoCache() {
+				return nil, nil, err
+			}
+			ui.PrintWarning("%v, using the cached copy", err)
+			fetched = stale
+		} else if cachePath != "" && !NoCache() {
+			if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+				os.WriteFile(cachePath, fetched, 0644)
+			}
+		}
+		content = fetched
+	}
+
+	list, err := parsePluginsFrom(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid plugins-from list %s: %w", url, err)
+	}
+	return parsePluginsList(list.Plugins), parseThemesList(list.Themes), nil
+}
+
+// fetchPluginsFrom downloads a plugins-from list, checking that it parses
+func fetchPluginsFrom(url string) ([]byte, error) {
+	resp, err := HTTPGet(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch plugins-from %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch plugins-from %s: HTTP %d", url, resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxPluginsFromSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch plugins-from %s: %w", url, err)
+	}
+	if len(content) > maxPluginsFromSize {
+		return nil, fmt.Errorf("plugins-from %s is larger than %s", url, formatBytes(maxPluginsFromSize))
+	}
+	if _, err := parsePluginsFrom(content); err != nil {
+		return nil, fmt.Errorf("invalid plugins-from list %s: %w", url, err)
+	}
+	return content, nil
+}
+
+// parsePluginsFrom decodes a plugins-from list
+func parsePluginsFrom(content []byte) (*pluginsFromList, error) {
+	var list pluginsFromList
+	if err := json.Unmarshal(content, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// pluginsFromCachePath returns where a plugins-from list is cached, or "" if
+// there's no home directory
+func pluginsFromCachePath(url string) string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(homeDir, pluginsFromBaseDir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// mergePlugins adds plugins to shared ones, replacing a shared plugin with the same slug
+func mergePlugins(shared, plugins []WordPressPlugin) []WordPressPlugin {
+	merged := append([]WordPressPlugin(nil), shared...)
+	for _, plugin := range plugins {
+		replaced := false
+		for i := range merged {
+			if merged[i].Slug == plugin.Slug {
+				merged[i], replaced = plugin, true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, plugin)
+		}
+	}
+	return merged
+}
+
+// mergeThemes adds themes to shared ones, replacing a shared theme with the same slug
+func mergeThemes(shared, themes []WordPressTheme) []WordPressTheme {
+	merged := append([]WordPressTheme(nil), shared...)
+	for _, theme := range themes {
+		replaced := false
+		for i := range merged {
+			if merged[i].Slug == theme.Slug {
+				merged[i], replaced = theme, true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, theme)
+		}
+	}
+	return merged
+}
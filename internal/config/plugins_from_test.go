@@ -0,0 +1,173 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestApplyPluginsFrom(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(NoCacheEnv, "")
+
+	requests := 0
+	list := `{"plugins": [{"slug": "akismet", "version": 5.3}, "query-monitor"], "themes": ["astra"]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(list))
+	}))
+	defer server.Close()
+
+	tmpDir, err := os.MkdirTemp("", "plugins_from_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	content := "plugins-from: " + server.URL + "/plugins.json\nplugins:\n  - slug: akismet\n    version: 5.4\n  - classic-editor\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "wordpress.properties"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadWordPressProperties(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadWordPressProperties() error = %v", err)
+	}
+
+	// Loading the configuration doesn't fetch the list
+	if requests != 0 || len(cfg.Plugins) != 2 {
+		t.Fatalf("LoadWordPressProperties() fetched the list (%d requests, %d plugins)", requests, len(cfg.Plugins))
+	}
+	if err := cfg.ApplyPluginsFrom(); err != nil {
+		t.Fatalf("ApplyPluginsFrom() error = %v", err)
+	}
+
+	// Shared plugins come first; a local entry replaces the shared one with its slug
+	want := []struct{ slug, version string }{{"akismet", "5.4"}, {"query-monitor", ""}, {"classic-editor", ""}}
+	if len(cfg.Plugins) != len(want) {
+		t.Fatalf("got %d plugins, want %d: %+v", len(cfg.Plugins), len(want), cfg.Plugins)
+	}
+	for i, w := range want {
+		if cfg.Plugins[i].Slug != w.slug || cfg.Plugins[i].Version != w.version {
+			t.Errorf("plugin %d = %s %s, want %s %s", i, cfg.Plugins[i].Slug, cfg.Plugins[i].Version, w.slug, w.version)
+		}
+	}
+	if len(cfg.Themes) != 1 || cfg.Themes[0].Slug != "astra" || !cfg.Themes[0].Active {
+		t.Errorf("themes = %+v, want astra (active)", cfg.Themes)
+	}
+
+	// The list is cached, and the cached copy is used when the server is down
+	if err := loadAndApplyPluginsFrom(tmpDir); err != nil {
+		t.Fatalf("ApplyPluginsFrom() error = %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("list fetched %d times, want 1 (cached)", requests)
+	}
+	cachePath := pluginsFromCachePath(server.URL + "/plugins.json")
+	expired := time.Now().Add(-2 * PluginsFromTTL)
+	if err := os.Chtimes(cachePath, expired, expired); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+	if err := loadAndApplyPluginsFrom(tmpDir); err != nil {
+		t.Errorf("expired cache should be used when the list can't be fetched, got %v", err)
+	}
+}
+
+func TestApplyPluginsFromOnly(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(NoCacheEnv, "")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"plugins": ["query-monitor"], "themes": ["astra"]}`))
+	}))
+	defer server.Close()
+
+	tmpDir, err := os.MkdirTemp("", "plugins_from_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// A properties file with nothing but the shared list
+	content := "plugins-from: " + server.URL + "/plugins.json\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "wordpress.properties"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadWordPressProperties(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadWordPressProperties() error = %v", err)
+	}
+	if len(cfg.Plugins) != 0 || len(cfg.Themes) != 0 {
+		t.Fatalf("LoadWordPressProperties() plugins = %+v, themes = %+v, want none before ApplyPluginsFrom", cfg.Plugins, cfg.Themes)
+	}
+	if err := cfg.ApplyPluginsFrom(); err != nil {
+		t.Fatalf("ApplyPluginsFrom() error = %v", err)
+	}
+	if len(cfg.Plugins) != 1 || cfg.Plugins[0].Slug != "query-monitor" {
+		t.Errorf("plugins = %+v, want query-monitor", cfg.Plugins)
+	}
+	if len(cfg.Themes) != 1 || cfg.Themes[0].Slug != "astra" {
+		t.Errorf("themes = %+v, want astra", cfg.Themes)
+	}
+}
+
+// loadAndApplyPluginsFrom loads wordpress.properties from dir and merges its plugins-from list
+func loadAndApplyPluginsFrom(dir string) error {
+	cfg, err := LoadWordPressProperties(dir)
+	if err != nil {
+		return err
+	}
+	return cfg.ApplyPluginsFrom()
+}
+
+func TestApplyPluginsFromErrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("not json"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		from    string
+		loadErr bool // The URL is rejected when loading, not when fetching
+	}{
+		{"not a URL", "plugins.json", true},
+		{"not found", server.URL + "/missing.json", false},
+		{"invalid JSON", server.URL + "/plugins.json", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir, err := os.MkdirTemp("", "plugins_from_test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(tmpDir)
+
+			if err := os.WriteFile(filepath.Join(tmpDir, "wordpress.properties"), []byte("plugins-from: "+tt.from+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadWordPressProperties(tmpDir)
+			if (err != nil) != tt.loadErr {
+				t.Fatalf("LoadWordPressProperties() error = %v, want error %v", err, tt.loadErr)
+			}
+			if tt.loadErr {
+				return
+			}
+			if err := cfg.ApplyPluginsFrom(); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -197,3 +197,35 @@ func PropertiesFileExists(dir, filename string) bool {
 	path := filepath.Join(dir, filename)
 	return FileExists(path)
 }
+
+// ProjectFiles lists the properties files that mark a wordsmith project root
+var ProjectFiles = []string{
+	"plugin.properties",
+	"theme.properties",
+	"library.properties",
+	"site.properties",
+	"wordpress.properties",
+}
+
+// FindProjectRoot walks up from dir to the nearest directory containing a
+// project properties file. Returns false if no parent directory has one.
+func FindProjectRoot(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		for _, name := range ProjectFiles {
+			if PropertiesFileExists(dir, name) {
+				return dir, true
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
@@ -602,6 +602,52 @@ requires-php=7.4`,
 				}
 			},
 		},
+		{
+			name: "update uri url",
+			content: `name=My Plugin
+main=my-plugin.php
+update-uri=https://example.com/my-plugin`,
+			validate: func(t *testing.T, cfg *PluginConfig) {
+				if cfg.UpdateURI != "https://example.com/my-plugin" {
+					t.Errorf("UpdateURI = %q, want %q", cfg.UpdateURI, "https://example.com/my-plugin")
+				}
+			},
+		},
+		{
+			name:    "update uri false",
+			content: "name: My Plugin\nmain: my-plugin.php\nupdate-uri: false\n",
+			validate: func(t *testing.T, cfg *PluginConfig) {
+				if cfg.UpdateURI != "false" {
+					t.Errorf("UpdateURI = %q, want %q", cfg.UpdateURI, "false")
+				}
+			},
+		},
+		{
+			name: "invalid update uri",
+			content: `name=My Plugin
+main=my-plugin.php
+update-uri=my-plugin`,
+			expectError: true,
+		},
+		{
+			name: "zip folder",
+			content: `name=My Plugin
+main=my-plugin.php
+zip-folder=my-plugin-pro`,
+			expectError: false,
+			validate: func(t *testing.T, cfg *PluginConfig) {
+				if cfg.ZipFolder != "my-plugin-pro" {
+					t.Errorf("ZipFolder = %q, want %q", cfg.ZipFolder, "my-plugin-pro")
+				}
+			},
+		},
+		{
+			name: "zip folder with a path",
+			content: `name=My Plugin
+main=my-plugin.php
+zip-folder=../my-plugin`,
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -680,3 +726,39 @@ func TestLoadPluginPropertiesFileNotFound(t *testing.T) {
 		t.Error("Expected error when plugin.properties doesn't exist")
 	}
 }
+
+func TestFindProjectRoot(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "project_root_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	nested := filepath.Join(tmpDir, "includes", "admin")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// No properties file anywhere in the tree
+	if _, found := FindProjectRoot(nested); found {
+		t.Error("FindProjectRoot should return false when no properties file exists")
+	}
+
+	err = os.WriteFile(filepath.Join(tmpDir, "plugin.properties"), []byte("name=Test\nmain=test.php"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, found := FindProjectRoot(nested)
+	if !found {
+		t.Fatal("FindProjectRoot should find plugin.properties in a parent directory")
+	}
+	if root != tmpDir {
+		t.Errorf("FindProjectRoot = %q, want %q", root, tmpDir)
+	}
+
+	root, found = FindProjectRoot(tmpDir)
+	if !found || root != tmpDir {
+		t.Errorf("FindProjectRoot(root) = %q, %v, want %q, true", root, found, tmpDir)
+	}
+}
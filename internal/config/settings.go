@@ -1,7 +1,9 @@
 package config
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -39,6 +41,84 @@ func ParseSettings(props Properties) map[string]interface{} {
 	return settings
 }
 
+// ParseProfileSettings parses per-profile settings declared under profiles:
+//
+//	profiles:
+//	  staging:
+//	    settings:
+//	      - option=value
+//
+// Returns a map of profile names to their parsed settings.
+func ParseProfileSettings(props Properties) map[string]map[string]interface{} {
+	profiles := make(map[string]map[string]interface{})
+
+	var m map[string]interface{}
+	switch v := props["profiles"].(type) {
+	case Properties:
+		m = v
+	case map[string]interface{}:
+		m = v
+	default:
+		return profiles
+	}
+
+	for name, value := range m {
+		switch v := value.(type) {
+		case Properties:
+			profiles[name] = ParseSettings(v)
+		case map[string]interface{}:
+			profiles[name] = ParseSettings(Properties(v))
+		default:
+			profiles[name] = make(map[string]interface{})
+		}
+	}
+	return profiles
+}
+
+// SettingsForProfile returns the settings to deploy for a profile: the base
+// settings with the profile's settings merged over them. An empty profile
+// returns the base settings; an unknown profile is an error.
+func (c *PluginConfig) SettingsForProfile(profile string) (map[string]interface{}, error) {
+	if profile == "" {
+		return c.Settings, nil
+	}
+
+	overrides, ok := c.ProfileSettings[profile]
+	if !ok {
+		names := make([]string, 0, len(c.ProfileSettings))
+		for name := range c.ProfileSettings {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("unknown profile '%s' (no profiles defined)", profile)
+		}
+		return nil, fmt.Errorf("unknown profile '%s' (available: %s)", profile, strings.Join(names, ", "))
+	}
+
+	return MergeSettings(c.Settings, overrides), nil
+}
+
+// MergeSettings returns base with overrides applied. Nested option maps are
+// merged key by key; any other override value replaces the base value.
+func MergeSettings(base, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overrides))
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range overrides {
+		overrideMap, isMap := value.(map[string]interface{})
+		baseMap, baseIsMap := merged[key].(map[string]interface{})
+		if isMap && baseIsMap {
+			merged[key] = MergeSettings(baseMap, overrideMap)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
 // parseAndSetBracketNotation parses a bracket notation key like "option[key1][key2]"
 // and sets the value in the nested map structure.
 func parseAndSetBracketNotation(settings map[string]interface{}, key, value string) {
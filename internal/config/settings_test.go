@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSettingsForProfile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "settings_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	content := `name: My Plugin
+main: my-plugin.php
+settings:
+  - blogname=Dev Site
+  - my_plugin[mode]=debug
+  - my_plugin[api]=http://localhost
+profiles:
+  staging:
+    settings:
+      - my_plugin[api]=https://staging.example.com
+      - my_plugin_cache=on
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "plugin.properties"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadPluginProperties(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadPluginProperties() error = %v", err)
+	}
+
+	base, err := cfg.SettingsForProfile("")
+	if err != nil {
+		t.Fatalf("SettingsForProfile(\"\") error = %v", err)
+	}
+	if base["my_plugin"].(map[string]interface{})["api"] != "http://localhost" {
+		t.Errorf("base my_plugin = %v, want the base api", base["my_plugin"])
+	}
+
+	staging, err := cfg.SettingsForProfile("staging")
+	if err != nil {
+		t.Fatalf("SettingsForProfile(staging) error = %v", err)
+	}
+	if staging["blogname"] != "Dev Site" || staging["my_plugin_cache"] != "on" {
+		t.Errorf("staging settings = %v, want base and profile options", staging)
+	}
+	plugin := staging["my_plugin"].(map[string]interface{})
+	if plugin["api"] != "https://staging.example.com" || plugin["mode"] != "debug" {
+		t.Errorf("staging my_plugin = %v, want api overridden and mode kept", plugin)
+	}
+	if cfg.Settings["my_plugin"].(map[string]interface{})["api"] != "http://localhost" {
+		t.Error("merging a profile should not modify the base settings")
+	}
+
+	if _, err := cfg.SettingsForProfile("production"); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+}
@@ -13,9 +13,36 @@ type SiteConfig struct {
 	URL         string // Site URL
 
 	// WordPress configuration (same as WordPressConfig)
-	Image   string            // Docker image (defaults to "wordpress:latest")
-	Plugins []WordPressPlugin // Plugins from site.properties
-	Themes  []WordPressTheme  // Themes from site.properties
+	Image         string            // Docker image (defaults to "wordpress:latest")
+	DBImage       string            // Database image (defaults to "mysql:8.0"; MariaDB images work too)
+	InitSQL       string            // SQL file run when the database is first created
+	EnvFile       string            // docker --env-file for the WordPress container
+	Hostname      string            // Custom hostname for siteurl/home (e.g. mysite.local)
+	Port          int               // WordPress port on the host (0 picks a free one)
+	DBPort        int               // MySQL port on the host (0 picks a free one)
+	RestartPolicy string            // Docker restart policy: no, unless-stopped, or always
+	PHPExtensions []string          // PHP extensions to install in the WordPress container
+	UploadLimit   string            // PHP upload_max_filesize and post_max_size (e.g. 256M)
+	MemoryLimit   string            // PHP memory_limit (e.g. 512M, or -1 for no limit)
+	Memory        string            // Docker --memory limit for each container (e.g. 1g)
+	CPUs          string            // Docker --cpus limit for each container (e.g. 1.5)
+	Redis         bool              // Run a Redis container and enable the object cache
+	Labels        map[string]string // Extra labels for containers and images
+	Credentials   Credentials       // Database and admin logins
+	PluginsFrom   string            // URL of a shared plugin and theme list, merged by ApplyPluginsFrom
+	Plugins       []WordPressPlugin // Plugins from site.properties
+	Themes        []WordPressTheme  // Themes from site.properties
+
+	// Commands run once by Docker images after plugins and themes are activated
+	AfterInstall       []string
+	AfterInstallStrict bool // Fail the container if an after-install command fails
+
+	// Multisite network (multisite: true|subdirectory|subdomain)
+	Multisite  bool
+	Subdomains bool
+
+	// Activate every bundled plugin in Docker images, not just active ones (for demos)
+	ActivateAll bool
 
 	// Discovered plugins and themes from directories
 	LocalPlugins []LocalPlugin // Plugins discovered in plugins/ directory
@@ -53,7 +80,49 @@ func LoadSiteProperties(dir string) (*SiteConfig, error) {
 		Description: props.Get("description"),
 		URL:         props.Get("url"),
 		Image:       props.GetWithDefault("image", "wordpress:latest"),
+		DBImage:     props.GetWithDefault("db-image", DefaultDBImage),
+		InitSQL:     props.Get("init-sql"),
+		EnvFile:     props.Get("env-file"),
+		Hostname:    props.Get("hostname"),
+		Redis:       props.GetBool("redis"),
 	}
+	config.AfterInstall = parseCommands(props, "after-install")
+	config.AfterInstallStrict = props.GetBool("after-install-strict")
+	config.PHPExtensions = props.GetList("php-extensions")
+	config.Multisite, config.Subdomains = parseMultisite(props)
+	config.ActivateAll = props.GetBool("activate-all")
+
+	restartPolicy, err := parseRestartPolicy(props)
+	if err != nil {
+		return nil, err
+	}
+	config.RestartPolicy = restartPolicy
+
+	config.UploadLimit, config.MemoryLimit, err = parsePHPLimits(props)
+	if err != nil {
+		return nil, err
+	}
+
+	config.Port, config.DBPort, err = parsePorts(props)
+	if err != nil {
+		return nil, err
+	}
+
+	config.Memory, config.CPUs, err = parseResourceLimits(props)
+	if err != nil {
+		return nil, err
+	}
+
+	config.Credentials, err = parseCredentials(props)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := ParseLabels(props)
+	if err != nil {
+		return nil, err
+	}
+	config.Labels = labels
 
 	// Parse plugins from site.properties
 	pluginsVal, ok := props["plugins"]
@@ -67,6 +136,12 @@ func LoadSiteProperties(dir string) (*SiteConfig, error) {
 		config.Themes = parseThemesList(themesVal)
 	}
 
+	// Shared plugins and themes, which the ones above override
+	config.PluginsFrom, err = parsePluginsFromURL(props)
+	if err != nil {
+		return nil, err
+	}
+
 	// Discover local plugins in plugins/ directory
 	config.LocalPlugins = discoverLocalPlugins(dir)
 
@@ -217,10 +292,31 @@ func SiteExists(dir string) bool {
 // This merges local plugins/themes with those from site.properties
 func (s *SiteConfig) ToWordPressConfig() *WordPressConfig {
 	wpConfig := &WordPressConfig{
-		Name:    s.Name,
-		Image:   s.Image,
-		Plugins: make([]WordPressPlugin, 0),
-		Themes:  make([]WordPressTheme, 0),
+		Name:          s.Name,
+		Image:         s.Image,
+		DBImage:       s.DBImage,
+		InitSQL:       s.InitSQL,
+		EnvFile:       s.EnvFile,
+		Hostname:      s.Hostname,
+		Port:          s.Port,
+		DBPort:        s.DBPort,
+		RestartPolicy: s.RestartPolicy,
+		PHPExtensions: s.PHPExtensions,
+		UploadLimit:   s.UploadLimit,
+		MemoryLimit:   s.MemoryLimit,
+		Memory:        s.Memory,
+		CPUs:          s.CPUs,
+		Redis:         s.Redis,
+		Labels:        s.Labels,
+		Credentials:   s.Credentials,
+		Multisite:     s.Multisite,
+		Subdomains:    s.Subdomains,
+		PluginsFrom:   s.PluginsFrom,
+		Plugins:       make([]WordPressPlugin, 0),
+		Themes:        make([]WordPressTheme, 0),
+
+		AfterInstall:       s.AfterInstall,
+		AfterInstallStrict: s.AfterInstallStrict,
 	}
 
 	// Add local plugins first (they take precedence)
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const stateFile = ".wordsmith/state.json"
+
+// Browser URLs opened by `wordpress start`
+const (
+	OpenBoth  = "both"
+	OpenSite  = "site"
+	OpenAdmin = "admin"
+)
+
+// State holds per-project, per-developer preferences remembered between runs.
+// It lives in .wordsmith/state.json and should not be committed.
+type State struct {
+	Open string `json:"open,omitempty"` // URLs to open on start: both, site, or admin
+
+	// Host ports of the last environment `wordpress start` created, reused when free
+	WordPressPort int `json:"wordpressPort,omitempty"`
+	MySQLPort     int `json:"mysqlPort,omitempty"`
+}
+
+// LoadState loads the project state file, returning an empty state if it doesn't exist
+func LoadState(dir string) (*State, error) {
+	state := &State{}
+
+	data, err := os.ReadFile(filepath.Join(dir, stateFile))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", stateFile, err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", stateFile, err)
+	}
+	return state, nil
+}
+
+// Save writes the state file, creating .wordsmith/ if needed
+func (s *State) Save(dir string) error {
+	path := filepath.Join(dir, stateFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(stateFile), err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// OpenPreference returns which URLs to open on start, defaulting to both
+func (s *State) OpenPreference() string {
+	switch s.Open {
+	case OpenSite, OpenAdmin:
+		return s.Open
+	}
+	return OpenBoth
+}
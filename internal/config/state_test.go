@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestStateSaveAndLoad(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "state_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Missing state file loads as empty state with the default preference
+	state, err := LoadState(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if state.OpenPreference() != OpenBoth {
+		t.Errorf("OpenPreference() = %q, want %q", state.OpenPreference(), OpenBoth)
+	}
+
+	state.Open = OpenAdmin
+	state.WordPressPort = 8082
+	state.MySQLPort = 3307
+	if err := state.Save(tmpDir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadState(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if loaded.OpenPreference() != OpenAdmin {
+		t.Errorf("OpenPreference() = %q, want %q", loaded.OpenPreference(), OpenAdmin)
+	}
+	if loaded.WordPressPort != 8082 || loaded.MySQLPort != 3307 {
+		t.Errorf("ports = %d, %d; want 8082, 3307", loaded.WordPressPort, loaded.MySQLPort)
+	}
+}
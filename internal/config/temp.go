@@ -0,0 +1,99 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TempPrefix starts the name of every temp file and directory wordsmith creates,
+// so stray ones left by a killed run can be found and swept
+const TempPrefix = "wordsmith-"
+
+var (
+	tempDirs   = make(map[string]bool)
+	tempDirsMu sync.Mutex
+)
+
+// NewTempDir creates a uniquely named temp directory (wordsmith-<kind>-*) and
+// records it so RemoveTempDir or CleanupTempDirs can remove it
+func NewTempDir(kind string) (string, error) {
+	dir, err := os.MkdirTemp("", TempPrefix+kind+"-*")
+	if err != nil {
+		return "", err
+	}
+
+	tempDirsMu.Lock()
+	tempDirs[dir] = true
+	tempDirsMu.Unlock()
+	return dir, nil
+}
+
+// RemoveTempDir removes path if it was created by NewTempDir; other paths
+// (the library cache, local library directories) are left alone
+func RemoveTempDir(path string) {
+	tempDirsMu.Lock()
+	owned := tempDirs[path]
+	delete(tempDirs, path)
+	tempDirsMu.Unlock()
+
+	if owned {
+		os.RemoveAll(path)
+	}
+}
+
+// CleanupTempDirs removes every temp directory created by this process that
+// hasn't been released
+func CleanupTempDirs() {
+	tempDirsMu.Lock()
+	dirs := tempDirs
+	tempDirs = make(map[string]bool)
+	tempDirsMu.Unlock()
+
+	for dir := range dirs {
+		os.RemoveAll(dir)
+	}
+}
+
+// SweepTempDirs removes wordsmith temp files and directories in the system temp
+// directory that are older than olderThan, skipping those owned by this process.
+// It returns the removed paths.
+func SweepTempDirs(olderThan time.Duration) ([]string, error) {
+	root := os.TempDir()
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	tempDirsMu.Lock()
+	owned := make(map[string]bool, len(tempDirs))
+	for dir := range tempDirs {
+		owned[dir] = true
+	}
+	tempDirsMu.Unlock()
+
+	cutoff := time.Now().Add(-olderThan)
+	var removed []string
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), TempPrefix) {
+			continue
+		}
+		path := filepath.Join(root, entry.Name())
+		if owned[path] {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, path)
+	}
+
+	return removed, nil
+}
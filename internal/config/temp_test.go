@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRemoveTempDir(t *testing.T) {
+	dir, err := NewTempDir("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(filepath.Base(dir), TempPrefix+"test-") {
+		t.Errorf("temp dir %s should start with %stest-", dir, TempPrefix)
+	}
+
+	RemoveTempDir(dir)
+	if FileExists(dir) {
+		t.Error("RemoveTempDir should remove a directory created by NewTempDir")
+	}
+
+	// Directories not created by NewTempDir are left alone
+	other, err := os.MkdirTemp("", "temp_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(other)
+
+	RemoveTempDir(other)
+	if !FileExists(other) {
+		t.Error("RemoveTempDir should not remove a directory it didn't create")
+	}
+}
+
+func TestCleanupTempDirs(t *testing.T) {
+	first, err := NewTempDir("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewTempDir("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Fatal("NewTempDir should return a unique directory on each call")
+	}
+
+	CleanupTempDirs()
+	if FileExists(first) || FileExists(second) {
+		t.Error("CleanupTempDirs should remove every directory created by NewTempDir")
+	}
+}
@@ -3,12 +3,14 @@ package config
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
 )
 
 // ThemeConfig represents the theme.properties configuration
 type ThemeConfig struct {
 	Name        string
 	Slug        string
+	ZipFolder   string // Top-level folder inside the zip (defaults to the slug)
 	Version     string
 	Description string
 	Author      string
@@ -24,6 +26,7 @@ type ThemeConfig struct {
 	Requires    string
 	RequiresPHP string
 	Tags        string
+	Supports    []string // Theme features passed to add_theme_support() in functions.php
 
 	// Additional files/directories to include (supports wildcards: *.php, **/*.php)
 	Include []string
@@ -36,8 +39,14 @@ type ThemeConfig struct {
 
 	// Minify CSS/JS files
 	Minify bool
+
+	// CSS/JS files/directories to copy without minification (supports wildcards)
+	MinifyExclude []string
 }
 
+// themeFeaturePattern matches add_theme_support() feature names such as post-thumbnails
+var themeFeaturePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
 // LoadThemeProperties loads theme configuration from theme.properties file
 func LoadThemeProperties(dir string) (*ThemeConfig, error) {
 	path := filepath.Join(dir, "theme.properties")
@@ -47,33 +56,44 @@ func LoadThemeProperties(dir string) (*ThemeConfig, error) {
 	}
 
 	config := &ThemeConfig{
-		Name:        props.Get("name"),
-		Slug:        props.Get("slug"),
-		Version:     props.Get("version"),
-		Description: props.Get("description"),
-		Author:      props.Get("author"),
-		AuthorURI:   props.Get("author-uri"),
-		ThemeURI:    props.Get("theme-uri"),
-		License:     props.Get("license"),
-		LicenseURI:  props.Get("license-uri"),
-		Main:        props.GetWithDefault("main", "style.css"),
-		Template:    props.Get("template"),
-		TemplateURI: props.Get("template-uri"),
-		TextDomain:  props.Get("text-domain"),
-		DomainPath:  props.Get("domain-path"),
-		Requires:    props.Get("requires"),
-		RequiresPHP: props.Get("requires-php"),
-		Tags:        props.Get("tags"),
-		Include:     props.GetList("include"),
-		Exclude:     props.GetList("exclude"),
-		Libraries:   ParseLibraries(props),
-		Minify:      props.GetBool("minify"),
+		Name:          props.Get("name"),
+		Slug:          props.Get("slug"),
+		ZipFolder:     props.Get("zip-folder"),
+		Version:       props.Get("version"),
+		Description:   props.Get("description"),
+		Author:        props.Get("author"),
+		AuthorURI:     props.Get("author-uri"),
+		ThemeURI:      props.Get("theme-uri"),
+		License:       props.Get("license"),
+		LicenseURI:    props.Get("license-uri"),
+		Main:          props.GetWithDefault("main", "style.css"),
+		Template:      props.Get("template"),
+		TemplateURI:   props.Get("template-uri"),
+		TextDomain:    props.Get("text-domain"),
+		DomainPath:    props.Get("domain-path"),
+		Requires:      props.Get("requires"),
+		RequiresPHP:   props.Get("requires-php"),
+		Tags:          props.Get("tags"),
+		Supports:      props.GetList("supports"),
+		Include:       props.GetList("include"),
+		Exclude:       props.GetList("exclude"),
+		Libraries:     ParseLibraries(props),
+		Minify:        props.GetBool("minify"),
+		MinifyExclude: props.GetList("minify-exclude"),
 	}
 
 	// Validate required fields
 	if config.Name == "" {
 		return nil, fmt.Errorf("missing required field: name")
 	}
+	if err := validateZipFolder(config.ZipFolder); err != nil {
+		return nil, err
+	}
+	for _, feature := range config.Supports {
+		if !themeFeaturePattern.MatchString(feature) {
+			return nil, fmt.Errorf("invalid supports entry %q (use a theme feature name like post-thumbnails)", feature)
+		}
+	}
 
 	return config, nil
 }
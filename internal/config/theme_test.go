@@ -73,6 +73,21 @@ minify=true`,
 				}
 			},
 		},
+		{
+			name: "with minify exclude",
+			content: `name=My Theme
+minify=true
+minify-exclude=vendor, *.min.js`,
+			expectError: false,
+			validate: func(t *testing.T, cfg *ThemeConfig) {
+				if len(cfg.MinifyExclude) != 2 {
+					t.Fatalf("MinifyExclude count = %d, want 2", len(cfg.MinifyExclude))
+				}
+				if cfg.MinifyExclude[0] != "vendor" {
+					t.Errorf("MinifyExclude[0] = %q, want %q", cfg.MinifyExclude[0], "vendor")
+				}
+			},
+		},
 		{
 			name: "minify false",
 			content: `name=My Theme
@@ -1,33 +1,103 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 // WordPressPlugin represents a plugin to install
 type WordPressPlugin struct {
-	Slug    string
-	Version string // Specific version to install
-	URI     string // HTTP URL or file path
-	Active  bool
+	Slug         string
+	Version      string // Specific version to install
+	URI          string // HTTP URL or file path
+	Release      string // GitHub release selection: latest, latest-including-prerelease, or a tag
+	AssetPattern string // Glob or regex selecting the GitHub release asset
+	Sha256       string // Expected SHA-256 of the downloaded zip (optional)
+	Order        int    // Activation order; lower numbers activate first (order or priority)
+	Active       bool
 }
 
 // WordPressTheme represents a theme to install
 type WordPressTheme struct {
-	Slug    string
-	Version string // Specific version to install
-	URI     string // HTTP URL or file path
-	Active  bool
+	Slug         string
+	Version      string // Specific version to install
+	URI          string // HTTP URL or file path
+	Release      string // GitHub release selection: latest, latest-including-prerelease, or a tag
+	AssetPattern string // Glob or regex selecting the GitHub release asset
+	Sha256       string // Expected SHA-256 of the downloaded zip (optional)
+	Active       bool
 }
 
 // WordPressConfig represents the wordpress.properties configuration
 type WordPressConfig struct {
-	Name    string             // Instance name (optional, defaults to plugin/theme name or directory)
-	Image   string             // Docker image (defaults to "wordpress:latest")
-	Plugins []WordPressPlugin
-	Themes  []WordPressTheme
+	Name          string            // Instance name (optional, defaults to plugin/theme name or directory)
+	Image         string            // Docker image (defaults to "wordpress:latest")
+	DBImage       string            // Database image (defaults to "mysql:8.0"; MariaDB images work too)
+	InitSQL       string            // SQL file run when the database is first created
+	EnvFile       string            // docker --env-file for the WordPress container
+	Hostname      string            // Custom hostname for siteurl/home (e.g. myplugin.local)
+	Port          int               // WordPress port on the host (0 picks a free one)
+	DBPort        int               // MySQL port on the host (0 picks a free one)
+	RestartPolicy string            // Docker restart policy: no, unless-stopped, or always
+	PHPExtensions []string          // PHP extensions to install in the WordPress container
+	UploadLimit   string            // PHP upload_max_filesize and post_max_size (e.g. 256M)
+	MemoryLimit   string            // PHP memory_limit (e.g. 512M, or -1 for no limit)
+	Memory        string            // Docker --memory limit for each container (e.g. 1g)
+	CPUs          string            // Docker --cpus limit for each container (e.g. 1.5)
+	Redis         bool              // Run a Redis container and enable the object cache
+	Labels        map[string]string // Extra labels for containers and images
+	Credentials   Credentials       // Database and admin logins
+	PluginsFrom   string            // URL of a shared plugin and theme list, merged by ApplyPluginsFrom
+	Plugins       []WordPressPlugin
+	Themes        []WordPressTheme
+
+	// Commands run once by Docker images after plugins and themes are activated
+	AfterInstall       []string
+	AfterInstallStrict bool // Fail the container if an after-install command fails
+
+	// Multisite network (multisite: true|subdirectory|subdomain)
+	Multisite  bool
+	Subdomains bool
+}
+
+// DefaultDBImage is the database image used when db-image isn't set
+const DefaultDBImage = "mysql:8.0"
+
+// Database and admin logins used when wordpress.properties doesn't set them
+const (
+	DefaultDBName        = "wordpress"
+	DefaultDBUser        = "wordpress"
+	DefaultDBPassword    = "wordpress"
+	DefaultAdminUser     = "admin"
+	DefaultAdminPassword = "admin"
+	DefaultAdminEmail    = "admin@localhost.com"
+)
+
+// Credentials are the MySQL database and WordPress admin logins of an environment
+type Credentials struct {
+	DBName        string
+	DBUser        string
+	DBPassword    string
+	AdminUser     string
+	AdminPassword string
+	AdminEmail    string
+}
+
+// DefaultCredentials returns the logins used when none are configured
+func DefaultCredentials() Credentials {
+	return Credentials{
+		DBName:        DefaultDBName,
+		DBUser:        DefaultDBUser,
+		DBPassword:    DefaultDBPassword,
+		AdminUser:     DefaultAdminUser,
+		AdminPassword: DefaultAdminPassword,
+		AdminEmail:    DefaultAdminEmail,
+	}
 }
 
 // LoadWordPressProperties loads WordPress configuration from wordpress.properties file
@@ -39,8 +109,49 @@ func LoadWordPressProperties(dir string) (*WordPressConfig, error) {
 	}
 
 	config := &WordPressConfig{
-		Name:  props.Get("name"),
-		Image: props.GetWithDefault("image", "wordpress:latest"),
+		Name:     props.Get("name"),
+		Image:    props.GetWithDefault("image", "wordpress:latest"),
+		DBImage:  props.GetWithDefault("db-image", DefaultDBImage),
+		InitSQL:  props.Get("init-sql"),
+		EnvFile:  props.Get("env-file"),
+		Hostname: props.Get("hostname"),
+		Redis:    props.GetBool("redis"),
+	}
+	config.AfterInstall = parseCommands(props, "after-install")
+	config.AfterInstallStrict = props.GetBool("after-install-strict")
+	config.PHPExtensions = props.GetList("php-extensions")
+	config.Multisite, config.Subdomains = parseMultisite(props)
+
+	restartPolicy, err := parseRestartPolicy(props)
+	if err != nil {
+		return nil, err
+	}
+	config.RestartPolicy = restartPolicy
+
+	config.UploadLimit, config.MemoryLimit, err = parsePHPLimits(props)
+	if err != nil {
+		return nil, err
+	}
+
+	config.Port, config.DBPort, err = parsePorts(props)
+	if err != nil {
+		return nil, err
+	}
+
+	config.Memory, config.CPUs, err = parseResourceLimits(props)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := ParseLabels(props)
+	if err != nil {
+		return nil, err
+	}
+	config.Labels = labels
+
+	config.Credentials, err = parseCredentials(props)
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse plugins
@@ -64,178 +175,365 @@ func LoadWordPressProperties(dir string) (*WordPressConfig, error) {
 		config.Themes = parseThemesList(themesVal)
 	}
 
+	// Shared plugins and themes, which the ones above override
+	config.PluginsFrom, err = parsePluginsFromURL(props)
+	if err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
-// parsePluginsList parses the plugins list from various formats
-func parsePluginsList(val interface{}) []WordPressPlugin {
-	var plugins []WordPressPlugin
-
-	switch v := val.(type) {
+// parseCommands parses a list of shell commands. Unlike GetList, a single string is
+// one command, since commands may contain commas.
+func parseCommands(props Properties, key string) []string {
+	var commands []string
+	switch v := props[key].(type) {
 	case []interface{}:
 		for _, item := range v {
-			plugin := parsePluginItem(item)
-			if plugin.Slug != "" {
-				plugins = append(plugins, plugin)
-			}
-		}
-	case []map[string]interface{}:
-		for _, item := range v {
-			plugin := parsePluginItem(item)
-			if plugin.Slug != "" {
-				plugins = append(plugins, plugin)
+			if command := strings.TrimSpace(fmt.Sprintf("%v", item)); command != "" {
+				commands = append(commands, command)
 			}
 		}
 	case string:
-		// Single plugin as string
-		if v != "" {
-			plugins = append(plugins, WordPressPlugin{Slug: v, Active: true})
+		if command := strings.TrimSpace(v); command != "" {
+			commands = append(commands, command)
 		}
-	default:
-		// For debugging - should not happen
-		_ = v
 	}
+	return commands
+}
 
-	return plugins
+// parseRestartPolicy parses the restart-policy option, defaulting to "no"
+func parseRestartPolicy(props Properties) (string, error) {
+	policy := props.GetWithDefault("restart-policy", "no")
+	switch policy {
+	case "no", "unless-stopped", "always":
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid restart-policy %q (use no, unless-stopped, or always)", policy)
 }
 
-// parsePluginItem parses a single plugin item
-func parsePluginItem(item interface{}) WordPressPlugin {
-	switch v := item.(type) {
-	case string:
-		// Check if it's a URL
-		if strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://") {
-			// Extract slug from URL (last path component without extension)
-			slug := extractSlugFromURL(v)
-			return WordPressPlugin{Slug: slug, URI: v, Active: true}
-		}
-		// Simple slug
-		return WordPressPlugin{Slug: v, Active: true}
-	case Properties:
-		// Map with slug, version, uri, active (from our YAML parser)
-		plugin := WordPressPlugin{Active: true} // Default to active
+// parseCredentials parses the db-name, db-user, db-password, admin-user,
+// admin-password, and admin-email options, keeping the defaults for any not set
+func parseCredentials(props Properties) (Credentials, error) {
+	credentials := Credentials{
+		DBName:        props.GetWithDefault("db-name", DefaultDBName),
+		DBUser:        props.GetWithDefault("db-user", DefaultDBUser),
+		DBPassword:    props.GetWithDefault("db-password", DefaultDBPassword),
+		AdminUser:     props.GetWithDefault("admin-user", DefaultAdminUser),
+		AdminPassword: props.GetWithDefault("admin-password", DefaultAdminPassword),
+		AdminEmail:    props.GetWithDefault("admin-email", DefaultAdminEmail),
+	}
+	if !dbIdentifierPattern.MatchString(credentials.DBName) {
+		return Credentials{}, fmt.Errorf("invalid db-name %q (use letters, numbers, and underscores)", credentials.DBName)
+	}
+	if !dbIdentifierPattern.MatchString(credentials.DBUser) {
+		return Credentials{}, fmt.Errorf("invalid db-user %q (use letters, numbers, and underscores)", credentials.DBUser)
+	}
+	if !strings.Contains(credentials.AdminEmail, "@") {
+		return Credentials{}, fmt.Errorf("invalid admin-email %q (use an email address)", credentials.AdminEmail)
+	}
+	return credentials, nil
+}
 
-		if slug, ok := v["slug"].(string); ok {
-			plugin.Slug = slug
-		}
-		if version, ok := v["version"].(string); ok {
-			plugin.Version = version
-		}
-		if uri, ok := v["uri"].(string); ok {
-			plugin.URI = uri
-		}
-		if active, ok := v["active"].(bool); ok {
-			plugin.Active = active
-		} else if activeStr, ok := v["active"].(string); ok {
-			plugin.Active = !(activeStr == "false" || activeStr == "no" || activeStr == "0")
-		}
+// dbIdentifierPattern matches MySQL database and user names that need no quoting
+var dbIdentifierPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
 
-		return plugin
-	case map[string]interface{}:
-		// Map with slug, version, uri, active
-		plugin := WordPressPlugin{Active: true} // Default to active
+// phpSizePattern matches PHP shorthand byte values such as 64M or 1G
+var phpSizePattern = regexp.MustCompile(`^[0-9]+[KkMmGg]?$`)
 
-		if slug, ok := v["slug"].(string); ok {
-			plugin.Slug = slug
-		}
-		if version, ok := v["version"].(string); ok {
-			plugin.Version = version
+// parsePHPLimits parses the upload-limit and memory-limit options, which are PHP
+// shorthand sizes; memory-limit may also be -1 for no limit
+func parsePHPLimits(props Properties) (string, string, error) {
+	upload := props.Get("upload-limit")
+	if upload != "" && !phpSizePattern.MatchString(upload) {
+		return "", "", fmt.Errorf("invalid upload-limit %q (use a size like 64M or 1G)", upload)
+	}
+	memory := props.Get("memory-limit")
+	if memory != "" && memory != "-1" && !phpSizePattern.MatchString(memory) {
+		return "", "", fmt.Errorf("invalid memory-limit %q (use a size like 512M, or -1 for no limit)", memory)
+	}
+	return upload, memory, nil
+}
+
+// dockerMemoryPattern matches Docker memory sizes such as 512m or 2g
+var dockerMemoryPattern = regexp.MustCompile(`^[0-9]+[bBkKmMgG]?$`)
+
+// parseResourceLimits parses the memory and cpus options, which are passed to
+// docker run as --memory and --cpus
+func parseResourceLimits(props Properties) (string, string, error) {
+	memory := props.Get("memory")
+	if memory != "" && !dockerMemoryPattern.MatchString(memory) {
+		return "", "", fmt.Errorf("invalid memory %q (use a size like 512m or 2g)", memory)
+	}
+	cpus := props.Get("cpus")
+	if cpus != "" {
+		if n, err := strconv.ParseFloat(cpus, 64); err != nil || n <= 0 {
+			return "", "", fmt.Errorf("invalid cpus %q (use a number of CPUs like 1 or 1.5)", cpus)
 		}
-		if uri, ok := v["uri"].(string); ok {
-			plugin.URI = uri
+	}
+	return memory, cpus, nil
+}
+
+// parsePorts parses the port and db-port options, returning 0 for any not set
+func parsePorts(props Properties) (int, int, error) {
+	port, err := parsePort(props, "port")
+	if err != nil {
+		return 0, 0, err
+	}
+	dbPort, err := parsePort(props, "db-port")
+	if err != nil {
+		return 0, 0, err
+	}
+	if port != 0 && port == dbPort {
+		return 0, 0, fmt.Errorf("port and db-port are both %d (use different ports)", port)
+	}
+	return port, dbPort, nil
+}
+
+// parsePort parses a host port option, returning 0 if it isn't set
+func parsePort(props Properties, key string) (int, error) {
+	value := props.Get(key)
+	if value == "" {
+		return 0, nil
+	}
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid %s %q (use a port number from 1 to 65535)", key, value)
+	}
+	return port, nil
+}
+
+// parseMultisite parses the multisite option, which is either a boolean or the
+// network mode ("subdirectory" or "subdomain"). Returns whether multisite is
+// enabled and whether it uses subdomains.
+func parseMultisite(props Properties) (bool, bool) {
+	if !props.GetBool("multisite") {
+		return false, false
+	}
+	mode := strings.ToLower(props.Get("multisite"))
+	return true, mode == "subdomain" || mode == "subdomains"
+}
+
+// parsePluginsList parses the plugins list from various formats
+func parsePluginsList(val interface{}) []WordPressPlugin {
+	var plugins []WordPressPlugin
+	for _, item := range listItems(val) {
+		if skipped(item) {
+			continue
 		}
-		if active, ok := v["active"].(bool); ok {
-			plugin.Active = active
-		} else if activeStr, ok := v["active"].(string); ok {
-			plugin.Active = !(activeStr == "false" || activeStr == "no" || activeStr == "0")
+		plugin := parsePluginItem(item)
+		if plugin.Slug != "" {
+			plugins = append(plugins, plugin)
 		}
+	}
+	return plugins
+}
 
-		return plugin
+// parsePluginItem parses a single plugin item
+func parsePluginItem(item interface{}) WordPressPlugin {
+	if s, ok := item.(string); ok {
+		slug, uri := parseSlugOrURL(s)
+		return WordPressPlugin{Slug: slug, URI: uri, Active: true}
 	}
 
-	return WordPressPlugin{}
+	fields, ok := itemFields(item)
+	if !ok {
+		return WordPressPlugin{}
+	}
+	// Map with slug, version, uri, active; plugins default to active
+	return WordPressPlugin{
+		Slug:         stringField(fields, "slug"),
+		Version:      stringField(fields, "version"),
+		URI:          stringField(fields, "uri"),
+		Release:      stringField(fields, "release"),
+		AssetPattern: stringField(fields, "asset-pattern"),
+		Sha256:       stringField(fields, "sha256"),
+		Order:        orderField(fields),
+		Active:       boolField(fields, "active", true),
+	}
 }
 
 // parseThemesList parses the themes list from various formats
 func parseThemesList(val interface{}) []WordPressTheme {
 	var themes []WordPressTheme
-
-	switch v := val.(type) {
-	case []interface{}:
-		for i, item := range v {
-			theme := parseThemeItem(item, i == 0)
-			if theme.Slug != "" {
-				themes = append(themes, theme)
-			}
+	// The first theme that isn't skipped defaults to active
+	first := true
+	for _, item := range listItems(val) {
+		if skipped(item) {
+			continue
 		}
-	case string:
-		// Single theme as string - first theme defaults to active
-		if v != "" {
-			themes = append(themes, WordPressTheme{Slug: v, Active: true})
+		theme := parseThemeItem(item, first)
+		first = false
+		if theme.Slug != "" {
+			themes = append(themes, theme)
 		}
 	}
-
 	return themes
 }
 
 // parseThemeItem parses a single theme item
 // isFirst indicates if this is the first theme in the list (defaults to active)
 func parseThemeItem(item interface{}, isFirst bool) WordPressTheme {
-	switch v := item.(type) {
-	case string:
-		// Check if it's a URL
-		if strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://") {
-			// Extract slug from URL (last path component without extension)
-			slug := extractSlugFromURL(v)
-			return WordPressTheme{Slug: slug, URI: v, Active: isFirst}
-		}
-		// Simple slug - first theme defaults to active
-		return WordPressTheme{Slug: v, Active: isFirst}
-	case Properties:
-		// Map with slug, version, uri, active (from our YAML parser)
-		theme := WordPressTheme{Active: isFirst} // First theme defaults to active
+	if s, ok := item.(string); ok {
+		slug, uri := parseSlugOrURL(s)
+		return WordPressTheme{Slug: slug, URI: uri, Active: isFirst}
+	}
 
-		if slug, ok := v["slug"].(string); ok {
-			theme.Slug = slug
+	fields, ok := itemFields(item)
+	if !ok {
+		return WordPressTheme{}
+	}
+	// Map with slug, version, uri, active; an explicit active overrides the default
+	return WordPressTheme{
+		Slug:         stringField(fields, "slug"),
+		Version:      stringField(fields, "version"),
+		URI:          stringField(fields, "uri"),
+		Release:      stringField(fields, "release"),
+		AssetPattern: stringField(fields, "asset-pattern"),
+		Sha256:       stringField(fields, "sha256"),
+		Active:       boolField(fields, "active", isFirst),
+	}
+}
+
+// listItems normalizes a plugins or themes value into a list of entries. The
+// properties parser may produce []interface{} or []map[string]interface{} for
+// lists, a single detailed entry may be a map, and a string is a comma-separated
+// list of slugs or URLs.
+func listItems(val interface{}) []interface{} {
+	var items []interface{}
+
+	switch v := val.(type) {
+	case []interface{}:
+		items = v
+	case []map[string]interface{}:
+		for _, item := range v {
+			items = append(items, item)
 		}
-		if version, ok := v["version"].(string); ok {
-			theme.Version = version
+	case []Properties:
+		for _, item := range v {
+			items = append(items, item)
 		}
-		if uri, ok := v["uri"].(string); ok {
-			theme.URI = uri
+	case []string:
+		for _, item := range v {
+			items = append(items, item)
 		}
-		// Explicit active setting overrides default
-		if active, ok := v["active"].(bool); ok {
-			theme.Active = active
-		} else if activeStr, ok := v["active"].(string); ok {
-			theme.Active = !(activeStr == "false" || activeStr == "no" || activeStr == "0")
+	case Properties, map[string]interface{}:
+		items = append(items, v)
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			items = append(items, item)
+		}
+	}
+
+	// Trim string entries and drop empty ones
+	result := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			item = s
 		}
+		result = append(result, item)
+	}
+	return result
+}
 
-		return theme
+// itemFields returns the fields of a detailed plugin or theme entry
+func itemFields(item interface{}) (map[string]interface{}, bool) {
+	switch v := item.(type) {
+	case Properties:
+		return v, true
 	case map[string]interface{}:
-		// Map with slug, version, uri, active
-		theme := WordPressTheme{Active: isFirst} // First theme defaults to active
+		return v, true
+	}
+	return nil, false
+}
 
-		if slug, ok := v["slug"].(string); ok {
-			theme.Slug = slug
-		}
-		if version, ok := v["version"].(string); ok {
-			theme.Version = version
-		}
-		if uri, ok := v["uri"].(string); ok {
-			theme.URI = uri
-		}
-		// Explicit active setting overrides default
-		if active, ok := v["active"].(bool); ok {
-			theme.Active = active
-		} else if activeStr, ok := v["active"].(string); ok {
-			theme.Active = !(activeStr == "false" || activeStr == "no" || activeStr == "0")
-		}
+// skipped checks if a detailed plugin or theme entry has skip: true, which
+// leaves it out of the configuration without deleting it
+func skipped(item interface{}) bool {
+	fields, ok := itemFields(item)
+	return ok && boolField(fields, "skip", false)
+}
 
-		return theme
+// parseSlugOrURL parses a plugin or theme given as a string: either a slug or an
+// http(s) URL, whose slug is the last path component
+func parseSlugOrURL(s string) (string, string) {
+	if strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") {
+		return extractSlugFromURL(s), s
 	}
+	return s, ""
+}
+
+// stringField returns a field as a string, formatting non-string scalars such as
+// versions the YAML parser read as numbers
+func stringField(fields map[string]interface{}, key string) string {
+	switch v := fields[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case bool, int, int64, float64:
+		return fmt.Sprintf("%v", v)
+	}
+	return ""
+}
+
+// boolField returns a field as a bool, accepting booleans and false/no/0 strings
+func boolField(fields map[string]interface{}, key string, def bool) bool {
+	switch v := fields[key].(type) {
+	case bool:
+		return v
+	case string:
+		return !(v == "false" || v == "no" || v == "0")
+	}
+	return def
+}
 
-	return WordPressTheme{}
+// orderField returns the activation order of a plugin entry from its order or
+// priority field, or 0 if neither is a number
+func orderField(fields map[string]interface{}) int {
+	value, ok := fields["order"]
+	if !ok {
+		value = fields["priority"]
+	}
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(strings.TrimSpace(v))
+		return n
+	}
+	return 0
+}
+
+// SortPlugins sorts plugins by activation order, lowest first, keeping the listed
+// order for plugins with the same order
+func SortPlugins(plugins []WordPressPlugin) []WordPressPlugin {
+	sorted := append([]WordPressPlugin(nil), plugins...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Order < sorted[j].Order
+	})
+	return sorted
+}
+
+// ResolveEnvFile resolves an env-file path relative to the project directory and
+// checks that the file exists
+func ResolveEnvFile(dir, envFile string) (string, error) {
+	if !filepath.IsAbs(envFile) {
+		envFile = filepath.Join(dir, envFile)
+	}
+	if !FileExists(envFile) {
+		return "", fmt.Errorf("env-file not found: %s", envFile)
+	}
+	return envFile, nil
 }
 
 // WordPressExists checks if wordpress.properties exists in the directory
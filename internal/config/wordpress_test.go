@@ -849,3 +849,482 @@ func TestParseThemeItemWithURL(t *testing.T) {
 		t.Error("Expected theme to be active (first theme)")
 	}
 }
+
+func TestLoadWordPressPropertiesInitSQL(t *testing.T) {
+	content := `name: Test Site
+init-sql: db/seed.sql
+`
+	cfg, err := loadWordPressProps(t, content)
+	if err != nil {
+		t.Fatalf("LoadWordPressProperties() error = %v", err)
+	}
+	if cfg.InitSQL != "db/seed.sql" {
+		t.Errorf("InitSQL = %q, want %q", cfg.InitSQL, "db/seed.sql")
+	}
+}
+
+func TestLoadWordPressPropertiesEnvFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "wordpress_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "wordpress.properties"), []byte("env-file: .env.wordpress\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadWordPressProperties(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadWordPressProperties() error = %v", err)
+	}
+	if cfg.EnvFile != ".env.wordpress" {
+		t.Errorf("EnvFile = %q, want %q", cfg.EnvFile, ".env.wordpress")
+	}
+
+	if _, err := ResolveEnvFile(tmpDir, cfg.EnvFile); err == nil {
+		t.Error("ResolveEnvFile() should fail when the file doesn't exist")
+	}
+	envPath := filepath.Join(tmpDir, ".env.wordpress")
+	if err := os.WriteFile(envPath, []byte("WORDPRESS_DEBUG=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ResolveEnvFile(tmpDir, cfg.EnvFile); err != nil || got != envPath {
+		t.Errorf("ResolveEnvFile() = %q, %v, want %q", got, err, envPath)
+	}
+}
+
+func TestParseMultisite(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        string
+		wantMultisite  bool
+		wantSubdomains bool
+	}{
+		{"not set", "name: Test\n", false, false},
+		{"disabled", "multisite: false\n", false, false},
+		{"enabled", "multisite: true\n", true, false},
+		{"subdirectory", "multisite: subdirectory\n", true, false},
+		{"subdomain", "multisite: subdomain\n", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadWordPressProps(t, tt.content)
+			if err != nil {
+				t.Fatalf("LoadWordPressProperties() error = %v", err)
+			}
+			if cfg.Multisite != tt.wantMultisite {
+				t.Errorf("Multisite = %v, want %v", cfg.Multisite, tt.wantMultisite)
+			}
+			if cfg.Subdomains != tt.wantSubdomains {
+				t.Errorf("Subdomains = %v, want %v", cfg.Subdomains, tt.wantSubdomains)
+			}
+		})
+	}
+}
+
+func TestParseRestartPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantPolicy string
+		wantErr    bool
+	}{
+		{"default", "name: Test\n", "no", false},
+		{"unless-stopped", "restart-policy: unless-stopped\n", "unless-stopped", false},
+		{"always", "restart-policy: always\n", "always", false},
+		{"invalid", "restart-policy: sometimes\n", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadWordPressProps(t, tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("LoadWordPressProperties() expected error for invalid restart-policy")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadWordPressProperties() error = %v", err)
+			}
+			if cfg.RestartPolicy != tt.wantPolicy {
+				t.Errorf("RestartPolicy = %q, want %q", cfg.RestartPolicy, tt.wantPolicy)
+			}
+		})
+	}
+}
+
+func TestLoadWordPressPropertiesPHPExtensions(t *testing.T) {
+	content := "php-extensions:\n  - redis\n  - gd\n"
+	cfg, err := loadWordPressProps(t, content)
+	if err != nil {
+		t.Fatalf("LoadWordPressProperties() error = %v", err)
+	}
+	if len(cfg.PHPExtensions) != 2 || cfg.PHPExtensions[0] != "redis" || cfg.PHPExtensions[1] != "gd" {
+		t.Errorf("PHPExtensions = %v, want [redis gd]", cfg.PHPExtensions)
+	}
+}
+
+func TestParsePHPLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantUpload string
+		wantMemory string
+		wantErr    bool
+	}{
+		{"not set", "name: Test\n", "", "", false},
+		{"both set", "upload-limit: 256M\nmemory-limit: 1G\n", "256M", "1G", false},
+		{"unlimited memory", "memory-limit: -1\n", "", "-1", false},
+		{"plain bytes", "upload-limit: 1048576\n", "1048576", "", false},
+		{"invalid upload", "upload-limit: 256MB\n", "", "", true},
+		{"unlimited upload", "upload-limit: -1\n", "", "", true},
+		{"invalid memory", "memory-limit: lots\n", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadWordPressProps(t, tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadWordPressProperties() error = %v", err)
+			}
+			if cfg.UploadLimit != tt.wantUpload || cfg.MemoryLimit != tt.wantMemory {
+				t.Errorf("limits = %q, %q, want %q, %q", cfg.UploadLimit, cfg.MemoryLimit, tt.wantUpload, tt.wantMemory)
+			}
+		})
+	}
+}
+
+func TestParsePorts(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantPort   int
+		wantDBPort int
+		wantErr    bool
+	}{
+		{"not set", "name: Test\n", 0, 0, false},
+		{"both set", "port: 8123\ndb-port: 3345\n", 8123, 3345, false},
+		{"quoted", "port: \"8123\"\n", 8123, 0, false},
+		{"not a number", "port: http\n", 0, 0, true},
+		{"out of range", "port: 70000\n", 0, 0, true},
+		{"zero", "db-port: 0\n", 0, 0, true},
+		{"same port", "port: 8080\ndb-port: 8080\n", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadWordPressProps(t, tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadWordPressProperties() error = %v", err)
+			}
+			if cfg.Port != tt.wantPort || cfg.DBPort != tt.wantDBPort {
+				t.Errorf("ports = %d, %d, want %d, %d", cfg.Port, cfg.DBPort, tt.wantPort, tt.wantDBPort)
+			}
+		})
+	}
+}
+
+func TestParseResourceLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantMemory string
+		wantCPUs   string
+		wantErr    bool
+	}{
+		{"not set", "name: Test\n", "", "", false},
+		{"both set", "memory: 1g\ncpus: 1.5\n", "1g", "1.5", false},
+		{"whole cpus", "cpus: 2\n", "", "2", false},
+		{"invalid memory", "memory: 1GB\n", "", "", true},
+		{"invalid cpus", "cpus: half\n", "", "", true},
+		{"zero cpus", "cpus: 0\n", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadWordPressProps(t, tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadWordPressProperties() error = %v", err)
+			}
+			if cfg.Memory != tt.wantMemory || cfg.CPUs != tt.wantCPUs {
+				t.Errorf("limits = %q, %q, want %q, %q", cfg.Memory, cfg.CPUs, tt.wantMemory, tt.wantCPUs)
+			}
+		})
+	}
+}
+
+func TestLoadWordPressPropertiesDBImage(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"default", "name: Test\n", "mysql:8.0"},
+		{"mariadb", "db-image: mariadb:11\n", "mariadb:11"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadWordPressProps(t, tt.content)
+			if err != nil {
+				t.Fatalf("LoadWordPressProperties() error = %v", err)
+			}
+			if cfg.DBImage != tt.want {
+				t.Errorf("DBImage = %q, want %q", cfg.DBImage, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCredentials(t *testing.T) {
+	custom := Credentials{
+		DBName:        "shop_db",
+		DBUser:        "shop",
+		DBPassword:    "s3cret!",
+		AdminUser:     "owner",
+		AdminPassword: "hunter2",
+		AdminEmail:    "owner@example.com",
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    Credentials
+		wantErr bool
+	}{
+		{"defaults", "name: Test\n", DefaultCredentials(), false},
+		{"all set", "db-name: shop_db\ndb-user: shop\ndb-password: s3cret!\nadmin-user: owner\nadmin-password: hunter2\nadmin-email: owner@example.com\n", custom, false},
+		{"invalid db-name", "db-name: shop-db\n", Credentials{}, true},
+		{"invalid db-user", "db-user: \"shop user\"\n", Credentials{}, true},
+		{"invalid admin-email", "admin-email: owner\n", Credentials{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadWordPressProps(t, tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadWordPressProperties() error = %v", err)
+			}
+			if cfg.Credentials != tt.want {
+				t.Errorf("Credentials = %+v, want %+v", cfg.Credentials, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadWordPressPropertiesRedis(t *testing.T) {
+	cfg, err := loadWordPressProps(t, "redis: true\n")
+	if err != nil {
+		t.Fatalf("LoadWordPressProperties() error = %v", err)
+	}
+	if !cfg.Redis {
+		t.Error("Redis = false, want true")
+	}
+}
+
+func TestParsePluginsAndThemesListRepresentations(t *testing.T) {
+	detailed := func() map[string]interface{} {
+		return map[string]interface{}{"slug": "my-item", "uri": "https://example.com/my-item.zip", "version": "1.2", "active": "false"}
+	}
+
+	tests := []struct {
+		name string
+		val  interface{}
+	}{
+		{"interface list of Properties", []interface{}{"first", Properties(detailed())}},
+		{"interface list of maps", []interface{}{"first", detailed()}},
+		{"list of maps", []map[string]interface{}{{"slug": "first"}, detailed()}},
+		{"list of Properties", []Properties{{"slug": "first"}, Properties(detailed())}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugins := parsePluginsList(tt.val)
+			if len(plugins) != 2 {
+				t.Fatalf("parsePluginsList() = %+v, want 2 plugins", plugins)
+			}
+			if plugins[0].Slug != "first" || !plugins[0].Active {
+				t.Errorf("plugins[0] = %+v, want active 'first'", plugins[0])
+			}
+			want := WordPressPlugin{Slug: "my-item", URI: "https://example.com/my-item.zip", Version: "1.2", Active: false}
+			if plugins[1] != want {
+				t.Errorf("plugins[1] = %+v, want %+v", plugins[1], want)
+			}
+
+			themes := parseThemesList(tt.val)
+			if len(themes) != 2 {
+				t.Fatalf("parseThemesList() = %+v, want 2 themes", themes)
+			}
+			if themes[0].Slug != "first" || !themes[0].Active {
+				t.Errorf("themes[0] = %+v, want active 'first'", themes[0])
+			}
+			wantTheme := WordPressTheme{Slug: "my-item", URI: "https://example.com/my-item.zip", Version: "1.2", Active: false}
+			if themes[1] != wantTheme {
+				t.Errorf("themes[1] = %+v, want %+v", themes[1], wantTheme)
+			}
+		})
+	}
+}
+
+func TestParsePluginsAndThemesListScalars(t *testing.T) {
+	// A single detailed entry that isn't in a list
+	themes := parseThemesList(Properties{"slug": "solo", "uri": "../themes/solo"})
+	if len(themes) != 1 || themes[0].Slug != "solo" || themes[0].URI != "../themes/solo" || !themes[0].Active {
+		t.Errorf("parseThemesList(map) = %+v, want active 'solo' with uri", themes)
+	}
+
+	// Comma-separated string, with URLs
+	plugins := parsePluginsList("akismet, https://example.com/downloads/my-plugin.zip,")
+	if len(plugins) != 2 {
+		t.Fatalf("parsePluginsList(string) = %+v, want 2 plugins", plugins)
+	}
+	if plugins[0].Slug != "akismet" || plugins[0].URI != "" {
+		t.Errorf("plugins[0] = %+v, want 'akismet'", plugins[0])
+	}
+	if plugins[1].Slug != "my-plugin" || plugins[1].URI != "https://example.com/downloads/my-plugin.zip" {
+		t.Errorf("plugins[1] = %+v, want 'my-plugin' with uri", plugins[1])
+	}
+
+	themes = parseThemesList("twentytwentyfour,twentytwentythree")
+	if len(themes) != 2 || !themes[0].Active || themes[1].Active {
+		t.Errorf("parseThemesList(string) = %+v, want only the first theme active", themes)
+	}
+
+	// Versions read as numbers
+	plugins = parsePluginsList([]interface{}{map[string]interface{}{"slug": "woocommerce", "version": 8.5, "active": false}})
+	if len(plugins) != 1 || plugins[0].Version != "8.5" || plugins[0].Active {
+		t.Errorf("parsePluginsList(numeric version) = %+v, want version 8.5, inactive", plugins)
+	}
+}
+
+func TestLoadWordPressPropertiesDetailedThemes(t *testing.T) {
+	content := `themes:
+  - slug: my-theme
+    uri: https://example.com/my-theme.zip
+    active: true
+  - twentytwentyfour
+`
+	cfg, err := loadWordPressProps(t, content)
+	if err != nil {
+		t.Fatalf("LoadWordPressProperties() error = %v", err)
+	}
+	if len(cfg.Themes) != 2 {
+		t.Fatalf("Themes = %+v, want 2", cfg.Themes)
+	}
+	if cfg.Themes[0].Slug != "my-theme" || cfg.Themes[0].URI != "https://example.com/my-theme.zip" || !cfg.Themes[0].Active {
+		t.Errorf("Themes[0] = %+v, want active my-theme with uri", cfg.Themes[0])
+	}
+	if cfg.Themes[1].Slug != "twentytwentyfour" || cfg.Themes[1].Active {
+		t.Errorf("Themes[1] = %+v, want inactive twentytwentyfour", cfg.Themes[1])
+	}
+}
+
+func TestLoadWordPressPropertiesRelease(t *testing.T) {
+	content := `plugins:
+  - slug: beta-plugin
+    uri: https://github.com/owner/beta-plugin
+    release: latest-including-prerelease
+themes:
+  - slug: nightly-theme
+    uri: https://github.com/owner/nightly-theme
+    release: nightly
+`
+	cfg, err := loadWordPressProps(t, content)
+	if err != nil {
+		t.Fatalf("LoadWordPressProperties() error = %v", err)
+	}
+	if len(cfg.Plugins) != 1 || cfg.Plugins[0].Release != ReleaseLatestPrerelease {
+		t.Errorf("Plugins = %+v, want release %s", cfg.Plugins, ReleaseLatestPrerelease)
+	}
+	if len(cfg.Themes) != 1 || cfg.Themes[0].Release != "nightly" {
+		t.Errorf("Themes = %+v, want release nightly", cfg.Themes)
+	}
+}
+
+func TestLoadWordPressPropertiesSkip(t *testing.T) {
+	content := `plugins:
+  - slug: woocommerce
+    skip: true
+  - slug: akismet
+themes:
+  - slug: old-theme
+    skip: yes
+  - slug: astra
+  - slug: twentytwentyfour
+`
+	cfg, err := loadWordPressProps(t, content)
+	if err != nil {
+		t.Fatalf("LoadWordPressProperties() error = %v", err)
+	}
+	if len(cfg.Plugins) != 1 || cfg.Plugins[0].Slug != "akismet" {
+		t.Errorf("Plugins = %+v, want only akismet", cfg.Plugins)
+	}
+	// The first theme that isn't skipped is the active one
+	if len(cfg.Themes) != 2 || cfg.Themes[0].Slug != "astra" || !cfg.Themes[0].Active || cfg.Themes[1].Active {
+		t.Errorf("Themes = %+v, want active astra and inactive twentytwentyfour", cfg.Themes)
+	}
+}
+
+func TestLoadWordPressPropertiesAfterInstall(t *testing.T) {
+	content := `after-install:
+  - wp option update blogdescription "Demo, live"
+  - wp import /tmp/content.xml --authors=create
+after-install-strict: true
+`
+	cfg, err := loadWordPressProps(t, content)
+	if err != nil {
+		t.Fatalf("LoadWordPressProperties() error = %v", err)
+	}
+	if len(cfg.AfterInstall) != 2 {
+		t.Fatalf("AfterInstall = %q, want 2 commands", cfg.AfterInstall)
+	}
+	if cfg.AfterInstall[0] != `wp option update blogdescription "Demo, live"` {
+		t.Errorf("AfterInstall[0] = %q", cfg.AfterInstall[0])
+	}
+	if !cfg.AfterInstallStrict {
+		t.Error("AfterInstallStrict = false, want true")
+	}
+}
+
+// loadWordPressProps writes content to wordpress.properties in a temp directory
+// and loads it
+func loadWordPressProps(t *testing.T, content string) (*WordPressConfig, error) {
+	t.Helper()
+	tmpDir, err := os.MkdirTemp("", "wordpress_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "wordpress.properties"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return LoadWordPressProperties(tmpDir)
+}
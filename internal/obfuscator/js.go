@@ -1,86 +1,290 @@
 package obfuscator
 
 import (
-	"regexp"
 	"strings"
 )
 
+// JavaScript token kinds
+const (
+	jsIdentifier = iota // identifiers and keywords
+	jsNumber
+	jsLiteral // strings, template literals, and regex literals
+	jsPunctuator
+)
+
+type jsToken struct {
+	kind int
+	text string
+}
+
+// jsPunctuators are the multi-character punctuators, longest first
+var jsPunctuators = []string{
+	">>>=", "...", "===", "!==", "**=", "<<=", ">>=", ">>>", "&&=", "||=", "??=",
+	"=>", "==", "!=", "<=", ">=", "&&", "||", "??", "?.", "++", "--",
+	"+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "**", "<<", ">>",
+}
+
+// jsRegexKeywords are the keywords after which a / starts a regex literal
+var jsRegexKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true,
+	"new": true, "delete": true, "void": true, "throw": true, "case": true,
+	"do": true, "else": true, "yield": true, "await": true,
+}
+
+// MinifyJS removes comments and whitespace from JavaScript. It tokenizes the
+// source, so strings, template literals, and regex literals are copied as-is,
+// and keeps a line break wherever removing it could change automatic
+// semicolon insertion.
 func MinifyJS(source string) string {
-	result := source
-
-	// Remove multi-line comments
-	re := regexp.MustCompile(`/\*[\s\S]*?\*/`)
-	result = re.ReplaceAllString(result, "")
-
-	// Remove single-line comments (but not in strings or regex)
-	lines := strings.Split(result, "\n")
-	var newLines []string
-	for _, line := range lines {
-		if idx := findJSCommentStart(line); idx != -1 {
-			line = line[:idx]
+	var result strings.Builder
+
+	// Keep a hashbang line
+	if strings.HasPrefix(source, "#!") {
+		end := strings.IndexByte(source, '\n')
+		if end == -1 {
+			return source
 		}
-		newLines = append(newLines, line)
+		result.WriteString(source[:end+1])
+		source = source[end+1:]
 	}
-	result = strings.Join(newLines, "\n")
 
-	// Remove whitespace
-	result = regexp.MustCompile(`\s+`).ReplaceAllString(result, " ")
+	var prev *jsToken
+	space, newline := false, false
+	i := 0
 
-	// Remove spaces around operators and punctuation
-	patterns := []struct {
-		re   *regexp.Regexp
-		repl string
-	}{
-		{regexp.MustCompile(`\s*([{}\[\]();,:<>+\-*/%=!&|?])\s*`), "$1"},
-	}
+	for i < len(source) {
+		ch := source[i]
 
-	for _, p := range patterns {
-		result = p.re.ReplaceAllString(result, p.repl)
-	}
+		// Whitespace and comments only separate tokens
+		if isSpace(ch) || ch == '\f' || ch == '\v' {
+			space = true
+			newline = newline || ch == '\n' || ch == '\r'
+			i++
+			continue
+		}
+		if strings.HasPrefix(source[i:], "//") {
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+			space = true
+			continue
+		}
+		if strings.HasPrefix(source[i:], "/*") {
+			end := strings.Index(source[i+2:], "*/")
+			if end == -1 {
+				end = len(source)
+			} else {
+				end += i + 4
+			}
+			space = true
+			newline = newline || strings.ContainsAny(source[i:end], "\n\r")
+			i = end
+			continue
+		}
+
+		token := nextJSToken(source, i, prev)
+		i += len(token.text)
+
+		if prev != nil {
+			if newline && jsEndsStatement(prev) && jsStartsStatement(&token) {
+				result.WriteByte('\n')
+			} else if space && jsNeedsSpace(prev, &token) {
+				result.WriteByte(' ')
+			}
+		}
+		result.WriteString(token.text)
 
-	// Ensure space after keywords
-	keywords := []string{"return", "throw", "new", "delete", "typeof", "void", "in", "instanceof", "var", "let", "const", "if", "else", "for", "while", "do", "switch", "case", "break", "continue", "function", "class", "extends", "import", "export", "default", "try", "catch", "finally", "async", "await", "yield"}
-	for _, kw := range keywords {
-		re := regexp.MustCompile(`\b` + kw + `\b([^\s;{(])`)
-		result = re.ReplaceAllString(result, kw+" $1")
+		prev = &token
+		space, newline = false, false
 	}
 
-	return strings.TrimSpace(result)
+	return result.String()
 }
 
-func findJSCommentStart(line string) int {
-	inSingle := false
-	inDouble := false
-	inTemplate := false
-	escaped := false
+// nextJSToken reads the token starting at source[i], using the previous token
+// to tell a regex literal from division
+func nextJSToken(source string, i int, prev *jsToken) jsToken {
+	ch := source[i]
 
-	for i := 0; i < len(line); i++ {
-		if escaped {
-			escaped = false
-			continue
+	switch {
+	case ch == '\'' || ch == '"':
+		return jsToken{jsLiteral, source[i:jsStringEnd(source, i)]}
+	case ch == '`':
+		return jsToken{jsLiteral, source[i:jsTemplateEnd(source, i)]}
+	case isDigit(ch) || (ch == '.' && i+1 < len(source) && isDigit(source[i+1])):
+		return jsToken{jsNumber, source[i:jsNumberEnd(source, i)]}
+	case isJSIdentifierChar(ch) || ch == '#':
+		end := i + 1
+		for end < len(source) && isJSIdentifierChar(source[end]) {
+			end++
 		}
+		return jsToken{jsIdentifier, source[i:end]}
+	case ch == '/' && jsRegexAllowed(prev):
+		if end := jsRegexEnd(source, i); end != -1 {
+			return jsToken{jsLiteral, source[i:end]}
+		}
+	}
 
-		ch := line[i]
-
-		if ch == '\\' {
-			escaped = true
-			continue
+	for _, punctuator := range jsPunctuators {
+		if strings.HasPrefix(source[i:], punctuator) {
+			return jsToken{jsPunctuator, punctuator}
 		}
+	}
+	return jsToken{jsPunctuator, source[i : i+1]}
+}
 
-		if ch == '\'' && !inDouble && !inTemplate {
-			inSingle = !inSingle
-		} else if ch == '"' && !inSingle && !inTemplate {
-			inDouble = !inDouble
-		} else if ch == '`' && !inSingle && !inDouble {
-			inTemplate = !inTemplate
+// jsStringEnd returns the index just past the string literal starting at
+// source[i]. An unterminated string ends at the end of the line.
+func jsStringEnd(source string, i int) int {
+	quote := source[i]
+	for i++; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		case '\n':
+			return i
 		}
+	}
+	return len(source)
+}
 
-		if !inSingle && !inDouble && !inTemplate && i+1 < len(line) {
-			if line[i:i+2] == "//" {
-				return i
+// jsTemplateEnd returns the index just past the template literal starting at
+// source[i], skipping over the strings and nested templates in its ${}
+// substitutions
+func jsTemplateEnd(source string, i int) int {
+	for i++; i < len(source); i++ {
+		switch {
+		case source[i] == '\\':
+			i++
+		case source[i] == '`':
+			return i + 1
+		case strings.HasPrefix(source[i:], "${"):
+			depth := 0
+			for i += 2; i < len(source); i++ {
+				ch := source[i]
+				if ch == '}' && depth == 0 {
+					break
+				}
+				switch ch {
+				case '{':
+					depth++
+				case '}':
+					depth--
+				case '\'', '"':
+					i = jsStringEnd(source, i) - 1
+				case '`':
+					i = jsTemplateEnd(source, i) - 1
+				}
 			}
 		}
 	}
+	return len(source)
+}
+
+// jsNumberEnd returns the index just past the numeric literal starting at source[i]
+func jsNumberEnd(source string, i int) int {
+	hex := strings.HasPrefix(strings.ToLower(source[i:]), "0x")
+	for i++; i < len(source); i++ {
+		ch := source[i]
+		exponent := !hex && (ch == '+' || ch == '-') && (source[i-1] == 'e' || source[i-1] == 'E')
+		if !isIdentifierChar(ch) && ch != '.' && !exponent {
+			break
+		}
+	}
+	return i
+}
 
+// jsRegexEnd returns the index just past the regex literal, including its flags,
+// starting at source[i], or -1 if the / doesn't start one
+func jsRegexEnd(source string, i int) int {
+	inClass := false
+	for i++; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n', '\r':
+			return -1
+		case '/':
+			if inClass {
+				continue
+			}
+			for i++; i < len(source) && isJSIdentifierChar(source[i]); i++ {
+			}
+			return i
+		}
+	}
 	return -1
 }
+
+// jsRegexAllowed checks if a / after prev starts a regex literal rather than division
+func jsRegexAllowed(prev *jsToken) bool {
+	if prev == nil {
+		return true
+	}
+	switch prev.kind {
+	case jsIdentifier:
+		return jsRegexKeywords[prev.text]
+	case jsPunctuator:
+		switch prev.text {
+		case ")", "]", "}", "++", "--":
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// jsEndsStatement checks if a statement could end with token, so a line break
+// after it may be an automatically inserted semicolon
+func jsEndsStatement(token *jsToken) bool {
+	if token.kind != jsPunctuator {
+		return true
+	}
+	switch token.text {
+	case ")", "]", "}", "++", "--":
+		return true
+	}
+	return false
+}
+
+// jsStartsStatement checks if a statement could start with token, so joining
+// it to the previous line could change how the code parses
+func jsStartsStatement(token *jsToken) bool {
+	if token.kind != jsPunctuator {
+		return true
+	}
+	switch token.text {
+	case "(", "[", "{", "++", "--", "!", "~", "+", "-":
+		return true
+	}
+	return false
+}
+
+// jsNeedsSpace checks if prev and next would run together without a space
+func jsNeedsSpace(prev, next *jsToken) bool {
+	last := prev.text[len(prev.text)-1]
+	first := next.text[0]
+
+	switch {
+	case isJSIdentifierChar(last) && isJSIdentifierChar(first):
+		return true
+	case (last == '+' || last == '-') && first == last:
+		return true
+	case last == '/' && (first == '/' || first == '*'):
+		return true
+	case prev.kind == jsNumber && first == '.':
+		return true
+	}
+	return false
+}
+
+// isJSIdentifierChar checks if a byte can be part of a JavaScript identifier
+func isJSIdentifierChar(ch byte) bool {
+	return isIdentifierChar(ch) || ch == '$' || ch == '\\'
+}
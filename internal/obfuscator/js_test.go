@@ -0,0 +1,72 @@
+package obfuscator
+
+import (
+	"testing"
+)
+
+func TestMinifyJS(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			"comments and whitespace",
+			"/* header */\nfunction add(a, b) {\n    // sum\n    return a + b;\n}\n",
+			"function add(a,b){return a+b;}",
+		},
+		{
+			"regex literal",
+			"var re = /a\\/b/g; // slash\nvar ok = re.test(path);\n",
+			"var re=/a\\/b/g;var ok=re.test(path);",
+		},
+		{
+			"regex with class and comment-like text",
+			"if (/[/*]\\/\\/x/.test(s)) { return /=+/; }\n",
+			"if(/[/*]\\/\\/x/.test(s)){return/=+/;}",
+		},
+		{
+			"division is not a regex",
+			"var half = total / 2 / count; // ratio\n",
+			"var half=total/2/count;",
+		},
+		{
+			"template literal",
+			"const msg = `Hello ${x} // not a comment\n  ${ {a: '`'}.a }  /* kept */`;\n",
+			"const msg=`Hello ${x} // not a comment\n  ${ {a: '`'}.a }  /* kept */`;",
+		},
+		{
+			"comment markers in strings",
+			"var url = \"https://example.com/*\"; var s = 'it\\'s // here';\n",
+			"var url=\"https://example.com/*\";var s='it\\'s // here';",
+		},
+		{
+			"line breaks that end statements",
+			"let a = b\n(c || d).run()\nlet e = f\n++g\nreturn\nh\n",
+			"let a=b\n(c||d).run()\nlet e=f\n++g\nreturn\nh",
+		},
+		{
+			"line breaks that don't",
+			"const list = [\n  1,\n  2\n]\nfoo(a,\n  b)\n",
+			"const list=[1,2]\nfoo(a,b)",
+		},
+		{
+			"operators that would merge",
+			"a = b + +c - -d; e = 1 .toString(); f = g / /h/.source.length;\n",
+			"a=b+ +c- -d;e=1 .toString();f=g/ /h/.source.length;",
+		},
+		{
+			"keywords",
+			"return typeof x === 'string' ? x : void 0\n",
+			"return typeof x==='string'?x:void 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MinifyJS(tt.source); got != tt.want {
+				t.Errorf("MinifyJS() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
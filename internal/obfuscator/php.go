@@ -2,6 +2,7 @@ package obfuscator
 
 import (
 	"encoding/base64"
+	"fmt"
 	"regexp"
 	"strings"
 )
@@ -25,65 +26,82 @@ func Obfuscate(source string) (string, error) {
 	return result, nil
 }
 
-// stripComments removes all PHP comments
+// stripComments removes all PHP comments. It scans the whole source, tracking
+// strings (which may span lines and contain escaped quotes), heredocs, and text
+// outside <?php ... ?> tags, so // or # inside them is left alone.
 func stripComments(source string) string {
-	// Remove multi-line comments /* */
-	re := regexp.MustCompile(`/\*[\s\S]*?\*/`)
-	result := re.ReplaceAllString(source, "")
-
-	// Remove single-line comments // but not inside strings
-	// This is simplified - we handle it line by line
-	lines := strings.Split(result, "\n")
-	var newLines []string
-
-	for _, line := range lines {
-		// Remove // comments (simplified - doesn't handle // inside strings perfectly)
-		if idx := findCommentStart(line, "//"); idx != -1 {
-			line = line[:idx]
-		}
-		// Remove # comments
-		if idx := findCommentStart(line, "#"); idx != -1 {
-			line = line[:idx]
-		}
-		newLines = append(newLines, line)
-	}
+	var result strings.Builder
+	inPHP := false
+	i := 0
 
-	return strings.Join(newLines, "\n")
-}
+	for i < len(source) {
+		// Outside PHP tags, copy everything up to the next opening tag
+		if !inPHP {
+			open := strings.Index(source[i:], "<?")
+			if open == -1 {
+				result.WriteString(source[i:])
+				break
+			}
+			result.WriteString(source[i : i+open+2])
+			i += open + 2
+			inPHP = true
+			continue
+		}
 
-// findCommentStart finds comment marker outside of strings
-func findCommentStart(line, marker string) int {
-	inSingle := false
-	inDouble := false
-	escaped := false
+		ch := source[i]
 
-	for i := 0; i < len(line); i++ {
-		if escaped {
-			escaped = false
+		// Strings, heredocs, and nowdocs are copied as they are
+		if ch == '\'' || ch == '"' || ch == '`' {
+			str, end := extractString(source, i, ch)
+			result.WriteString(str)
+			i = end
+			continue
+		}
+		if end, _ := heredocEnd(source, i); end != -1 {
+			result.WriteString(source[i:end])
+			i = end
 			continue
 		}
 
-		ch := line[i]
-
-		if ch == '\\' {
-			escaped = true
+		if strings.HasPrefix(source[i:], "?>") {
+			result.WriteString("?>")
+			i += 2
+			inPHP = false
 			continue
 		}
 
-		if ch == '\'' && !inDouble {
-			inSingle = !inSingle
-		} else if ch == '"' && !inSingle {
-			inDouble = !inDouble
+		// Block comments become a space, so tokens on either side stay apart
+		if strings.HasPrefix(source[i:], "/*") {
+			end := strings.Index(source[i+2:], "*/")
+			if end == -1 {
+				break
+			}
+			i += 2 + end + 2
+			if result.Len() > 0 && !isSpace(result.String()[result.Len()-1]) {
+				result.WriteByte(' ')
+			}
+			continue
 		}
 
-		if !inSingle && !inDouble && i+len(marker) <= len(line) {
-			if line[i:i+len(marker)] == marker {
-				return i
+		// Line comments run to the end of the line or a closing ?> tag. #[ starts
+		// a PHP 8 attribute, not a comment.
+		if strings.HasPrefix(source[i:], "//") || (ch == '#' && !strings.HasPrefix(source[i:], "#[")) {
+			for i < len(source) && source[i] != '\n' && !strings.HasPrefix(source[i:], "?>") {
+				i++
 			}
+			continue
 		}
+
+		result.WriteByte(ch)
+		i++
 	}
 
-	return -1
+	return result.String()
+}
+
+// isSpace checks if a byte is ASCII whitespace
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
 }
 
 // encodeStrings encodes string literals to base64
@@ -92,6 +110,13 @@ func encodeStrings(source string) string {
 	i := 0
 
 	for i < len(source) {
+		// Leave heredocs and nowdocs as they are
+		if end, _ := heredocEnd(source, i); end != -1 {
+			result.WriteString(source[i:end])
+			i = end
+			continue
+		}
+
 		// Check for single-quoted string
 		if source[i] == '\'' {
 			str, end := extractString(source, i, '\'')
@@ -161,11 +186,132 @@ func extractString(source string, i int, quote byte) (string, int) {
 	return str.String(), i
 }
 
+// heredocEnd returns the position just after the closing identifier of a heredoc
+// (<<<EOT or <<<"EOT") or nowdoc (<<<'EOT') starting at i, and whether it is a
+// nowdoc. The closing identifier may be indented (PHP 7.3+). Returns -1 if there
+// is no heredoc at i.
+func heredocEnd(source string, i int) (int, bool) {
+	if !strings.HasPrefix(source[i:], "<<<") {
+		return -1, false
+	}
+	j := i + 3
+	for j < len(source) && (source[j] == ' ' || source[j] == '\t') {
+		j++
+	}
+
+	var quote byte
+	if j < len(source) && (source[j] == '\'' || source[j] == '"') {
+		quote = source[j]
+		j++
+	}
+	start := j
+	for j < len(source) && isIdentifierChar(source[j]) && (j > start || !isDigit(source[j])) {
+		j++
+	}
+	identifier := source[start:j]
+	if identifier == "" {
+		return -1, false
+	}
+	if quote != 0 {
+		if j >= len(source) || source[j] != quote {
+			return -1, false
+		}
+		j++
+	}
+	if strings.HasPrefix(source[j:], "\r\n") {
+		j += 2
+	} else if j < len(source) && source[j] == '\n' {
+		j++
+	} else {
+		return -1, false
+	}
+
+	// Find a line that starts with the identifier, not followed by more of a name
+	for j < len(source) {
+		k := j
+		for k < len(source) && (source[k] == ' ' || source[k] == '\t') {
+			k++
+		}
+		if strings.HasPrefix(source[k:], identifier) {
+			end := k + len(identifier)
+			if end == len(source) || !isIdentifierChar(source[end]) {
+				return end, quote == '\''
+			}
+		}
+
+		next := strings.IndexByte(source[j:], '\n')
+		if next == -1 {
+			break
+		}
+		j += next + 1
+	}
+
+	return -1, false
+}
+
+// isIdentifierChar checks if a byte can be part of a PHP identifier
+func isIdentifierChar(ch byte) bool {
+	return ch == '_' || isDigit(ch) || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch >= 0x80
+}
+
+// isDigit checks if a byte is an ASCII digit
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// protectHeredocs replaces heredocs and nowdocs (or only nowdocs) with placeholders
+// so regex-based steps leave their contents alone, returning the originals in order
+func protectHeredocs(source string, nowdocsOnly bool) (string, []string) {
+	var result strings.Builder
+	var docs []string
+	i := 0
+
+	for i < len(source) {
+		if end, nowdoc := heredocEnd(source, i); end != -1 {
+			if nowdoc || !nowdocsOnly {
+				result.WriteString(heredocPlaceholder(len(docs)))
+				docs = append(docs, source[i:end])
+			} else {
+				result.WriteString(source[i:end])
+			}
+			i = end
+			continue
+		}
+
+		result.WriteByte(source[i])
+		i++
+	}
+
+	return result.String(), docs
+}
+
+// restoreHeredocs puts back the heredocs replaced by protectHeredocs. With
+// newline, each is followed by a line break, since before PHP 7.3 the closing
+// identifier must end its line.
+func restoreHeredocs(source string, docs []string, newline bool) string {
+	for n, doc := range docs {
+		if newline {
+			doc += "\n"
+		}
+		source = strings.Replace(source, heredocPlaceholder(n), doc, 1)
+	}
+	return source
+}
+
+// heredocPlaceholder returns the placeholder for the nth protected heredoc
+func heredocPlaceholder(n int) string {
+	return fmt.Sprintf("__WORDSMITH_HEREDOC_%d__", n)
+}
+
 // renameLocalVariables renames local variables in functions
 func renameLocalVariables(source string) string {
 	// Find function bodies and rename variables within them
 	// This is a simplified approach - find function blocks and process them
 
+	// Nowdocs are literal text, so their $names aren't variables. Variables in
+	// heredocs are renamed along with the rest of the function.
+	source, nowdocs := protectHeredocs(source, true)
+
 	// Superglobals and special variables to skip
 	skipVars := map[string]bool{
 		"$_GET": true, "$_POST": true, "$_REQUEST": true, "$_SERVER": true,
@@ -178,7 +324,7 @@ func renameLocalVariables(source string) string {
 	matches := funcRe.FindAllStringIndex(source, -1)
 
 	if len(matches) == 0 {
-		return source
+		return restoreHeredocs(source, nowdocs, false)
 	}
 
 	// Process each function
@@ -205,9 +351,9 @@ func renameLocalVariables(source string) string {
 		signature := source[funcStart:match[1]]
 		body := source[match[1]:bodyEnd]
 
-		// Find all variables in this function body
+		// Find all variables in this function's parameters and body
 		varRe := regexp.MustCompile(`\$[a-zA-Z_][a-zA-Z0-9_]*`)
-		vars := varRe.FindAllString(body, -1)
+		vars := varRe.FindAllString(signature+body, -1)
 
 		// Create unique list and mapping
 		varMap := make(map[string]string)
@@ -223,19 +369,16 @@ func renameLocalVariables(source string) string {
 			}
 		}
 
-		// Replace variables in body (longest first to avoid partial replacements)
-		newBody := body
-		// Sort by length descending
-		sortedVars := sortByLengthDesc(varMap)
-		for _, oldVar := range sortedVars {
-			newVar := varMap[oldVar]
-			// Use word boundary to avoid partial matches
-			re := regexp.MustCompile(regexp.QuoteMeta(oldVar) + `\b`)
-			newBody = re.ReplaceAllString(newBody, newVar)
+		// Replace each whole variable name in one pass, so a new name is never renamed again
+		rename := func(v string) string {
+			if newVar, ok := varMap[v]; ok {
+				return newVar
+			}
+			return v
 		}
 
-		result.WriteString(signature)
-		result.WriteString(newBody)
+		result.WriteString(varRe.ReplaceAllStringFunc(signature, rename))
+		result.WriteString(varRe.ReplaceAllStringFunc(body, rename))
 		result.WriteString("}")
 
 		lastEnd = bodyEnd + 1
@@ -244,7 +387,7 @@ func renameLocalVariables(source string) string {
 	// Write remaining content
 	result.WriteString(source[lastEnd:])
 
-	return result.String()
+	return restoreHeredocs(result.String(), nowdocs, false)
 }
 
 // findMatchingBrace finds the position of the closing brace
@@ -266,6 +409,14 @@ func findMatchingBrace(source string, start int) int {
 
 		ch := source[i]
 
+		// Skip heredocs and nowdocs, whose quotes and braces don't count
+		if !inSingle && !inDouble {
+			if end, _ := heredocEnd(source, i); end != -1 {
+				i = end - 1
+				continue
+			}
+		}
+
 		if ch == '\\' && (inSingle || inDouble) {
 			escaped = true
 			continue
@@ -301,27 +452,11 @@ func genVarName(index int) string {
 	return string(chars[index/26-1]) + string(chars[index%26])
 }
 
-// sortByLengthDesc returns map keys sorted by length descending
-func sortByLengthDesc(m map[string]string) []string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-
-	// Simple bubble sort by length (descending)
-	for i := 0; i < len(keys)-1; i++ {
-		for j := 0; j < len(keys)-i-1; j++ {
-			if len(keys[j]) < len(keys[j+1]) {
-				keys[j], keys[j+1] = keys[j+1], keys[j]
-			}
-		}
-	}
-
-	return keys
-}
-
 // minifyWhitespace reduces whitespace to minimum
 func minifyWhitespace(source string) string {
+	// Heredocs and nowdocs keep their line breaks and indentation
+	source, docs := protectHeredocs(source, false)
+
 	// Replace multiple whitespace with single space
 	re := regexp.MustCompile(`\s+`)
 	result := re.ReplaceAllString(source, " ")
@@ -348,5 +483,5 @@ func minifyWhitespace(source string) string {
 		result = re.ReplaceAllString(result, kw+" $1")
 	}
 
-	return strings.TrimSpace(result)
+	return restoreHeredocs(strings.TrimSpace(result), docs, true)
 }
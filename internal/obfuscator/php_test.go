@@ -0,0 +1,142 @@
+package obfuscator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestObfuscateHeredoc(t *testing.T) {
+	heredoc := `<<<HTML
+<div class="notice" data-id='{$id}'>
+    <a href="https://example.com/#top">It's {$name}'s page</a> // not a comment
+    <style>a { color: #fff; }</style>
+</div>
+HTML`
+	nowdoc := `<<<'SQL'
+    SELECT * FROM t WHERE a = '$a' # literal
+      AND b = "{b}"
+    SQL`
+
+	source := "<?php\n" +
+		"function render($id, $name) {\n" +
+		"    // comment\n" +
+		"    $html = " + heredoc + ";\n" +
+		"    $sql = " + nowdoc + ";\n" +
+		"    return $html . $sql;\n" +
+		"}\n"
+
+	result, err := Obfuscate(source)
+	if err != nil {
+		t.Fatalf("Obfuscate failed: %v", err)
+	}
+
+	if strings.Contains(result, "// comment") {
+		t.Errorf("comment was not stripped:\n%s", result)
+	}
+	if !strings.Contains(result, nowdoc+"\n") {
+		t.Errorf("nowdoc was changed:\n%s", result)
+	}
+
+	// The heredoc keeps its text, with its variables renamed like the parameters
+	renamed := strings.NewReplacer("$id", "$a", "$name", "$b").Replace(heredoc)
+	if !strings.Contains(result, renamed+"\n") {
+		t.Errorf("heredoc was changed, want:\n%s\ngot:\n%s", renamed, result)
+	}
+	if !strings.Contains(result, "function render($a,$b){") {
+		t.Errorf("parameters not renamed:\n%s", result)
+	}
+
+	// Lint the result when PHP is installed
+	if _, err := exec.LookPath("php"); err != nil {
+		return
+	}
+	tempDir, err := os.MkdirTemp("", "obfuscator_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "render.php")
+	if err := os.WriteFile(path, []byte(result), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if output, err := exec.Command("php", "-l", path).CombinedOutput(); err != nil {
+		t.Errorf("obfuscated PHP does not parse: %s\n%s", output, result)
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			"URL in double quotes",
+			"<?php\n$url = \"https://wp.org\"; // comment\n",
+			"<?php\n$url = \"https://wp.org\"; \n",
+		},
+		{
+			"multi-line double-quoted string",
+			"<?php\n$sql = \"SELECT 1 # not a comment\n  -- \\\"quoted // still text\\\"\n\"; # comment\necho $sql;\n",
+			"<?php\n$sql = \"SELECT 1 # not a comment\n  -- \\\"quoted // still text\\\"\n\"; \necho $sql;\n",
+		},
+		{
+			"escaped quotes in single quotes",
+			"<?php\n$a = 'it\\'s // here'; /* block\ncomment */$b = 1;\n",
+			"<?php\n$a = 'it\\'s // here'; $b = 1;\n",
+		},
+		{
+			"block comment between tokens",
+			"<?php\necho/* x */PHP_EOL;\n",
+			"<?php\necho PHP_EOL;\n",
+		},
+		{
+			"attribute and closing tag",
+			"<?php\n#[Attribute]\nclass A {} // done ?>\n<a href=\"http://example.com\"># top</a>\n",
+			"<?php\n#[Attribute]\nclass A {} ?>\n<a href=\"http://example.com\"># top</a>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripComments(tt.source); got != tt.want {
+				t.Errorf("stripComments() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeredocEnd(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+		nowdoc bool
+	}{
+		{"heredoc", "<<<EOT\na\nEOT;\n", "<<<EOT\na\nEOT", false},
+		{"quoted heredoc", "<<<\"EOT\"\na\nEOT;", "<<<\"EOT\"\na\nEOT", false},
+		{"nowdoc", "<<<'EOT'\n$a\nEOT;", "<<<'EOT'\n$a\nEOT", true},
+		{"indented closing", "<<<EOT\n  a\n  EOT, 1);", "<<<EOT\n  a\n  EOT", false},
+		{"longer name is not closing", "<<<EOT\nEOTX\nEOT\n", "<<<EOT\nEOTX\nEOT", false},
+		{"unterminated", "<<<EOT\na\n", "", false},
+		{"no newline after opening", "'<<<EOT'", "", false},
+		{"not a heredoc", "$a << 1", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, nowdoc := heredocEnd(tt.source, 0)
+			got := ""
+			if end != -1 {
+				got = tt.source[:end]
+			}
+			if got != tt.want || nowdoc != tt.nowdoc {
+				t.Errorf("heredocEnd(%q) = %q, %v; want %q, %v", tt.source, got, nowdoc, tt.want, tt.nowdoc)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event levels emitted in JSON events mode, in addition to the Print* levels
+const (
+	LevelPhase = "phase"
+)
+
+// Event is one newline-delimited JSON record written in JSON events mode
+type Event struct {
+	Phase   string `json:"phase"`
+	Message string `json:"message"`
+	Level   string `json:"level"`
+	Ts      string `json:"ts"`
+}
+
+var (
+	eventsMu  sync.Mutex
+	eventsOut io.Writer // Non-nil when JSON events mode is enabled
+	phase     string
+	ansiRe    = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
+// EnableJSONEvents switches the Print* functions to emit JSON events on stdout.
+// Anything else written to stdout is redirected to stderr so the stream stays
+// machine-readable.
+func EnableJSONEvents() {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	if eventsOut != nil {
+		return
+	}
+	eventsOut = os.Stdout
+	os.Stdout = os.Stderr
+}
+
+// JSONEvents reports whether JSON events mode is enabled
+func JSONEvents() bool {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	return eventsOut != nil
+}
+
+// EventWriter returns the writer events are written to, or nil when JSON events
+// mode is disabled. Child wordsmith processes run with --json-events can write here.
+func EventWriter() io.Writer {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	return eventsOut
+}
+
+// SetPhase sets the phase attached to subsequent events and emits a phase event
+func SetPhase(name string) {
+	eventsMu.Lock()
+	changed := phase != name
+	phase = name
+	eventsMu.Unlock()
+
+	if changed {
+		emitEvent(LevelPhase, name)
+	}
+}
+
+// EmitEvent writes an event in JSON events mode; it does nothing otherwise.
+// Use it for progress that has no styled equivalent, like downloads.
+func EmitEvent(level, format string, args ...interface{}) {
+	emitEvent(level, fmt.Sprintf(format, args...))
+}
+
+// emitEvent writes an event if JSON events mode is enabled and reports whether it did
+func emitEvent(level, message string) bool {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	if eventsOut == nil {
+		return false
+	}
+
+	data, err := json.Marshal(Event{
+		Phase:   phase,
+		Message: strings.TrimSpace(ansiRe.ReplaceAllString(message, "")),
+		Level:   level,
+		Ts:      time.Now().UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return false
+	}
+	eventsOut.Write(append(data, '\n'))
+	return true
+}
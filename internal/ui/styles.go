@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -75,26 +76,41 @@ func Header(text string) string {
 
 // PrintSuccess prints a success message
 func PrintSuccess(format string, args ...interface{}) {
+	if emitEvent("success", fmt.Sprintf(format, args...)) {
+		return
+	}
 	fmt.Println(SuccessStyle.Render("✓ " + fmt.Sprintf(format, args...)))
 }
 
 // PrintInfo prints an info message
 func PrintInfo(format string, args ...interface{}) {
+	if emitEvent("info", fmt.Sprintf(format, args...)) {
+		return
+	}
 	fmt.Println(InfoStyle.Render("• " + fmt.Sprintf(format, args...)))
 }
 
 // PrintError prints an error message
 func PrintError(format string, args ...interface{}) {
+	if emitEvent("error", fmt.Sprintf(format, args...)) {
+		return
+	}
 	fmt.Println(ErrorStyle.Render("✗ " + fmt.Sprintf(format, args...)))
 }
 
 // PrintWarning prints a warning message
 func PrintWarning(format string, args ...interface{}) {
+	if emitEvent("warning", fmt.Sprintf(format, args...)) {
+		return
+	}
 	fmt.Println(WarningStyle.Render("⚠ " + fmt.Sprintf(format, args...)))
 }
 
 // PrintKeyValue prints a key-value pair
 func PrintKeyValue(key, value string) {
+	if emitEvent("info", key+": "+strings.TrimSpace(value)) {
+		return
+	}
 	fmt.Printf("  %s %s\n", KeyStyle.Render(key+":"), ValueStyle.Render(value))
 }
 
@@ -115,6 +131,9 @@ func PrintVersion(version string) {
 
 // PrintHeader prints the standard header
 func PrintHeader(version string) {
+	if JSONEvents() {
+		return
+	}
 	fmt.Println()
 	fmt.Println(Divider())
 	fmt.Println(Banner())
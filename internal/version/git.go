@@ -12,14 +12,34 @@ import (
 type Version struct {
 	Major       int
 	Minor       int
-	Maintenance string
+	Patch       int
+	Prerelease  string // Dot- or dash-separated identifiers after the "-", e.g. beta.1
+	Build       string // Build metadata after the "+"
+	Rest        string // Everything after major.minor when it isn't semver, e.g. 3.4 in 1.2.3.4
 	GitDescribe string
 	IsDirty     bool
+	Tagged      bool // Whether the version came from a v*.*.* tag rather than the 0.1.0 default
 }
 
 // String returns the version as a string
 func (v *Version) String() string {
-	return fmt.Sprintf("%d.%d.%s", v.Major, v.Minor, v.Maintenance)
+	return fmt.Sprintf("%d.%d.%s", v.Major, v.Minor, v.Maintenance())
+}
+
+// Maintenance returns the patch number with any pre-release and build suffix,
+// the part of the version after major.minor.
+func (v *Version) Maintenance() string {
+	if v.Rest != "" {
+		return v.Rest
+	}
+	maintenance := fmt.Sprintf("%d", v.Patch)
+	if v.Prerelease != "" {
+		maintenance += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		maintenance += "+" + v.Build
+	}
+	return maintenance
 }
 
 // GetFromGit gets the version from git tags
@@ -35,27 +55,29 @@ func GetFromGit(dir string) (*Version, error) {
 	}
 
 	// Parse git describe output
-	// Format: v0.1.0 or v0.1.0-5-g1a2b3c4
-	re := regexp.MustCompile(`^v(\d+)\.(\d+)\.(\d+)(?:-(\d+)-g([0-9a-f]+))?$`)
+	// Format: v0.1.0, v0.1.0-beta, v0.1.0-5-g1a2b3c4, or v0.1.0-beta-5-g1a2b3c4
+	re := regexp.MustCompile(`^v(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+?))??(?:-(\d+)-g([0-9a-f]+))?$`)
 	matches := re.FindStringSubmatch(gitDescribe)
 
-	var major, minor int
-	var maintenance string
+	var major, minor, patch int
+	var prerelease string
+	tagged := err == nil && matches != nil
 
 	if matches != nil {
 		fmt.Sscanf(matches[1], "%d", &major)
 		fmt.Sscanf(matches[2], "%d", &minor)
-		maintenance = matches[3]
+		fmt.Sscanf(matches[3], "%d", &patch)
+		prerelease = matches[4]
 
 		// If there are commits after the tag, append commit count
-		if matches[4] != "" {
-			maintenance = fmt.Sprintf("%s-%s", maintenance, matches[4])
+		if matches[5] != "" {
+			prerelease = joinPrerelease(prerelease, matches[5])
 		}
 	} else {
 		// Fallback
 		major = 0
 		minor = 1
-		maintenance = "0"
+		patch = 0
 	}
 
 	// Check for uncommitted changes
@@ -66,18 +88,28 @@ func GetFromGit(dir string) (*Version, error) {
 	if err == nil && len(strings.TrimSpace(string(output))) > 0 {
 		isDirty = true
 		timestamp := time.Now().Format("01021504")
-		maintenance = fmt.Sprintf("%s-%s", maintenance, timestamp)
+		prerelease = joinPrerelease(prerelease, timestamp)
 	}
 
 	return &Version{
 		Major:       major,
 		Minor:       minor,
-		Maintenance: maintenance,
+		Patch:       patch,
+		Prerelease:  prerelease,
 		GitDescribe: gitDescribe,
 		IsDirty:     isDirty,
+		Tagged:      tagged,
 	}, nil
 }
 
+// joinPrerelease appends part to a pre-release, separated by a dash
+func joinPrerelease(prerelease, part string) string {
+	if prerelease == "" {
+		return part
+	}
+	return prerelease + "-" + part
+}
+
 // IsGitRepo checks if the directory is a git repository
 func IsGitRepo(dir string) bool {
 	cmd := exec.Command("git", "rev-parse", "--git-dir")